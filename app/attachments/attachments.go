@@ -0,0 +1,68 @@
+// Package attachments checks the files attached to transactions and makes
+// the thumbnails of the images.
+package attachments
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+)
+
+// SniffLen is how many bytes Detect needs from the start of a file
+const SniffLen = 512
+
+// MaxFilenameLen is the max length of a filename in runes
+const MaxFilenameLen = 255
+
+// Content types that can be attached, with the extension of their files
+var ContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// Detect returns the content type of a file from its first SniffLen bytes,
+// ignoring the one the client declared. Files claiming to be images get
+// apperrors.InvalidImageType when they are not a JPEG or PNG.
+func Detect(head []byte, declared, filename string) (string, error) {
+	contentType := http.DetectContentType(head)
+	if _, ok := ContentTypes[contentType]; ok {
+		return contentType, nil
+	}
+
+	if IsImage(declared) || IsImage(mime.TypeByExtension(filepath.Ext(filename))) {
+		return "", apperrors.NewUnsupportedMediaType(apperrors.InvalidImageType)
+	}
+
+	return "", apperrors.NewUnsupportedMediaType(apperrors.InvalidAttachmentType)
+}
+
+// IsImage reports whether the content type is an image
+func IsImage(contentType string) bool {
+	return strings.HasPrefix(contentType, "image/")
+}
+
+// Filename cleans the name of an uploaded file, using fallback when nothing
+// is left
+func Filename(name, fallback string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "" || name == "." || name == "/" {
+		return fallback
+	}
+	if utf8.RuneCountInString(name) > MaxFilenameLen {
+		name = string([]rune(name)[:MaxFilenameLen])
+	}
+
+	return name
+}
@@ -0,0 +1,114 @@
+package attachments
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngImage(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	img := pngImage(t, 2, 2, color.Black)
+
+	contentType, err := Detect(img, "application/octet-stream", "receipt")
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	contentType, err = Detect([]byte("%PDF-1.7\n"), "image/png", "receipt.png")
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+
+	_, err = Detect([]byte("<svg></svg>"), "image/svg+xml", "logo.svg")
+	assert.Equal(t, http.StatusUnsupportedMediaType, apperrors.Status(err))
+	assert.EqualError(t, err, apperrors.InvalidImageType)
+
+	_, err = Detect([]byte("GIF89a"), "application/octet-stream", "receipt.gif")
+	assert.EqualError(t, err, apperrors.InvalidImageType)
+
+	_, err = Detect([]byte("hello"), "text/plain", "notes.txt")
+	assert.Equal(t, http.StatusUnsupportedMediaType, apperrors.Status(err))
+	assert.EqualError(t, err, apperrors.InvalidAttachmentType)
+}
+
+func TestFilename(t *testing.T) {
+	assert.Equal(t, "receipt.pdf", Filename("  receipt.pdf ", "file"))
+	assert.Equal(t, "evil.pdf", Filename("../../evil.pdf", "file"))
+	assert.Equal(t, "evil.pdf", Filename(`C:\Users\me\evil.pdf`, "file"))
+	assert.Equal(t, "ab.pdf", Filename("a\r\nb.pdf", "file"))
+	assert.Equal(t, "file", Filename("", "file"))
+	assert.Equal(t, "file", Filename("/", "file"))
+	assert.Equal(t, MaxFilenameLen, len([]rune(Filename(strings.Repeat("é", 300), "file"))))
+}
+
+func TestThumbnail(t *testing.T) {
+	b, err := Thumbnail(bytes.NewReader(pngImage(t, 400, 100, color.NRGBA{R: 255, A: 255})), 200)
+	require.NoError(t, err)
+
+	img, err := jpeg.Decode(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 50), img.Bounds())
+
+	r, g, _, _ := img.At(100, 25).RGBA()
+	assert.Greater(t, r>>8, uint32(240))
+	assert.Less(t, g>>8, uint32(20))
+
+	t.Run("Small Images Keep Their Size", func(t *testing.T) {
+		b, err := Thumbnail(bytes.NewReader(pngImage(t, 10, 30, color.Black)), 200)
+		require.NoError(t, err)
+
+		img, err := jpeg.Decode(bytes.NewReader(b))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 10, 30), img.Bounds())
+	})
+
+	t.Run("Transparent Is White", func(t *testing.T) {
+		b, err := Thumbnail(bytes.NewReader(pngImage(t, 4, 4, color.Transparent)), 200)
+		require.NoError(t, err)
+
+		img, err := jpeg.Decode(bytes.NewReader(b))
+		require.NoError(t, err)
+		r, g, bl, _ := img.At(1, 1).RGBA()
+		assert.Greater(t, min(r, g, bl)>>8, uint32(245))
+	})
+
+	t.Run("Invalid Image", func(t *testing.T) {
+		_, err := Thumbnail(strings.NewReader("\x89PNG\r\n\x1a\nnot really"), 200)
+		assert.Error(t, err)
+	})
+}
+
+func TestThumbnailTooManyPixels(t *testing.T) {
+	// a PNG header claiming a huge image is enough
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	b := buf.Bytes()
+	// width and height of the IHDR chunk, 10000 x 10000, and its checksum
+	copy(b[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+	binary.BigEndian.PutUint32(b[29:33], crc32.ChecksumIEEE(b[12:29]))
+
+	_, err := Thumbnail(bytes.NewReader(b), 200)
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
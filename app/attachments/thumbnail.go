@@ -0,0 +1,97 @@
+package attachments
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// ThumbnailType is the content type of the thumbnails
+const ThumbnailType = "image/jpeg"
+
+// MaxPixels is the largest image a thumbnail is made of, so small files
+// can't decode into huge images
+const MaxPixels = 25_000_000
+
+// ErrTooManyPixels is returned for images over MaxPixels
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// Thumbnail decodes a JPEG or PNG image and returns a JPEG of it fitting in
+// size x size pixels. Smaller images keep their size. Transparent pixels are
+// made white.
+func Thumbnail(r io.ReadSeeker, size int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scale(src, size), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// scale shrinks src to fit in size x size, averaging the pixels each
+// thumbnail pixel covers
+func scale(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/b.Dx())
+		} else {
+			w, h = max(1, w*size/b.Dy()), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+
+			var r, g, bl, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					// blend on white
+					a := uint64(c.A)
+					r += (uint64(c.R)*a + 0xffff*(0xffff-a)) / 0xffff
+					g += (uint64(c.G)*a + 0xffff*(0xffff-a)) / 0xffff
+					bl += (uint64(c.B)*a + 0xffff*(0xffff-a)) / 0xffff
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
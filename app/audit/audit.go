@@ -0,0 +1,145 @@
+// Package audit records who changed what in the append-only audit_logs
+// table.
+//
+// The changes of accounts, categories, transactions, their lines and tags and
+// user roles are logged by database triggers, so no code path can skip them.
+// The triggers read the actor from the transaction settings, which SetActor
+// copies from the request context. Other events, like logins, are logged
+// with Record.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// Entity types
+const (
+	EntityUser        = "user"
+	EntityAccount     = "account"
+	EntityCategory    = "category"
+	EntityTransaction = "transaction"
+	EntitySplit       = "transaction_split"
+	EntityTag         = "tag"
+	EntityRule        = "rule"
+	EntityPayee       = "payee"
+)
+
+// Actions logged with Record. The triggers log created, updated, deleted and
+// role_changed.
+const (
+	ActionLogin                  = "login"
+	ActionLoginFailed            = "login_failed"
+	ActionLogout                 = "logout"
+	ActionPasswordResetRequested = "password_reset_requested"
+)
+
+// Actor is who makes the changes of a request
+type Actor struct {
+	UserID    uuid.NullUUID
+	IPAddress string
+	RequestID string
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the actor
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// WithUserID returns a copy of ctx whose actor is the given user
+func WithUserID(ctx context.Context, userId uuid.UUID) context.Context {
+	a := ActorFrom(ctx)
+	a.UserID = uuid.NullUUID{UUID: userId, Valid: true}
+	return WithActor(ctx, a)
+}
+
+// ActorFrom returns the actor of ctx, which is empty outside requests, e.g.
+// in jobs
+func ActorFrom(ctx context.Context) Actor {
+	a, _ := ctx.Value(actorKey{}).(Actor)
+	return a
+}
+
+// Entry is an event to log. Before and After are encoded as JSON.
+type Entry struct {
+	// ActorID defaults to the user of the context actor
+	ActorID     uuid.NullUUID
+	WorkspaceID uuid.NullUUID
+	EntityType  string
+	EntityID    uuid.NullUUID
+	Action      string
+	Before      any
+	After       any
+}
+
+// Record logs the entry with the actor of ctx. Run it with the queries of the
+// transaction of the change, if any, so both are kept or lost together.
+func Record(ctx context.Context, q *model.Queries, e Entry) error {
+	actor := ActorFrom(ctx)
+	if !e.ActorID.Valid {
+		e.ActorID = actor.UserID
+	}
+
+	before, err := encode(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := encode(e.After)
+	if err != nil {
+		return err
+	}
+
+	return q.CreateAuditLog(ctx, model.CreateAuditLogParams{
+		ActorID:     e.ActorID,
+		WorkspaceID: e.WorkspaceID,
+		EntityType:  e.EntityType,
+		EntityID:    e.EntityID,
+		Action:      e.Action,
+		Before:      before,
+		After:       after,
+		IpAddress:   text(actor.IPAddress),
+		RequestID:   text(actor.RequestID),
+	})
+}
+
+// SetActor saves the actor of ctx in the settings of the transaction for the
+// audit triggers. Call it before changing the audited tables.
+func SetActor(ctx context.Context, tx pgx.Tx) error {
+	actor := ActorFrom(ctx)
+
+	actorId := ""
+	if actor.UserID.Valid {
+		actorId = actor.UserID.UUID.String()
+	}
+
+	// is_local=true keeps them to the transaction
+	_, err := tx.Exec(ctx, `SELECT set_config('audit.actor_id', $1, true),
+		set_config('audit.ip_address', $2, true),
+		set_config('audit.request_id', $3, true)`,
+		actorId, actor.IPAddress, actor.RequestID,
+	)
+	return err
+}
+
+// ID returns id as a valid uuid.NullUUID
+func ID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: true}
+}
+
+func encode(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func text(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// Export formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// exportBatchSize is how many entries are read at once when exporting
+const exportBatchSize = 1000
+
+// Filter selects entries. Zero fields match everything and To is exclusive.
+type Filter struct {
+	WorkspaceID uuid.NullUUID
+	EntityType  string
+	EntityID    uuid.NullUUID
+	Action      string
+	ActorID     uuid.NullUUID
+	From        time.Time
+	To          time.Time
+}
+
+// List returns up to limit entries matching the filter, newest first, with
+// an id lower than before unless it is 0
+func List(ctx context.Context, q *model.Queries, f Filter, before int64, limit int) ([]*model.AuditLog, error) {
+	return q.GetAuditLogs(ctx, model.GetAuditLogsParams{
+		WorkspaceID: f.WorkspaceID,
+		EntityType:  text(f.EntityType),
+		EntityID:    f.EntityID,
+		Action:      text(f.Action),
+		ActorID:     f.ActorID,
+		From:        timestamp(f.From),
+		To:          timestamp(f.To),
+		Before:      pgtype.Int8{Int64: before, Valid: before > 0},
+		Lim:         int32(limit),
+	})
+}
+
+// Export writes the entries matching the filter to w, newest first, as JSON
+// lines or CSV. It returns how many were written.
+func Export(ctx context.Context, q *model.Queries, f Filter, w io.Writer, format string) (int, error) {
+	var write func(e *model.AuditLog) error
+	var flush func() error
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		write = func(e *model.AuditLog) error { return enc.Encode(e) }
+		flush = func() error { return nil }
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		err := cw.Write([]string{"id", "created_at", "actor_id", "workspace_id", "entity_type", "entity_id", "action", "before", "after", "ip_address", "request_id"})
+		if err != nil {
+			return 0, err
+		}
+		write = func(e *model.AuditLog) error { return cw.Write(csvRecord(e)) }
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return 0, fmt.Errorf("invalid export format %q", format)
+	}
+
+	n := 0
+	var before int64
+	for {
+		entries, err := List(ctx, q, f, before, exportBatchSize)
+		if err != nil {
+			return n, err
+		}
+
+		for _, e := range entries {
+			if err := write(e); err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		if len(entries) < exportBatchSize {
+			return n, flush()
+		}
+		before = entries[len(entries)-1].ID
+	}
+}
+
+func csvRecord(e *model.AuditLog) []string {
+	nullUUID := func(id uuid.NullUUID) string {
+		if !id.Valid {
+			return ""
+		}
+		return id.UUID.String()
+	}
+
+	return []string{
+		strconv.FormatInt(e.ID, 10),
+		e.CreatedAt.Time.Format(time.RFC3339),
+		nullUUID(e.ActorID),
+		nullUUID(e.WorkspaceID),
+		e.EntityType,
+		nullUUID(e.EntityID),
+		e.Action,
+		string(e.Before),
+		string(e.After),
+		e.IpAddress.String,
+		e.RequestID.String,
+	}
+}
+
+func timestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t.UTC(), Valid: !t.IsZero()}
+}
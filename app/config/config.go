@@ -13,7 +13,7 @@ type Config struct {
 	RedisUrl       string `env:"REDIS_URL,required"`
 	Port           string `env:"PORT,default=8080"`
 	Domain         string `env:"DOMAIN,required"`
-	CorsOrigin     string `env:"CORS_ORIGIN,default=*"`
+	PublicURL      string `env:"PUBLIC_URL,default=http://localhost:8080"`
 	HandlerTimeOut int64  `env:"HANDLER_TIMEOUT,default=5"`
 	MaxBodyBytes   int64  `env:"MAX_BODY_BYTES,default=4194304"`
 	RootPath       string `env:"ROOT_PATH,default=src/github.com/opchaves/gin-web-app"`
@@ -21,13 +21,96 @@ type Config struct {
 	AssetsDir      string `env:"ASSETS_DIR,default=assets"`
 	SessionSecret  string `env:"SESSION_SECRET,default=sup3rs3cr37"`
 	RateLimit      int64  `env:"RATE_LIMIT,default=1000"`
+	// HealthCheckTimeout and ShutdownDrain are in seconds
+	HealthCheckTimeout int64  `env:"HEALTH_CHECK_TIMEOUT,default=2"`
+	HealthCheckSMTP    bool   `env:"HEALTH_CHECK_SMTP,default=false"`
+	ShutdownDrain      int64  `env:"SHUTDOWN_DRAIN,default=5"`
+	LogLevel           string `env:"LOG_LEVEL,default=info"`
+	LogFormat          string `env:"LOG_FORMAT,default=json"`
 
+	// DefaultLanguage is used when neither the profile nor the
+	// Accept-Language header name a language with a catalogue. Catalogues in
+	// LocalesDir extend and override the embedded ones.
+	DefaultLanguage string `env:"DEFAULT_LANGUAGE,default=en"`
+	LocalesDir      string `env:"LOCALES_DIR"`
+
+	// AutoMigrate applies the pending migrations on start up, waiting up to
+	// MigrateLockTimeout seconds for another replica doing the same
+	AutoMigrate        bool  `env:"AUTO_MIGRATE,default=false"`
+	MigrateLockTimeout int64 `env:"MIGRATE_LOCK_TIMEOUT,default=60"`
+
+	// JobInline runs the job worker inside the server, otherwise it runs
+	// with cmd/worker. Queues are listed by priority. JobLease and
+	// JobDrainTimeout are in seconds.
+	JobInline       bool     `env:"JOB_INLINE,default=true"`
+	JobQueues       []string `env:"JOB_QUEUES,default=emails,webhooks,imports,reports,default"`
+	JobConcurrency  int      `env:"JOB_CONCURRENCY,default=4"`
+	JobLease        int64    `env:"JOB_LEASE,default=300"`
+	JobDrainTimeout int64    `env:"JOB_DRAIN_TIMEOUT,default=10"`
+
+	// The outbox relay runs with the job worker. OutboxPollInterval is in
+	// milliseconds and OutboxRetention, how long published events are kept,
+	// in hours.
+	OutboxBatchSize    int   `env:"OUTBOX_BATCH_SIZE,default=100"`
+	OutboxPollInterval int64 `env:"OUTBOX_POLL_INTERVAL,default=1000"`
+	OutboxRetention    int64 `env:"OUTBOX_RETENTION,default=168"`
+
+	// WebhookTimeout is how many seconds a webhook receiver has to answer.
+	// Receivers on loopback and private addresses are refused unless
+	// WebhookAllowPrivate is set.
+	WebhookTimeout      int64 `env:"WEBHOOK_TIMEOUT,default=10"`
+	WebhookAllowPrivate bool  `env:"WEBHOOK_ALLOW_PRIVATE,default=false"`
+
+	// Uploads are stored under StorageDir or, with the s3 driver, in an S3
+	// compatible bucket. Download links expire after StorageURLExpiry
+	// seconds and local ones are signed with StorageSecret, which defaults to
+	// SessionSecret. UploadMaxBytes is the max size of a file and
+	// UploadTimeout, in seconds, the timeout of the upload routes.
+	StorageDriver    string `env:"STORAGE_DRIVER,default=local"`
+	StorageDir       string `env:"STORAGE_DIR,default=storage"`
+	StorageSecret    string `env:"STORAGE_SECRET"`
+	StorageURLExpiry int64  `env:"STORAGE_URL_EXPIRY,default=900"`
+	S3Endpoint       string `env:"S3_ENDPOINT,default=http://localhost:9000"`
+	S3Region         string `env:"S3_REGION,default=us-east-1"`
+	S3Bucket         string `env:"S3_BUCKET"`
+	S3AccessKey      string `env:"S3_ACCESS_KEY"`
+	S3SecretKey      string `env:"S3_SECRET_KEY"`
+	S3PathStyle      bool   `env:"S3_PATH_STYLE,default=true"`
+	UploadMaxBytes   int64  `env:"UPLOAD_MAX_BYTES,default=10485760"`
+	UploadTimeout    int64  `env:"UPLOAD_TIMEOUT,default=60"`
+	ThumbnailSize    int    `env:"THUMBNAIL_SIZE,default=320"`
+
+	// TrustedProxies are the addresses or CIDRs of the proxies whose
+	// X-Forwarded-For header gives the client address. None are trusted by
+	// default.
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+
+	CorsOrigin      []string `env:"CORS_ORIGIN,default=http://localhost:3000"`
+	CorsMethods     []string `env:"CORS_METHODS,default=GET,POST,PUT,PATCH,DELETE"`
+	CorsHeaders     []string `env:"CORS_HEADERS,default=Content-Type,X-CSRF-Token,HX-Request,HX-Current-URL,HX-Target,HX-Trigger"`
+	CorsCredentials bool     `env:"CORS_CREDENTIALS,default=true"`
+	CorsMaxAge      int64    `env:"CORS_MAX_AGE,default=43200"`
+
+	// Metrics are served on MetricsPort when set, otherwise on /metrics behind
+	// basic auth when MetricsPassword is set
+	MetricsPort     string `env:"METRICS_PORT"`
+	MetricsUsername string `env:"METRICS_USERNAME,default=metrics"`
+	MetricsPassword string `env:"METRICS_PASSWORD"`
+
+	TracingExporter    string  `env:"TRACING_EXPORTER,default=none"`
+	TracingServiceName string  `env:"TRACING_SERVICE_NAME,default=gin-web-app"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO,default=1"`
+
+	// MailMailer is smtp, log or memory. The log mailer writes the emails to
+	// MailDir when set.
 	MailMailer     string `env:"MAIL_MAILER,default=smtp"`
 	MailHost       string `env:"MAIL_HOST,default=localhost"`
 	MailPort       string `env:"MAIL_PORT,default=1025"`
 	MailUsername   string `env:"MAIL_USERNAME"`
 	MailPassword   string `env:"MAIL_PASSWORD"`
 	MailEncryption string `env:"MAIL_ENCRYPTION"`
+	MailFrom       string `env:"MAIL_FROM,default=Kommonei <no-reply@localhost>"`
+	MailDir        string `env:"MAIL_DIR"`
 }
 
 func LoadConfig(ctx context.Context) (config Config, err error) {
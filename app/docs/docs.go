@@ -0,0 +1,37 @@
+// Package docs holds the OpenAPI spec of the API
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+var (
+	jsonOnce sync.Once
+	jsonSpec []byte
+	jsonErr  error
+)
+
+// YAML returns the spec as written
+func YAML() []byte {
+	return spec
+}
+
+// JSON returns the spec converted to JSON
+func JSON() ([]byte, error) {
+	jsonOnce.Do(func() {
+		var doc map[string]interface{}
+		if jsonErr = yaml.Unmarshal(spec, &doc); jsonErr != nil {
+			return
+		}
+		jsonSpec, jsonErr = json.Marshal(doc)
+	})
+
+	return jsonSpec, jsonErr
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/opchaves/gin-web-app/app/outbox"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+)
+
+// SetEvents subscribes the handlers of the outbox events. They run at least
+// once, so they should only enqueue jobs or do other work that is safe to
+// repeat.
+func SetEvents(c *Config) {
+	c.Outbox.Subscribe(service.EventUserRegistered, func(ctx context.Context, e *outbox.Event) error {
+		var data service.UserRegisteredEvent
+		if err := e.Decode(&data); err != nil {
+			return err
+		}
+
+		// in the language the user registered in
+		ctx = i18n.WithLocalizer(ctx, c.I18n.Localizer(c.I18n.Match(data.Language)))
+
+		return c.MailService.SendWelcomeEmail(ctx, data.Email, data.FirstName)
+	})
+
+	for _, event := range webhooks.Events {
+		c.Outbox.Subscribe(event, c.WebhookService.Dispatch)
+	}
+}
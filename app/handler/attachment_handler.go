@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/storage"
+)
+
+func (h *Handler) GetAttachments(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	items, err := h.AttachmentService.List(c, workspace.ID, c.Param("transactionId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+func (h *Handler) GetAttachment(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	item, err := h.AttachmentService.Get(c, workspace.ID, c.Param("transactionId"), c.Param("attachmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// DownloadAttachment redirects to a fresh signed URL of the file
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	item, err := h.AttachmentService.Get(c, workspace.ID, c.Param("transactionId"), c.Param("attachmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, item.URL)
+}
+
+// UploadAttachment takes the file in the "file" field of a multipart form
+func (h *Handler) UploadAttachment(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Error(apperrors.NewPayloadTooLarge(maxBytesErr.Limit, c.Request.ContentLength))
+			return
+		}
+
+		c.Error(apperrors.NewValidation([]apperrors.FieldError{{Field: "File", Message: i18n.From(c).T("validation.required")}}))
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	item, err := h.AttachmentService.Upload(c, workspace.ID, userId, c.Param("transactionId"), file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	err := h.AttachmentService.Delete(c, workspace.ID, c.Param("transactionId"), c.Param("attachmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, true)
+}
+
+// GetFile serves a file of the local storage to a signed URL
+func (h *Handler) GetFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	if err := h.Files.Verify(key, c.Request.URL.Query()); err != nil {
+		c.Error(apperrors.NewForbidden(apperrors.InvalidLink))
+		return
+	}
+
+	f, err := h.Files.Get(c, key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		c.Error(apperrors.NewNotFound("file", key))
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer f.Close()
+
+	if cd := h.Files.ContentDisposition(c.Request.URL.Query()); cd != "" {
+		c.Header("Content-Disposition", cd)
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(c.Writer, c.Request, key, time.Time{}, f.(io.ReadSeeker))
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+func (h *Handler) GetAuditLogs(c *gin.Context) {
+	var req service.AuditLogsInput
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	res, err := h.AuditService.List(c, workspace.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/docs"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+)
+
+// errorCode is a row of the error codes on the docs page
+type errorCode struct {
+	Code        apperrors.Type
+	Status      int
+	Description string
+}
+
+func (h *Handler) GetDocs(c *gin.Context) {
+	codes := make([]errorCode, 0, len(apperrors.Types))
+	for _, t := range apperrors.Types {
+		codes = append(codes, errorCode{Code: t, Status: t.Status(), Description: t.Description()})
+	}
+
+	c.HTML(http.StatusOK, "docs.html", gin.H{
+		"title": "API Docs",
+		"codes": codes,
+	})
+}
+
+func (h *Handler) GetOpenAPIJSON(c *gin.Context) {
+	spec, err := docs.JSON()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+func (h *Handler) GetOpenAPIYAML(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", docs.YAML())
+}
@@ -5,9 +5,13 @@ import (
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/handler/middleware"
+	"github.com/opchaves/gin-web-app/app/model"
 	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/app/storage"
 )
 
 // Handler struct holds required services for handler to function
@@ -19,13 +23,36 @@ type Handler struct {
 	UserService  service.UserService
 	RedisService service.RedisService
 	MailService  service.MailService
+
+	WorkspaceService   service.WorkspaceService
+	WebhookService     service.WebhookService
+	AuditService       service.AuditService
+	TransactionService service.TransactionService
+	AttachmentService  service.AttachmentService
+	TagService         service.TagService
+	ReportService      service.ReportService
+	RuleService        service.RuleService
+	PayeeService       service.PayeeService
+	JobRunService      service.JobRunService
+
+	// Files serves the files of the local storage, when it is the one used
+	Files *storage.Local
+
+	HealthService service.HealthService
 }
 
-// setUserSession saves the users ID in the session
-func (h *Handler) setUserSession(c *gin.Context, id string) {
+// setUserSession saves the users ID and the language of their profile in the
+// session and rotates its CSRF token
+func (h *Handler) setUserSession(c *gin.Context, id uuid.UUID) {
 	session := sessions.Default(c)
-	session.Set("userId", id)
+	session.Set("userId", id.String())
+	if lang := h.UserService.Language(c, id); lang != "" {
+		session.Set(model.LanguageKey, lang)
+	} else {
+		session.Delete(model.LanguageKey)
+	}
+	middleware.ResetCSRFToken(session)
 	if err := session.Save(); err != nil {
-		h.Logger.Error("error setting the session", slog.AnyValue(err.Error()))
+		h.Logger.ErrorContext(c, "error setting the session", slog.String("error", err.Error()))
 	}
 }
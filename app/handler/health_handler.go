@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHealthz tells whether the process is alive
+func (h *Handler) GetHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GetReadyz tells whether the server can take traffic, with the result of
+// each dependency check
+func (h *Handler) GetReadyz(c *gin.Context) {
+	report, ok := h.HealthService.Ready(c)
+
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
@@ -1,24 +1,34 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/opchaves/gin-web-app/app/utils"
 )
 
 // TODO maybe move routes to app/handlers and only leave router.go and server.go under cmd/server
 
 func (h *Handler) GetHome(c *gin.Context) {
-	fmt.Println(h.Db.Config().ConnString())
+	data := gin.H{
+		"title": i18n.From(c).T("pages.title"),
+	}
 
-	c.HTML(http.StatusOK, "index.html", gin.H{
-		"title": "Gin Web App",
+	utils.Render(c, http.StatusOK, utils.Negotiated{
+		JSON: gin.H{"data": data},
+		Page: "index.html",
+		Data: data,
 	})
 }
 
 func (h *Handler) PostAddCar(c *gin.Context) {
 	carName := c.PostForm("car")
 
-	c.HTML(http.StatusOK, "add_car.html", gin.H{"name": carName})
+	utils.Render(c, http.StatusOK, utils.Negotiated{
+		JSON:     gin.H{"data": gin.H{"name": carName}},
+		Fragment: "add_car.html",
+		Redirect: "/",
+		Data:     gin.H{"name": carName},
+	})
 }
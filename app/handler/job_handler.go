@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/app/utils"
+)
+
+func (h *Handler) GetJob(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	run, err := h.JobRunService.Get(c, workspace.ID, c.Param("jobId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := jobRunResponse(c, run)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// startJob starts a run of the given kind and responds with it, the client
+// follows it on the Location header
+func (h *Handler) startJob(c *gin.Context, kind string, input any) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	run, err := h.JobRunService.Start(c, workspace.ID, userId, kind, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := jobRunResponse(c, run)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", "/workspaces/"+workspace.ID.String()+"/jobs/"+run.ID.String())
+	c.JSON(http.StatusAccepted, gin.H{"data": res})
+}
+
+// jobRunResponse hides the input of the run and translates its error to the
+// language of the request
+func jobRunResponse(c *gin.Context, run *model.JobRun) (*service.JobRunResponse, error) {
+	res := &service.JobRunResponse{
+		ID:          run.ID,
+		Kind:        run.Kind,
+		Status:      run.Status,
+		WorkspaceID: run.WorkspaceID,
+		Result:      run.Result,
+		FinishedAt:  run.FinishedAt,
+		CreatedAt:   run.CreatedAt,
+	}
+
+	if run.Error != nil {
+		var e service.JobRunError
+		if err := json.Unmarshal(run.Error, &e); err != nil {
+			return nil, err
+		}
+
+		httpErr := utils.NewErrorResponse(c, e.AppError()).Error
+		// it isn't an error of this request
+		httpErr.RequestID = ""
+		res.Error = &httpErr
+	}
+
+	return res, nil
+}
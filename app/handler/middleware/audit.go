@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// AuditActor saves the client ip address and request id in the request
+// context for the audit log. It goes after RequestID, and AuthUser adds the
+// user.
+func AuditActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+			IPAddress: c.ClientIP(),
+			RequestID: c.GetString(model.RequestIDKey),
+		}))
+
+		c.Next()
+	}
+}
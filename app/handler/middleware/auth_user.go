@@ -5,6 +5,9 @@ import (
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/logging"
 	"github.com/opchaves/gin-web-app/app/model/apperrors"
 )
 
@@ -16,20 +19,23 @@ func AuthUser(logger *slog.Logger) gin.HandlerFunc {
 		id := session.Get("userId")
 
 		if id == nil {
-			e := apperrors.NewAuthorization(apperrors.InvalidSession)
-			c.JSON(e.Status(), gin.H{"error": e})
-			c.Abort()
+			abort(c, apperrors.NewAuthorization(apperrors.InvalidSession))
 			return
 		}
 
 		userId := id.(string)
 
 		c.Set("userId", userId)
+		ctx := logging.WithAttrs(c.Request.Context(), slog.String("user_id", userId))
+		if id, err := uuid.Parse(userId); err == nil {
+			ctx = audit.WithUserID(ctx, id)
+		}
+		c.Request = c.Request.WithContext(ctx)
 
 		// Recreate session to extend its lifetime
 		session.Set("userId", id)
 		if err := session.Save(); err != nil {
-			logger.Error("Failed to create session", slog.AnyValue(err.Error()))
+			logger.ErrorContext(c, "Failed to create session", slog.String("error", err.Error()))
 		}
 
 		c.Next()
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+)
+
+// BodyLimit rejects requests whose body is larger than limit bytes. Bodies
+// without a Content-Length are cut off at the limit while being read.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			abort(c, apperrors.NewPayloadTooLarge(limit, c.Request.ContentLength))
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/config"
+)
+
+// CORS applies the cross-origin policy set in the config. Allowed origins are
+// either exact ("https://app.example.com"), wildcard subdomains
+// ("https://*.example.com") or "*", which is only accepted when credentials
+// are disabled. Requests from any other origin are rejected with a 403.
+func CORS(cfg *config.Config, logger *slog.Logger) (gin.HandlerFunc, error) {
+	allowAll := slices.Contains(cfg.CorsOrigin, "*")
+
+	if allowAll && cfg.CorsCredentials {
+		return nil, fmt.Errorf("CORS_ORIGIN cannot be * when CORS_CREDENTIALS is enabled")
+	}
+
+	patterns := make([]originPattern, 0, len(cfg.CorsOrigin))
+	for _, origin := range cfg.CorsOrigin {
+		if origin == "*" {
+			continue
+		}
+
+		p, err := parseOrigin(origin, true)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+
+	return cors.New(cors.Config{
+		AllowOriginFunc: func(origin string) bool {
+			if allowAll || matchOrigin(patterns, origin) {
+				return true
+			}
+
+			logger.Warn("cors origin rejected", slog.String("origin", origin))
+			return false
+		},
+		AllowMethods:     cfg.CorsMethods,
+		AllowHeaders:     cfg.CorsHeaders,
+		AllowCredentials: cfg.CorsCredentials,
+		MaxAge:           time.Duration(cfg.CorsMaxAge) * time.Second,
+	}), nil
+}
+
+// originPattern is an allowed origin split into its parts. A wildcard
+// pattern matches any subdomain of host, but not host itself.
+type originPattern struct {
+	scheme   string
+	host     string
+	port     string
+	wildcard bool
+}
+
+// parseOrigin splits an origin of the form scheme://host[:port]
+func parseOrigin(origin string, allowWildcard bool) (originPattern, error) {
+	var p originPattern
+
+	scheme, rest, found := strings.Cut(strings.ToLower(origin), "://")
+	if !found || scheme == "" || rest == "" || strings.ContainsAny(rest, "/?#") {
+		return p, fmt.Errorf("invalid cors origin %q", origin)
+	}
+
+	if allowWildcard && strings.HasPrefix(rest, "*.") {
+		p.wildcard = true
+		rest = rest[2:]
+	}
+
+	if i := strings.LastIndex(rest, ":"); i != -1 {
+		p.port = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	if rest == "" || strings.Contains(rest, "*") {
+		return p, fmt.Errorf("invalid cors origin %q", origin)
+	}
+
+	p.scheme = scheme
+	p.host = rest
+
+	return p, nil
+}
+
+func matchOrigin(patterns []originPattern, origin string) bool {
+	o, err := parseOrigin(origin, false)
+	if err != nil {
+		return false
+	}
+
+	for _, p := range patterns {
+		if p.scheme != o.scheme || p.port != o.port {
+			continue
+		}
+
+		if p.wildcard && strings.HasSuffix(o.host, "."+p.host) {
+			return true
+		}
+
+		if !p.wildcard && p.host == o.host {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	var patterns []originPattern
+	for _, origin := range []string{"https://app.example.com", "https://*.example.org", "http://localhost:3000"} {
+		p, err := parseOrigin(origin, true)
+		assert.NoError(t, err)
+		patterns = append(patterns, p)
+	}
+
+	testCases := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"http://app.example.com", false},
+		{"https://evil.example.com", false},
+		{"https://app.example.com.evil.com", false},
+		{"https://api.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://evilexample.org", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:4000", false},
+		{"http://localhost", false},
+		{"null", false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.allowed, matchOrigin(patterns, tc.origin), tc.origin)
+	}
+}
+
+func TestParseOrigin_Invalid(t *testing.T) {
+	for _, origin := range []string{"example.com", "https://", "https://*", "https://example.com/path", "https://a.*.com"} {
+		_, err := parseOrigin(origin, true)
+		assert.Error(t, err, origin)
+	}
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/utils"
+)
+
+// CSRF protects state-changing requests authenticated by the session cookie.
+// Each session holds a synchronizer token which is saved in the context for
+// templates and must come back in the X-CSRF-Token header or the csrf_token
+// form field. Requests without a session cookie can't be forged by another
+// site and are let through. Safe requests only start a session when they
+// have a cookie already or ask for a page.
+func CSRF(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := sessions.Default(c)
+		token, _ := session.Get(model.CSRFKey).(string)
+
+		if isSafeMethod(c.Request.Method) {
+			// only start a session for clients that will use the token, so
+			// probes and scrapers don't each create one
+			if token == "" && (hasSessionCookie(c) || utils.WantsHTML(c)) {
+				token = newCSRFToken(session, logger)
+			}
+
+			c.Set(model.CSRFKey, token)
+			c.Next()
+			return
+		}
+
+		if !hasSessionCookie(c) {
+			c.Next()
+			return
+		}
+
+		if token == "" || !validCSRFToken(requestCSRFToken(c), token) {
+			abort(c, apperrors.NewForbidden(apperrors.InvalidCSRF))
+			return
+		}
+
+		c.Set(model.CSRFKey, token)
+		c.Next()
+	}
+}
+
+// ResetCSRFToken drops the session's token so a new one is issued. Call it
+// whenever the user behind the session changes.
+func ResetCSRFToken(session sessions.Session) {
+	session.Delete(model.CSRFKey)
+}
+
+func newCSRFToken(session sessions.Session, logger *slog.Logger) string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		logger.Error("failed to generate csrf token", slog.String("error", err.Error()))
+		return ""
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	session.Set(model.CSRFKey, token)
+
+	if err := session.Save(); err != nil {
+		logger.Error("failed to save csrf token", slog.String("error", err.Error()))
+		return ""
+	}
+
+	return token
+}
+
+func requestCSRFToken(c *gin.Context) string {
+	if token := c.GetHeader(model.CSRFHeader); token != "" {
+		return token
+	}
+
+	return c.PostForm(model.CSRFFormField)
+}
+
+func validCSRFToken(given, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
+
+func hasSessionCookie(c *gin.Context) bool {
+	_, err := c.Cookie(model.CookieName)
+	return err == nil
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
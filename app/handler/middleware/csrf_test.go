@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func csrfRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	router := gin.New()
+	router.Use(Errors(logger), sessions.Sessions(model.CookieName, cookie.NewStore([]byte("secret"))), CSRF(logger))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(model.CSRFKey))
+	})
+	router.POST("/", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	return router
+}
+
+func sessionCookie(res *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range res.Result().Cookies() {
+		if c.Name == model.CookieName {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestCSRF(t *testing.T) {
+	router := csrfRouter()
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("Safe Request Without Cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/json")
+
+		res := serve(req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Empty(t, res.Body.String(), "no token")
+		assert.Nil(t, sessionCookie(res), "no session started")
+	})
+
+	var session *http.Cookie
+	var token string
+
+	t.Run("Page Starts A Session", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "text/html")
+
+		res := serve(req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		token = res.Body.String()
+		assert.NotEmpty(t, token)
+		session = sessionCookie(res)
+		require.NotNil(t, session)
+
+		// the same token for the rest of the session
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(session)
+		assert.Equal(t, token, serve(req).Body.String())
+	})
+
+	t.Run("Unsafe Requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(session)
+		assert.Equal(t, http.StatusForbidden, serve(req).Code, "no token")
+
+		req = httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(session)
+		req.Header.Set(model.CSRFHeader, "wrong")
+		assert.Equal(t, http.StatusForbidden, serve(req).Code, "wrong token")
+
+		req = httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(session)
+		req.Header.Set(model.CSRFHeader, token)
+		assert.Equal(t, http.StatusNoContent, serve(req).Code)
+
+		// the app has no bearer authentication, the cookie still counts
+		req = httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(session)
+		req.Header.Set("Authorization", "Bearer anything")
+		assert.Equal(t, http.StatusForbidden, serve(req).Code, "bearer token")
+
+		// nothing to forge without a session
+		req = httptest.NewRequest(http.MethodPost, "/", nil)
+		assert.Equal(t, http.StatusNoContent, serve(req).Code)
+	})
+}
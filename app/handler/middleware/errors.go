@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/utils"
+)
+
+// Errors responds with the last error added with c.Error, unless something
+// was written already. Its apperrors.Type sets the status, and errors which
+// aren't apperrors are logged and hidden behind an internal error.
+func Errors(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		e, ok := utils.ToAppError(err)
+		if !ok || e.Type == apperrors.Internal {
+			logger.ErrorContext(c, "request failed", slog.String("error", err.Error()))
+		}
+
+		utils.RenderError(c, e)
+	}
+}
+
+// Recovery responds to panics with an internal error
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, _ any) {
+		utils.RenderError(c, apperrors.NewInternal())
+		c.Abort()
+	})
+}
+
+// abort stops the chain with e, which Errors responds with
+func abort(c *gin.Context, e *apperrors.Error) {
+	c.Error(e)
+	c.Abort()
+}
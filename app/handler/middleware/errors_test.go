@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(RequestID(), Recovery(), Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.NewNotFound("route", c.Request.URL.Path))
+	})
+
+	router.GET("/not-found", func(c *gin.Context) {
+		c.Error(apperrors.NewNotFound("user", "1"))
+	})
+	router.GET("/validation", func(c *gin.Context) {
+		c.Error(apperrors.NewValidation([]model.FieldError{{Field: "Email", Message: "email is not valid"}}))
+	})
+	router.GET("/unknown", func(c *gin.Context) {
+		c.Error(errors.New("connection refused"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		c.Error(errors.New("logged only"))
+		c.JSON(http.StatusOK, gin.H{"data": true})
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/timeout", Timeout(time.Second), func(c *gin.Context) {
+		c.Error(apperrors.NewForbidden("nope"))
+	})
+
+	get := func(path string) (*httptest.ResponseRecorder, model.HttpError) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(model.RequestIDHeader, "req-1")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var res model.ErrorResponse
+		if rr.Code >= http.StatusBadRequest {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res), rr.Body.String())
+		}
+		return rr, res.Error
+	}
+
+	t.Run("Maps The Type To The Status", func(t *testing.T) {
+		rr, e := get("/not-found")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, model.HttpError{
+			Code:      apperrors.NotFound,
+			Message:   "resource: user with value: 1 not found",
+			RequestID: "req-1",
+			Docs:      "/docs#NOTFOUND",
+		}, e)
+	})
+
+	t.Run("Lists Invalid Fields", func(t *testing.T) {
+		rr, e := get("/validation")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, apperrors.Validation, e.Code)
+		assert.Equal(t, []model.FieldError{{Field: "Email", Message: "email is not valid"}}, e.Fields)
+	})
+
+	t.Run("Hides Unknown Errors", func(t *testing.T) {
+		rr, e := get("/unknown")
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, apperrors.Internal, e.Code)
+		assert.NotContains(t, rr.Body.String(), "connection refused")
+	})
+
+	t.Run("Keeps Written Responses", func(t *testing.T) {
+		rr, _ := get("/written")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"data": true}`, rr.Body.String())
+	})
+
+	t.Run("No Route", func(t *testing.T) {
+		rr, e := get("/missing")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, apperrors.NotFound, e.Code)
+	})
+
+	t.Run("Panics", func(t *testing.T) {
+		rr, e := get("/panic")
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, apperrors.Internal, e.Code)
+		assert.Equal(t, "req-1", e.RequestID)
+	})
+
+	t.Run("Behind The Timeout", func(t *testing.T) {
+		rr, e := get("/timeout")
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, apperrors.Forbidden, e.Code)
+	})
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// Language picks the language of the request from the profile of the user,
+// saved in the session on login, then the Accept-Language header and then
+// the default. Its localizer goes in the request context, see i18n.From.
+func Language(catalog *i18n.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		profile, _ := sessions.Default(c).Get(model.LanguageKey).(string)
+		lang := catalog.Match(profile, c.GetHeader("Accept-Language"))
+
+		c.Set(model.LanguageKey, lang)
+		c.Header("Content-Language", lang)
+		c.Request = c.Request.WithContext(i18n.WithLocalizer(c.Request.Context(), catalog.Localizer(lang)))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/logging"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(model.RequestIDKey))
+	})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if id != "" {
+			req.Header.Set(model.RequestIDHeader, id)
+		}
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("Reused", func(t *testing.T) {
+		res := get("req-1")
+		assert.Equal(t, "req-1", res.Header().Get(model.RequestIDHeader))
+		assert.Equal(t, "req-1", res.Body.String())
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		for name, id := range map[string]string{
+			"Missing":   "",
+			"Too Long":  strings.Repeat("a", maxRequestIDLength+1),
+			"Not ASCII": "req 1\n",
+		} {
+			res := get(id)
+			generated := res.Header().Get(model.RequestIDHeader)
+			_, err := uuid.Parse(generated)
+			assert.NoError(t, err, name)
+			assert.Equal(t, generated, res.Body.String(), name)
+		}
+
+		assert.NotEqual(t, get("").Header().Get(model.RequestIDHeader), get("").Header().Get(model.RequestIDHeader))
+	})
+}
+
+func TestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, "info", logging.FormatJSON)
+	require.NoError(t, err)
+
+	userId := uuid.NewString()
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(RequestID(), Logger(logger), Errors(logger), sessions.Sessions("session", cookie.NewStore([]byte("secret"))))
+	router.POST("/login", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("userId", userId)
+		require.NoError(t, session.Save())
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/items/:id", AuthUser(logger), func(c *gin.Context) {
+		// the way services log, with the context of the request
+		logger.WarnContext(c, "item is stale", slog.String("item_id", c.Param("id")))
+		c.String(http.StatusOK, "item")
+	})
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/login", nil))
+	require.Equal(t, http.StatusNoContent, res.Code)
+
+	logs.Reset()
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	req.Header.Set(model.RequestIDHeader, "req-1")
+	for _, c := range res.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var service, access map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &service))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &access))
+
+	assert.Equal(t, "item is stale", service["msg"])
+	assert.Equal(t, "42", service["item_id"])
+	assert.Equal(t, "req-1", service["request_id"])
+	assert.Equal(t, userId, service["user_id"])
+
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "INFO", access["level"])
+	assert.Equal(t, http.MethodGet, access["method"])
+	assert.Equal(t, "/items/:id", access["route"])
+	assert.Equal(t, "/items/42", access["path"])
+	assert.Equal(t, float64(http.StatusOK), access["status"])
+	assert.Equal(t, float64(len("item")), access["bytes"])
+	assert.Equal(t, "req-1", access["request_id"])
+	assert.Equal(t, userId, access["user_id"])
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		logs.Reset()
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/items/42", nil))
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+
+		lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+		var access map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &access))
+		assert.Equal(t, "WARN", access["level"])
+		assert.Equal(t, float64(http.StatusUnauthorized), access["status"])
+		assert.NotContains(t, access, "user_id")
+		_, err := uuid.Parse(access["request_id"].(string))
+		assert.NoError(t, err)
+	})
+}
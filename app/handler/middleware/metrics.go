@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/metrics"
+)
+
+// Metrics records the count and latency of requests per route and status
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		m.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/logging"
+	"github.com/opchaves/gin-web-app/app/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRequestIDLength caps the size of request ids taken from clients
+const maxRequestIDLength = 128
+
+// RequestID takes the request id from the X-Request-ID header or generates a
+// new one. The id is sent back in the same header, saved in the context as
+// requestId and added to every log record made with the request context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(model.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(model.RequestIDKey, id)
+		c.Header(model.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(
+			logging.WithAttrs(c.Request.Context(), slog.String("request_id", id)),
+		)
+		trace.SpanFromContext(c).SetAttributes(attribute.String("request.id", id))
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts ids of printable ASCII only, so they are safe to
+// log and echo back
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
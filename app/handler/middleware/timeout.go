@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/utils"
+)
+
+// Timeout cancels the request context once timeout is exceeded and responds
+// with a 503. Handlers write into a buffer which is only sent when they
+// finish in time, so a timed out response is never half-written.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		w := c.Writer
+		tw := &timeoutWriter{
+			ResponseWriter: w,
+			header:         w.Header().Clone(),
+			status:         http.StatusOK,
+		}
+		c.Writer = tw
+
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+
+			c.Next()
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			c.Writer = w
+			panic(p)
+		case <-done:
+			tw.flush()
+			c.Writer = w
+		case <-ctx.Done():
+			tw.timeout(utils.NewErrorResponse(c, apperrors.NewServiceUnavailable()))
+
+			// gin reuses the context once we return, so wait for the handler
+			// which should be quick now that its context is cancelled
+			select {
+			case <-done:
+			case <-panicked:
+			}
+
+			c.Writer = w
+			c.Abort()
+		}
+	}
+}
+
+// timeoutWriter buffers the response until the handler is done
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) WriteString(s string) (int, error) {
+	return tw.Write([]byte(s))
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+
+	tw.status = code
+}
+
+func (tw *timeoutWriter) WriteHeaderNow() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.wroteHeader = true
+}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.status
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if !tw.wroteHeader {
+		return -1
+	}
+	return tw.body.Len()
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.wroteHeader
+}
+
+// Flush is a no-op as nothing leaves the buffer before the handler is done
+func (tw *timeoutWriter) Flush() {}
+
+// flush sends the buffered response to the client
+func (tw *timeoutWriter) flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	dst := tw.ResponseWriter.Header()
+	for k := range dst {
+		if _, ok := tw.header[k]; !ok {
+			dst.Del(k)
+		}
+	}
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+
+	// only the status is set when nothing was written, so an error the
+	// handler added with c.Error can still be rendered
+	tw.ResponseWriter.WriteHeader(tw.status)
+	if tw.body.Len() > 0 {
+		tw.ResponseWriter.Write(tw.body.Bytes())
+	} else if tw.wroteHeader {
+		tw.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// timeout discards whatever the handler wrote and sends res instead
+func (tw *timeoutWriter) timeout(res model.ErrorResponse) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.timedOut = true
+	tw.body.Reset()
+	tw.status = res.Error.Code.Status()
+
+	body, _ := json.Marshal(res)
+
+	tw.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tw.ResponseWriter.WriteHeader(tw.status)
+	tw.ResponseWriter.Write(body)
+	tw.ResponseWriter.Flush()
+}
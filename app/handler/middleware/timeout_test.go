@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))), Timeout(50*time.Millisecond), BodyLimit(16))
+
+	router.GET("/fast", func(c *gin.Context) {
+		c.Header("X-Handler", "fast")
+		c.JSON(http.StatusCreated, gin.H{"data": true})
+	})
+	router.GET("/slow", func(c *gin.Context) {
+		c.Header("X-Handler", "slow")
+		c.String(http.StatusOK, "partial")
+		<-c.Done()
+		c.String(http.StatusOK, "rest")
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.POST("/body", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("Handler Finishes In Time", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, "fast", rr.Header().Get("X-Handler"))
+		assert.JSONEq(t, `{"data": true}`, rr.Body.String())
+	})
+
+	t.Run("Handler Times Out", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Empty(t, rr.Header().Get("X-Handler"))
+		assert.NotContains(t, rr.Body.String(), "partial")
+		assert.Contains(t, rr.Body.String(), "SERVICE_UNAVAILABLE")
+	})
+
+	t.Run("Handler Panics", func(t *testing.T) {
+		assert.Panics(t, func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+		})
+	})
+
+	t.Run("Body Too Large", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/body", strings.NewReader(strings.Repeat("a", 17))))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Max payload size of 16 exceeded")
+	})
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/logging"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+// Workspace loads the workspace of the :workspaceId param if it belongs to
+// the user and saves it in the context. It goes after AuthUser.
+func Workspace(workspaceService service.WorkspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.MustGet("userId").(string)
+
+		workspace, err := workspaceService.GetUserWorkspace(c, userId, c.Param("workspaceId"))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(model.WorkspaceKey, workspace)
+		c.Request = c.Request.WithContext(
+			logging.WithAttrs(c.Request.Context(), slog.String("workspace_id", workspace.ID.String())),
+		)
+
+		c.Next()
+	}
+}
@@ -2,19 +2,38 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	"github.com/opchaves/gin-web-app/app/i18n"
 	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
 )
 
+// bindError adds the error of binding the request for the Errors middleware.
+// Bodies over the limit are reported as such, anything else as invalid fields
+// in the language of the request.
+func bindError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.Error(err)
+		return
+	}
+
+	c.Error(apperrors.NewValidation(parseError(i18n.From(c), err)))
+}
+
 // parseError takes an error or multiple errors and attempts to determine the best path to convert them into
 // human readable strings
-func parseError(errs ...error) []model.FieldError {
+func parseError(l *i18n.Localizer, errs ...error) []model.FieldError {
 	var out []model.FieldError
 	for _, err := range errs {
 		switch typedError := any(err).(type) {
@@ -24,13 +43,13 @@ func parseError(errs ...error) []model.FieldError {
 			for _, e := range typedError {
 				fieldErr := model.FieldError{
 					Field:   e.Field(),
-					Message: parseFieldError(e),
+					Message: parseFieldError(l, e),
 				}
 				out = append(out, fieldErr)
 			}
 		case *json.UnmarshalTypeError:
 			// similarly, if the error is an unmarshalling error we'll parse it into another, more readable string format
-			out = append(out, parseMarshallingError(*typedError))
+			out = append(out, parseMarshallingError(l, *typedError))
 		default:
 			out = append(out, model.FieldError{
 				Field:   "Error",
@@ -41,55 +60,46 @@ func parseError(errs ...error) []model.FieldError {
 	return out
 }
 
-func parseFieldError(e validator.FieldError) string {
+func parseFieldError(l *i18n.Localizer, e validator.FieldError) string {
 	// workaround to the fact that the `gt|gtfield=Start` gets passed as an entire tag for some reason
 	// https://github.com/go-playground/validator/issues/926
-	fieldPrefix := fmt.Sprintf("The field %s", e.Field())
 	tag := strings.Split(e.Tag(), "|")[0]
+	param := e.Param()
+
 	switch tag {
-	case "required_without":
-		return fmt.Sprintf("%s is required if %s is not supplied", fieldPrefix, e.Param())
-	case "required":
-		return fmt.Sprintf("cannot be empty")
-	case "email":
-		return fmt.Sprintf("email is not valid")
-	case "min":
-		if e.Type().Name() == "string" {
-			return fmt.Sprintf("cannot have less than %s characters", e.Param())
-		}
-		return fmt.Sprintf("min value is %s, %s", e.Param(), e.Type().String())
-	case "max":
-		if e.Type().Name() == "string" {
-			return fmt.Sprintf("cannot be longer than %s characters", e.Param())
-		}
-		return fmt.Sprintf("max value is %s", e.Param())
-	case "lt", "ltfield":
-		param := e.Param()
-		if param == "" {
-			param = time.Now().Format(time.RFC3339)
+	case "min", "max":
+		// the limits of strings and lists are lengths
+		switch e.Kind() {
+		case reflect.String:
+			tag += "_string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			tag += "_list"
 		}
-		return fmt.Sprintf("%s must be less than %s", fieldPrefix, param)
-	case "gt", "gtfield":
-		param := e.Param()
+	case TagWebhookEvent:
+		tag, param = "oneof", webhookEvents()
+	case "lt", "ltfield", "gt", "gtfield":
+		tag = strings.TrimSuffix(tag, "field")
 		if param == "" {
 			param = time.Now().Format(time.RFC3339)
 		}
-		return fmt.Sprintf("%s must be greater than %s", fieldPrefix, param)
-	default:
-		// if it's a tag for which we don't have a good format string yet we'll try using the default english translator
-		english := en.New()
-		translator := ut.New(english, english)
-		if translatorInstance, found := translator.GetTranslator("en"); found {
-			return e.Translate(translatorInstance)
-		} else {
-			return fmt.Errorf("%v", e).Error()
-		}
 	}
+
+	if _, ok := l.Lookup("validation." + tag); ok {
+		return l.T("validation."+tag, "field", e.Field(), "param", param)
+	}
+
+	// if it's a tag for which we don't have a message yet we'll try using the default english translator
+	english := en.New()
+	translator := ut.New(english, english)
+	if translatorInstance, found := translator.GetTranslator("en"); found {
+		return e.Translate(translatorInstance)
+	}
+	return fmt.Errorf("%v", e).Error()
 }
 
-func parseMarshallingError(e json.UnmarshalTypeError) model.FieldError {
+func parseMarshallingError(l *i18n.Localizer, e json.UnmarshalTypeError) model.FieldError {
 	return model.FieldError{
 		Field:   e.Field,
-		Message: fmt.Sprintf("must be a %s", e.Type.String()),
+		Message: l.T("validation.type", "type", e.Type.String()),
 	}
 }
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+func (h *Handler) GetPayees(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	payees, err := h.PayeeService.List(c, workspace.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": payees})
+}
+
+func (h *Handler) CreatePayee(c *gin.Context) {
+	var req service.PayeeInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	payee, err := h.PayeeService.Create(c, workspace.ID, userId, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": payee})
+}
+
+func (h *Handler) UpdatePayee(c *gin.Context) {
+	var req service.PayeeInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	payee, err := h.PayeeService.Update(c, workspace.ID, c.Param("payeeId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": payee})
+}
+
+func (h *Handler) DeletePayee(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	if err := h.PayeeService.Delete(c, workspace.ID, c.Param("payeeId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, true)
+}
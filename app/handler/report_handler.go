@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+func (h *Handler) GetCategoryReport(c *gin.Context) {
+	var req service.ReportFilter
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	rows, err := h.ReportService.Categories(c, workspace.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+func (h *Handler) GetTagReport(c *gin.Context) {
+	var req service.ReportFilter
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	rows, err := h.ReportService.Tags(c, workspace.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+func (h *Handler) GetPayeeReport(c *gin.Context) {
+	var req service.ReportFilter
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	rows, err := h.ReportService.Payees(c, workspace.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+func (h *Handler) GenerateReport(c *gin.Context) {
+	var req service.ReportInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	h.startJob(c, service.JobRunReport, &req)
+}
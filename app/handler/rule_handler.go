@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+func (h *Handler) GetRules(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	rules, err := h.RuleService.List(c, workspace.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+func (h *Handler) CreateRule(c *gin.Context) {
+	var req service.RuleInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	rule, err := h.RuleService.Create(c, workspace.ID, userId, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": rule})
+}
+
+func (h *Handler) UpdateRule(c *gin.Context) {
+	var req service.RuleInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	rule, err := h.RuleService.Update(c, workspace.ID, c.Param("ruleId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+func (h *Handler) DeleteRule(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	if err := h.RuleService.Delete(c, workspace.ID, c.Param("ruleId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, true)
+}
+
+// RunRules applies the rules to the transactions, or lists what they would
+// change on a dry run
+func (h *Handler) RunRules(c *gin.Context) {
+	var req service.RunRulesInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	result, err := h.RuleService.Run(c, workspace.ID, userId, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *Handler) GetRuleSuggestions(c *gin.Context) {
+	var req service.SuggestRulesInput
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	suggestions, err := h.RuleService.Suggestions(c, workspace.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+func (h *Handler) GetTags(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	tags, err := h.TagService.List(c, workspace.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+func (h *Handler) DeleteTag(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	if err := h.TagService.Delete(c, workspace.ID, c.Param("tagId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, true)
+}
+
+func (h *Handler) GetTransactionTags(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	tags, err := h.TagService.TransactionTags(c, workspace.ID, c.Param("transactionId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// SetTransactionTags replaces the tags of the transaction
+func (h *Handler) SetTransactionTags(c *gin.Context) {
+	var req service.TagsInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	tags, err := h.TagService.SetTransactionTags(c, workspace.ID, userId, c.Param("transactionId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+func (h *Handler) CreateTransaction(c *gin.Context) {
+	var req service.TransactionInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	transaction, err := h.TransactionService.Create(c, workspace, userId, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": transaction})
+}
+
+func (h *Handler) ImportTransactions(c *gin.Context) {
+	var req service.ImportTransactionsInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	h.startJob(c, service.JobRunImport, &req)
+}
+
+func (h *Handler) UpdateTransaction(c *gin.Context) {
+	var req service.UpdateTransactionInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	transaction, err := h.TransactionService.Update(c, workspace.ID, userId, c.Param("transactionId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": transaction})
+}
+
+func (h *Handler) SearchTransactions(c *gin.Context) {
+	var req service.SearchTransactionsInput
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	rows, err := h.TransactionService.Search(c, workspace, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+func (h *Handler) GetTransactionSplits(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	splits, err := h.TransactionService.Splits(c, workspace.ID, c.Param("transactionId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": splits})
+}
+
+// SplitTransaction replaces the lines of the transaction
+func (h *Handler) SplitTransaction(c *gin.Context) {
+	var req service.SplitTransactionInput
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	splits, err := h.TransactionService.Split(c, workspace.ID, c.Param("transactionId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": splits})
+}
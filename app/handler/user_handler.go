@@ -6,6 +6,8 @@ import (
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/opchaves/gin-web-app/app/model"
 	"github.com/opchaves/gin-web-app/app/model/apperrors"
 	"github.com/opchaves/gin-web-app/app/service"
 	"github.com/opchaves/gin-web-app/app/utils"
@@ -17,62 +19,71 @@ func (h *Handler) Register(c *gin.Context) {
 	var req service.RegisterInput
 
 	if err := c.ShouldBind(&req); err != nil {
-		errors := parseError(err)
-		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		bindError(c, err)
 		return
 	}
 
 	user, err := h.UserService.Register(c, &req)
 
 	if err != nil {
-		if err.Error() == apperrors.NewBadRequest(apperrors.DuplicateEmail).Error() {
-			utils.ToFieldErrorResponse(c, "Email", apperrors.DuplicateEmail)
-			return
-		}
-
-		c.JSON(apperrors.Status(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
 	}
 
-	h.setUserSession(c, user.ID.String())
+	h.setUserSession(c, user.ID)
 
-	c.JSON(http.StatusCreated, gin.H{"data": user})
+	utils.Render(c, http.StatusCreated, utils.Negotiated{
+		JSON:     gin.H{"data": user},
+		Redirect: "/",
+	})
 }
 
 func (h *Handler) Login(c *gin.Context) {
 	var req service.LoginInput
 
 	if err := c.ShouldBind(&req); err != nil {
-		errors := parseError(err)
-		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		bindError(c, err)
 		return
 	}
 
 	user, err := h.UserService.Login(c, &req)
 
 	if err != nil {
-		c.JSON(apperrors.Status(err), gin.H{"error": err})
+		c.Error(err)
 		return
 	}
 
-	h.setUserSession(c, user.ID.String())
+	h.setUserSession(c, user.ID)
 
-	c.JSON(http.StatusOK, gin.H{"data": user})
+	utils.Render(c, http.StatusOK, utils.Negotiated{
+		JSON:     gin.H{"data": user},
+		Redirect: "/",
+	})
 }
 
 func (h *Handler) Logout(c *gin.Context) {
 	c.Set("user", nil)
 
 	session := sessions.Default(c)
+	if userId, ok := session.Get("userId").(string); ok && userId != "" {
+		if err := h.UserService.Logout(c, userId); err != nil {
+			h.Logger.WarnContext(c, "error recording logout", slog.Any("error", err))
+		}
+	}
+
 	session.Set("userId", "")
 	session.Clear()
 	session.Options(sessions.Options{Path: "/", MaxAge: -1})
 	err := session.Save()
 
 	if err != nil {
-		h.Logger.Warn("error clearing sessions", slog.AnyValue(err))
+		h.Logger.WarnContext(c, "error clearing sessions", slog.Any("error", err))
 	}
 
-	c.JSON(http.StatusOK, true)
+	utils.Render(c, http.StatusOK, utils.Negotiated{
+		JSON:     true,
+		Redirect: "/",
+	})
 }
 
 func (h *Handler) GetCurrent(c *gin.Context) {
@@ -80,22 +91,25 @@ func (h *Handler) GetCurrent(c *gin.Context) {
 	user, err := h.UserService.GetById(c, userId)
 
 	if err != nil {
-		h.Logger.Info("Unable to find user", slog.AnyValue(err))
-		e := apperrors.NewNotFound("user", userId)
-
-		c.JSON(e.Status(), gin.H{"error": e})
+		h.Logger.InfoContext(c, "Unable to find user", slog.Any("error", err))
+		c.Error(apperrors.NewNotFound("user", userId))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": user})
 }
 
+// GetCSRFToken returns the session's CSRF token for API clients using the
+// session cookie
+func (h *Handler) GetCSRFToken(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"token": c.GetString(model.CSRFKey)}})
+}
+
 func (h *Handler) ForgotPassword(c *gin.Context) {
 	var req service.ForgotPasswordInput
 
 	if err := c.ShouldBind(&req); err != nil {
-		errors := parseError(err)
-		c.JSON(http.StatusBadRequest, gin.H{"errors": errors})
+		bindError(c, err)
 		return
 	}
 
@@ -103,13 +117,12 @@ func (h *Handler) ForgotPassword(c *gin.Context) {
 
 	if err != nil {
 		// No user with the email found
-		if err.Error() == apperrors.NewNotFound("email", req.Email).Error() {
-			c.JSON(http.StatusOK, true)
+		if apperrors.IsType(err, apperrors.NotFound) {
+			h.renderResetEmailSent(c)
 			return
 		}
 
-		e := apperrors.NewInternal()
-		c.JSON(e.Status(), gin.H{"error": e})
+		c.Error(err)
 		return
 	}
 
@@ -117,13 +130,23 @@ func (h *Handler) ForgotPassword(c *gin.Context) {
 	err = h.UserService.ForgotPassword(ctx, user)
 
 	if err != nil {
-		h.Logger.Warn("error sending reset password email", slog.AnyValue(err))
-		e := apperrors.NewInternal()
-		c.JSON(e.Status(), gin.H{
-			"error": e,
-		})
+		h.Logger.WarnContext(ctx, "error sending reset password email", slog.Any("error", err))
+		c.Error(apperrors.NewInternal())
 		return
 	}
 
-	c.JSON(http.StatusOK, true)
+	h.renderResetEmailSent(c)
+}
+
+// renderResetEmailSent responds the same way whether the email exists or not
+// so the form can't be used to find registered accounts
+func (h *Handler) renderResetEmailSent(c *gin.Context) {
+	utils.Render(c, http.StatusOK, utils.Negotiated{
+		JSON:     true,
+		Fragment: "flash.html",
+		Data: gin.H{
+			"level":   "success",
+			"message": i18n.From(c).T("pages.forgot_password.sent"),
+		},
+	})
 }
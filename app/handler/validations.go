@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+)
+
+// TagWebhookEvent validates the fields holding a webhook event, against
+// webhooks.Events so a new event is accepted as soon as it's listed there
+const TagWebhookEvent = "webhook_event"
+
+// RegisterValidations adds the tags of the app to the validator gin binds the
+// requests with
+func RegisterValidations() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unexpected validator %T", binding.Validator.Engine())
+	}
+
+	return v.RegisterValidation(TagWebhookEvent, func(fl validator.FieldLevel) bool {
+		return webhooks.IsEvent(fl.Field().String())
+	})
+}
+
+// webhookEvents is the param of the message of an invalid webhook event
+func webhookEvents() string {
+	return strings.Join(webhooks.Events, " ")
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	hooks, err := h.WebhookService.List(c, workspace.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": hooks})
+}
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req service.WebhookInput
+
+	if err := c.ShouldBind(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+	userId := uuid.MustParse(c.MustGet("userId").(string))
+
+	hook, err := h.WebhookService.Create(c, workspace, userId, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// the only response with the secret
+	c.JSON(http.StatusCreated, gin.H{"data": hook})
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	if err := h.WebhookService.Delete(c, workspace.ID, c.Param("webhookId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, true)
+}
+
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	var req service.DeliveriesInput
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	deliveries, err := h.WebhookService.ListDeliveries(c, workspace.ID, c.Param("webhookId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+func (h *Handler) ReplayWebhookDelivery(c *gin.Context) {
+	workspace := c.MustGet(model.WorkspaceKey).(*model.Workspace)
+
+	delivery, err := h.WebhookService.Replay(c, workspace.ID, c.Param("webhookId"), c.Param("deliveryId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": delivery})
+}
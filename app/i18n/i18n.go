@@ -0,0 +1,230 @@
+// Package i18n translates the messages of the app.
+//
+// Catalogues are YAML files named after their language, like pt.yaml, with
+// nested keys which are joined with dots, e.g. errors.not_found. Messages
+// take {name} placeholders filled by the arguments of T. A key missing in a
+// language is looked up in its base language, pt for pt-br, and then in the
+// default one.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLanguage is the language used when nothing better matches
+const DefaultLanguage = "en"
+
+//go:embed locales/*.yaml
+var localesFS embed.FS
+
+// Catalog holds the messages of every language
+type Catalog struct {
+	fallback string
+	langs    []string
+	messages map[string]map[string]string
+	matcher  language.Matcher
+}
+
+// Config of the catalogue
+type Config struct {
+	// Default is the language of last resort, DefaultLanguage when empty
+	Default string
+	// Dir holds catalogues which extend and override the embedded ones
+	Dir string
+}
+
+// Load reads the embedded catalogues and the ones of cfg.Dir
+func Load(cfg *Config) (*Catalog, error) {
+	messages := map[string]map[string]string{}
+
+	if err := readCatalogs(localesFS, "locales", messages); err != nil {
+		return nil, err
+	}
+	if cfg.Dir != "" {
+		if err := readCatalogs(os.DirFS(cfg.Dir), ".", messages); err != nil {
+			return nil, err
+		}
+	}
+
+	fallback := strings.ToLower(cfg.Default)
+	if fallback == "" {
+		fallback = DefaultLanguage
+	}
+	if _, ok := messages[fallback]; !ok {
+		return nil, fmt.Errorf("i18n: no catalogue for the default language %q", fallback)
+	}
+
+	// the matcher falls back to the first language
+	langs := []string{fallback}
+	for lang := range messages {
+		if lang != fallback {
+			langs = append(langs, lang)
+		}
+	}
+
+	tags := make([]language.Tag, 0, len(langs))
+	for _, lang := range langs {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: invalid language %q: %w", lang, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return &Catalog{
+		fallback: fallback,
+		langs:    langs,
+		messages: messages,
+		matcher:  language.NewMatcher(tags),
+	}, nil
+}
+
+// readCatalogs adds the messages of the *.yaml files in dir to messages
+func readCatalogs(fsys fs.FS, dir string, messages map[string]map[string]string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		var tree map[string]any
+		if err := yaml.Unmarshal(b, &tree); err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+
+		lang := strings.ToLower(strings.TrimSuffix(path.Base(file), ".yaml"))
+		if messages[lang] == nil {
+			messages[lang] = map[string]string{}
+		}
+		if err := flatten(messages[lang], "", tree); err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// flatten adds the messages of tree to messages, joining nested keys with dots
+func flatten(messages map[string]string, prefix string, tree map[string]any) error {
+	for k, v := range tree {
+		key := prefix + k
+
+		switch v := v.(type) {
+		case string:
+			messages[key] = v
+		case map[string]any:
+			if err := flatten(messages, key+".", v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s is not a message", key)
+		}
+	}
+
+	return nil
+}
+
+// Languages returns the languages with a catalogue, the default first
+func (c *Catalog) Languages() []string {
+	return c.langs
+}
+
+// Default returns the language of last resort
+func (c *Catalog) Default() string {
+	return c.fallback
+}
+
+// Keys returns the keys of the messages of lang
+func (c *Catalog) Keys(lang string) []string {
+	keys := make([]string, 0, len(c.messages[lang]))
+	for key := range c.messages[lang] {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Match returns the language with a catalogue which best fits the first
+// preference that fits any. Preferences are language tags or Accept-Language
+// headers, empty ones are skipped. It returns the default when none fits.
+func (c *Catalog) Match(preferences ...string) string {
+	for _, pref := range preferences {
+		if pref == "" {
+			continue
+		}
+
+		tags, _, err := language.ParseAcceptLanguage(pref)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+
+		if _, i, confidence := c.matcher.Match(tags...); confidence != language.No {
+			return c.langs[i]
+		}
+	}
+
+	return c.fallback
+}
+
+// Lookup returns the message of key in lang, its base language or the default
+func (c *Catalog) Lookup(lang, key string) (string, bool) {
+	lang = strings.ToLower(lang)
+
+	candidates := []string{lang}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, c.fallback)
+
+	for _, l := range candidates {
+		if msg, ok := c.messages[l][key]; ok {
+			return msg, true
+		}
+	}
+
+	return "", false
+}
+
+// T returns the message of key in lang with its placeholders replaced by
+// args, given as name and value pairs. Keys without a message are returned
+// as is.
+func (c *Catalog) T(lang, key string, args ...any) string {
+	msg, ok := c.Lookup(lang, key)
+	if !ok {
+		return key
+	}
+
+	return format(msg, args)
+}
+
+// Localizer returns the translator of lang
+func (c *Catalog) Localizer(lang string) *Localizer {
+	return &Localizer{catalog: c, Lang: lang}
+}
+
+// format replaces the {name} placeholders of msg with the name and value
+// pairs of args
+func format(msg string, args []any) string {
+	if len(args) < 2 {
+		return msg
+	}
+
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
@@ -0,0 +1,102 @@
+package i18n
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var placeholder = regexp.MustCompile(`\{\w+\}`)
+
+func TestCatalogs(t *testing.T) {
+	c := Embedded()
+	en := c.Keys(DefaultLanguage)
+	sort.Strings(en)
+
+	t.Run("Every Language Has The Same Keys", func(t *testing.T) {
+		for _, lang := range c.Languages() {
+			keys := c.Keys(lang)
+			sort.Strings(keys)
+			assert.Equal(t, en, keys, lang)
+		}
+	})
+
+	t.Run("Translations Keep The Placeholders", func(t *testing.T) {
+		for _, lang := range c.Languages() {
+			for _, key := range en {
+				want, _ := c.Lookup(DefaultLanguage, key)
+				got, _ := c.Lookup(lang, key)
+				assert.ElementsMatch(t, placeholder.FindAllString(want, -1), placeholder.FindAllString(got, -1), "%s %s", lang, key)
+			}
+		}
+	})
+
+	t.Run("English Matches The Error Messages", func(t *testing.T) {
+		for message := range apperrors.MessageKeys {
+			got, ok := c.Lookup(DefaultLanguage, apperrors.MessageKey(message))
+			if assert.True(t, ok, message) {
+				assert.Equal(t, message, got)
+			}
+		}
+	})
+}
+
+func TestMatch(t *testing.T) {
+	c := Embedded()
+
+	assert.Equal(t, "pt", c.Match("pt-BR"))
+	assert.Equal(t, "pt", c.Match("fr-FR, pt;q=0.8, en;q=0.5"))
+	assert.Equal(t, "en", c.Match("fr"))
+	assert.Equal(t, "en", c.Match())
+	// the first preference which fits wins, like the profile over the header
+	assert.Equal(t, "pt", c.Match("", "pt-br", "en"))
+	assert.Equal(t, "en", c.Match("de", "en-us", "pt"))
+	assert.Equal(t, "en", c.Match("not a language"))
+}
+
+func TestT(t *testing.T) {
+	c := Embedded()
+
+	assert.Equal(t, "cannot have less than 10 characters", c.T("en", "validation.min_string", "param", 10))
+	assert.Equal(t, "não pode ter menos de 10 caracteres", c.T("pt-br", "validation.min_string", "param", 10))
+	assert.Equal(t, "no.such.key", c.T("pt", "no.such.key"))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pt.yaml"), []byte("validation:\n  email: email errado\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "es.yaml"), []byte("validation:\n  required: no puede estar vacío\n"), 0o644))
+
+	c, err := Load(&Config{Default: "en", Dir: dir})
+	require.NoError(t, err)
+
+	assert.Equal(t, "email errado", c.T("pt", "validation.email"))
+	assert.Equal(t, "não pode ficar vazio", c.T("pt", "validation.required"))
+	assert.Equal(t, "es", c.Match("es-AR"))
+	assert.Equal(t, "no puede estar vacío", c.T("es", "validation.required"))
+	// missing in es, so it falls back to the default
+	assert.Equal(t, "email is not valid", c.T("es", "validation.email"))
+
+	_, err = Load(&Config{Default: "de"})
+	assert.Error(t, err)
+}
+
+func TestLocalizerError(t *testing.T) {
+	pt := Embedded().Localizer("pt")
+
+	assert.Equal(t, "Requisição inválida. Motivo: A busca não tem palavras", pt.Error(apperrors.NewBadRequest(apperrors.EmptySearchQuery)))
+	assert.Equal(t, "recurso: webhook com valor: 1 não encontrado", pt.Error(apperrors.NewNotFound("webhook", "1")))
+	assert.Equal(t, "Não autorizado", pt.Error(apperrors.NewAuthorization(apperrors.Unauthorized)))
+	// errors without a key keep their message
+	assert.Equal(t, "query too long", pt.Error(apperrors.NewForbidden("query too long")))
+
+	assert.Equal(t, DefaultLanguage, From(context.Background()).Lang)
+	assert.Equal(t, "pt", From(WithLocalizer(context.Background(), pt)).Lang)
+}
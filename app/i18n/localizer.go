@@ -0,0 +1,102 @@
+package i18n
+
+import (
+	"context"
+	"sync"
+
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+)
+
+type contextKey struct{}
+
+// Localizer translates messages into one language
+type Localizer struct {
+	catalog *Catalog
+	Lang    string
+}
+
+// T returns the message of key, see Catalog.T
+func (l *Localizer) T(key string, args ...any) string {
+	return l.catalog.T(l.Lang, key, args...)
+}
+
+// Lookup returns the message of key, see Catalog.Lookup
+func (l *Localizer) Lookup(key string) (string, bool) {
+	return l.catalog.Lookup(l.Lang, key)
+}
+
+// Error returns the message of e. Params holding a message with a key, like
+// the reason of a bad request, are translated too. Errors without a key keep
+// their message.
+func (l *Localizer) Error(e *apperrors.Error) string {
+	if e.Key == "" {
+		return e.Message
+	}
+
+	msg, ok := l.Lookup(e.Key)
+	if !ok {
+		return e.Message
+	}
+
+	args := make([]any, 0, len(e.Params)*2)
+	for name, value := range e.Params {
+		if key := apperrors.MessageKey(value); key != "" {
+			value = l.T(key)
+		}
+		args = append(args, name, value)
+	}
+
+	return format(msg, args)
+}
+
+// Fields returns the invalid fields of e with the messages known to the
+// catalogue translated
+func (l *Localizer) Fields(e *apperrors.Error) []apperrors.FieldError {
+	if len(e.Fields) == 0 {
+		return e.Fields
+	}
+
+	fields := make([]apperrors.FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		if key := apperrors.MessageKey(f.Message); key != "" {
+			f.Message = l.T(key)
+		}
+		fields[i] = f
+	}
+
+	return fields
+}
+
+// WithLocalizer returns a copy of ctx carrying l
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// From returns the localizer of ctx, or the one of the default language of
+// the embedded catalogues when it has none
+func From(ctx context.Context) *Localizer {
+	if l, ok := ctx.Value(contextKey{}).(*Localizer); ok {
+		return l
+	}
+
+	return Embedded().Localizer(DefaultLanguage)
+}
+
+var (
+	embeddedOnce    sync.Once
+	embeddedCatalog *Catalog
+)
+
+// Embedded returns the catalogue of the embedded files only. They are
+// checked by the tests, so loading them can't fail.
+func Embedded() *Catalog {
+	embeddedOnce.Do(func() {
+		c, err := Load(&Config{})
+		if err != nil {
+			panic(err)
+		}
+		embeddedCatalog = c
+	})
+
+	return embeddedCatalog
+}
@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T) (*Queue, *Worker) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	w := NewWorker(&WorkerConfig{
+		Redis:        rdb,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Queues:       []string{QueueEmails, QueueDefault},
+		PollInterval: 10 * time.Millisecond,
+		Backoff:      func(int) time.Duration { return 0 },
+	})
+
+	return NewQueue(rdb), w
+}
+
+func TestWorker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Runs Jobs By Priority", func(t *testing.T) {
+		q, w := setup(t)
+
+		var order []string
+		w.Handle("greet", func(ctx context.Context, job *Job) error {
+			var name string
+			require.NoError(t, job.Decode(&name))
+			order = append(order, name)
+			return nil
+		})
+
+		_, err := q.Enqueue(ctx, QueueDefault, "greet", "default")
+		require.NoError(t, err)
+		_, err = q.Enqueue(ctx, QueueEmails, "greet", "emails")
+		require.NoError(t, err)
+
+		n, err := w.ProcessAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"emails", "default"}, order)
+
+		stats, err := q.Stats(ctx, QueueDefault)
+		require.NoError(t, err)
+		assert.Equal(t, &Stats{}, stats)
+	})
+
+	t.Run("Retries Then Dead Letter", func(t *testing.T) {
+		q, w := setup(t)
+
+		calls := 0
+		w.Handle("fail", func(ctx context.Context, job *Job) error {
+			calls++
+			return errors.New("boom")
+		})
+
+		_, err := q.Enqueue(ctx, QueueDefault, "fail", nil, MaxAttempts(3))
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err := w.ProcessAll(ctx)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, calls)
+
+		dead, err := q.Dead(ctx, QueueDefault)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, 3, dead[0].Attempts)
+		assert.Equal(t, "boom", dead[0].LastError)
+
+		n, err := q.RetryDead(ctx, QueueDefault)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		stats, err := q.Stats(ctx, QueueDefault)
+		require.NoError(t, err)
+		assert.Equal(t, &Stats{Ready: 1}, stats)
+	})
+
+	t.Run("Retry Leaves Undecodable Jobs Dead", func(t *testing.T) {
+		q, _ := setup(t)
+
+		dead, err := json.Marshal(&Job{ID: "1", Queue: QueueDefault, Type: "greet", Attempts: 5, MaxAttempts: 5})
+		require.NoError(t, err)
+		require.NoError(t, q.rdb.LPush(ctx, deadKey(QueueDefault), dead, "not a job").Err())
+
+		n, err := q.RetryDead(ctx, QueueDefault)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		left, err := q.rdb.LRange(ctx, deadKey(QueueDefault), 0, -1).Result()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"not a job"}, left)
+
+		raw, err := q.rdb.LPop(ctx, readyKey(QueueDefault)).Result()
+		require.NoError(t, err)
+		var job Job
+		require.NoError(t, json.Unmarshal([]byte(raw), &job))
+		assert.Equal(t, "1", job.ID)
+		assert.Equal(t, 0, job.Attempts)
+
+		n, err = q.RetryDead(ctx, QueueDefault)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("Unknown Type And Panics Fail", func(t *testing.T) {
+		q, w := setup(t)
+		w.Handle("panic", func(ctx context.Context, job *Job) error {
+			panic("oops")
+		})
+
+		_, err := q.Enqueue(ctx, QueueDefault, "unknown", nil, MaxAttempts(1))
+		require.NoError(t, err)
+		_, err = q.Enqueue(ctx, QueueDefault, "panic", nil, MaxAttempts(1))
+		require.NoError(t, err)
+
+		_, err = w.ProcessAll(ctx)
+		require.NoError(t, err)
+
+		dead, err := q.Dead(ctx, QueueDefault)
+		require.NoError(t, err)
+		require.Len(t, dead, 2)
+		assert.Contains(t, dead[0].LastError, "job panicked: oops")
+		assert.Contains(t, dead[1].LastError, `no handler for job type "unknown"`)
+	})
+
+	t.Run("Scheduled Jobs Wait", func(t *testing.T) {
+		q, w := setup(t)
+		w.Handle("later", func(ctx context.Context, job *Job) error { return nil })
+
+		_, err := q.Enqueue(ctx, QueueDefault, "later", nil, In(time.Hour))
+		require.NoError(t, err)
+		_, err = q.Enqueue(ctx, QueueDefault, "later", nil, At(time.Now().Add(-time.Second)))
+		require.NoError(t, err)
+
+		n, err := w.ProcessAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		stats, err := q.Stats(ctx, QueueDefault)
+		require.NoError(t, err)
+		assert.Equal(t, &Stats{Scheduled: 1}, stats)
+	})
+
+	t.Run("Drains On Shutdown", func(t *testing.T) {
+		q, w := setup(t)
+
+		started := make(chan struct{})
+		var finished atomic.Bool
+		w.Handle("slow", func(ctx context.Context, job *Job) error {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			finished.Store(ctx.Err() == nil)
+			return nil
+		})
+
+		_, err := q.Enqueue(ctx, QueueDefault, "slow", nil)
+		require.NoError(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			w.Run(runCtx)
+			close(done)
+		}()
+
+		<-started
+		cancel()
+		<-done
+
+		assert.True(t, finished.Load())
+		stats, err := q.Stats(ctx, QueueDefault)
+		require.NoError(t, err)
+		assert.Equal(t, &Stats{}, stats)
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Second, time.Minute)
+
+	assert.InDelta(t, 10*time.Second, backoff(1), float64(2*time.Second))
+	assert.InDelta(t, 20*time.Second, backoff(2), float64(4*time.Second))
+	assert.InDelta(t, time.Minute, backoff(10), float64(12*time.Second))
+}
@@ -0,0 +1,231 @@
+// Package jobs is a small job queue on top of Redis.
+//
+// Each named queue uses four keys: a list of jobs ready to run, a sorted set
+// of jobs scheduled for later (including retries), a sorted set of jobs being
+// processed scored by when their lease expires, and a dead-letter list of jobs
+// that ran out of attempts. Jobs whose lease expires, e.g. because the worker
+// crashed, are put back in the queue, so a job runs at least once.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Queue names
+const (
+	QueueDefault  = "default"
+	QueueEmails   = "emails"
+	QueueWebhooks = "webhooks"
+	QueueImports  = "imports"
+	QueueReports  = "reports"
+)
+
+// DefaultMaxAttempts is how many times a job runs before it is dead
+const DefaultMaxAttempts = 5
+
+// Job is a unit of work. Payload is decoded by the handler of its Type.
+type Job struct {
+	ID          string          `json:"id"`
+	Queue       string          `json:"queue"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	EnqueuedAt  time.Time       `json:"enqueued_at"`
+}
+
+// Decode unmarshals the payload into v
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Option changes how a job is enqueued
+type Option func(j *Job, runAt *time.Time)
+
+// In schedules the job to run after d
+func In(d time.Duration) Option {
+	return func(j *Job, runAt *time.Time) {
+		*runAt = time.Now().Add(d)
+	}
+}
+
+// At schedules the job to run at t
+func At(t time.Time) Option {
+	return func(j *Job, runAt *time.Time) {
+		*runAt = t
+	}
+}
+
+// MaxAttempts sets how many times the job runs before it is dead
+func MaxAttempts(n int) Option {
+	return func(j *Job, runAt *time.Time) {
+		j.MaxAttempts = n
+	}
+}
+
+// Queue adds jobs to the queues and inspects them
+type Queue struct {
+	rdb *redis.Client
+}
+
+// NewQueue returns a queue using the given redis client
+func NewQueue(rdb *redis.Client) *Queue {
+	return &Queue{rdb: rdb}
+}
+
+// Enqueue adds a job of the given type to the queue. The payload is encoded
+// as JSON.
+func (q *Queue) Enqueue(ctx context.Context, queue, jobType string, payload any, opts ...Option) (*Job, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s job: %w", jobType, err)
+	}
+
+	job := &Job{
+		ID:          uuid.NewString(),
+		Queue:       queue,
+		Type:        jobType,
+		Payload:     b,
+		MaxAttempts: DefaultMaxAttempts,
+		EnqueuedAt:  time.Now().UTC(),
+	}
+
+	var runAt time.Time
+	for _, opt := range opts {
+		opt(job, &runAt)
+	}
+
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	if runAt.After(time.Now()) {
+		err = q.rdb.ZAdd(ctx, scheduledKey(queue), redis.Z{Score: score(runAt), Member: raw}).Err()
+	} else {
+		err = q.rdb.RPush(ctx, readyKey(queue), raw).Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Stats are the number of jobs of a queue in each state
+type Stats struct {
+	Ready      int64 `json:"ready"`
+	Scheduled  int64 `json:"scheduled"`
+	Processing int64 `json:"processing"`
+	Dead       int64 `json:"dead"`
+}
+
+// Stats counts the jobs of the queue
+func (q *Queue) Stats(ctx context.Context, queue string) (*Stats, error) {
+	var ready, scheduled, processing, dead *redis.IntCmd
+	_, err := q.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		ready = p.LLen(ctx, readyKey(queue))
+		scheduled = p.ZCard(ctx, scheduledKey(queue))
+		processing = p.ZCard(ctx, processingKey(queue))
+		dead = p.LLen(ctx, deadKey(queue))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stats{
+		Ready:      ready.Val(),
+		Scheduled:  scheduled.Val(),
+		Processing: processing.Val(),
+		Dead:       dead.Val(),
+	}, nil
+}
+
+// Dead returns the jobs of the dead-letter list, newest first
+func (q *Queue) Dead(ctx context.Context, queue string) ([]*Job, error) {
+	raws, err := q.rdb.LRange(ctx, deadKey(queue), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(raws))
+	for _, raw := range raws {
+		var job Job
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+// retryScript moves a dead job, ARGV[1], back to the queue as ARGV[2] unless
+// it was retried already
+var retryScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+  return 0
+end
+redis.call('RPUSH', KEYS[2], ARGV[2])
+return 1
+`)
+
+// RetryDead moves the dead jobs back to the queue with their attempts reset,
+// oldest first. Each job is moved at once, so it's never lost between the
+// two lists, and the ones which can't be decoded stay dead.
+func (q *Queue) RetryDead(ctx context.Context, queue string) (int, error) {
+	raws, err := q.rdb.LRange(ctx, deadKey(queue), 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for i := len(raws) - 1; i >= 0; i-- {
+		var job Job
+		if err := json.Unmarshal([]byte(raws[i]), &job); err != nil {
+			continue
+		}
+		job.Attempts = 0
+
+		b, err := json.Marshal(&job)
+		if err != nil {
+			return n, err
+		}
+		moved, err := retryScript.Run(ctx, q.rdb, []string{deadKey(queue), readyKey(queue)}, raws[i], b).Int()
+		if err != nil {
+			return n, err
+		}
+		n += moved
+	}
+
+	return n, nil
+}
+
+func readyKey(queue string) string {
+	return "jobs:" + queue
+}
+
+func scheduledKey(queue string) string {
+	return "jobs:" + queue + ":scheduled"
+}
+
+func processingKey(queue string) string {
+	return "jobs:" + queue + ":processing"
+}
+
+func deadKey(queue string) string {
+	return "jobs:" + queue + ":dead"
+}
+
+// score is the sorted set score of t, in milliseconds
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
@@ -0,0 +1,389 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/opchaves/gin-web-app/app/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// HandlerFunc runs a job. Returning an error retries it later.
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Job statuses reported to the metrics
+const (
+	StatusDone  = "done"
+	StatusRetry = "retry"
+	StatusDead  = "dead"
+)
+
+// fetch takes the next ready job and leases it
+var fetchScript = redis.NewScript(`
+local job = redis.call('LPOP', KEYS[1])
+if job then
+  redis.call('ZADD', KEYS[2], ARGV[1], job)
+end
+return job
+`)
+
+// promote moves the jobs of a sorted set whose score is due to the queue
+var promoteScript = redis.NewScript(`
+local jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, job in ipairs(jobs) do
+  redis.call('ZREM', KEYS[1], job)
+  redis.call('RPUSH', KEYS[2], job)
+end
+return #jobs
+`)
+
+// WorkerConfig configures a worker
+type WorkerConfig struct {
+	Redis  *redis.Client
+	Logger *slog.Logger
+	// Queues are polled in order, so the first ones have priority
+	Queues []string
+	// Concurrency is how many jobs run at once
+	Concurrency int
+	// PollInterval is how long to wait when the queues are empty
+	PollInterval time.Duration
+	// Lease is how long a job may run before it is considered lost and put
+	// back in its queue
+	Lease time.Duration
+	// DrainTimeout is how long to wait for running jobs on shutdown
+	DrainTimeout time.Duration
+	// Backoff returns how long to wait before retrying after the given
+	// number of attempts
+	Backoff func(attempts int) time.Duration
+	Metrics *metrics.Metrics
+}
+
+// Worker runs the jobs of some queues with the handlers of their types
+type Worker struct {
+	rdb          *redis.Client
+	logger       *slog.Logger
+	queues       []string
+	concurrency  int
+	pollInterval time.Duration
+	lease        time.Duration
+	drainTimeout time.Duration
+	backoff      func(attempts int) time.Duration
+	metrics      *metrics.Metrics
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+// NewWorker returns a worker, register the handlers with Handle before
+// running it
+func NewWorker(c *WorkerConfig) *Worker {
+	w := &Worker{
+		rdb:          c.Redis,
+		logger:       c.Logger,
+		queues:       c.Queues,
+		concurrency:  c.Concurrency,
+		pollInterval: c.PollInterval,
+		lease:        c.Lease,
+		drainTimeout: c.DrainTimeout,
+		backoff:      c.Backoff,
+		metrics:      c.Metrics,
+		handlers:     map[string]HandlerFunc{},
+	}
+
+	if len(w.queues) == 0 {
+		w.queues = []string{QueueDefault}
+	}
+	if w.concurrency < 1 {
+		w.concurrency = 1
+	}
+	if w.pollInterval == 0 {
+		w.pollInterval = time.Second
+	}
+	if w.lease == 0 {
+		w.lease = 5 * time.Minute
+	}
+	if w.drainTimeout == 0 {
+		w.drainTimeout = 10 * time.Second
+	}
+	if w.backoff == nil {
+		w.backoff = ExponentialBackoff(10*time.Second, time.Hour)
+	}
+
+	return w
+}
+
+// ExponentialBackoff doubles the wait after each attempt, starting at base
+// and up to max, with some jitter so retries don't all happen at once
+func ExponentialBackoff(base, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+		d := base
+		for i := 1; i < attempts && d < max; i++ {
+			d *= 2
+		}
+		d = min(d, max)
+
+		// +-20%
+		jitter := time.Duration(rand.Int63n(int64(d)/5*2+1)) - d/5
+		return d + jitter
+	}
+}
+
+// Handle registers the handler of a job type
+func (w *Worker) Handle(jobType string, h HandlerFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers[jobType] = h
+}
+
+// Run processes jobs until ctx is cancelled. It then stops taking jobs and
+// waits up to the drain timeout for the running ones before returning.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("job worker started",
+		slog.Any("queues", w.queues),
+		slog.Int("concurrency", w.concurrency),
+	)
+
+	// running jobs must not be cancelled with ctx, only once draining
+	// takes too long
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx, jobCtx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.schedule(ctx)
+	}()
+
+	<-ctx.Done()
+	w.logger.Info("job worker draining")
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(w.drainTimeout):
+		w.logger.Warn("job worker drain timed out, cancelling running jobs")
+		cancelJobs()
+		<-done
+	}
+
+	w.logger.Info("job worker stopped")
+}
+
+// ProcessAll runs the jobs which are due until there are none left, e.g. in
+// tests or scripts. It returns how many jobs ran.
+func (w *Worker) ProcessAll(ctx context.Context) (int, error) {
+	if err := w.promote(ctx); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for {
+		ran, err := w.processNext(ctx)
+		if err != nil || !ran {
+			return n, err
+		}
+		n++
+	}
+}
+
+// loop takes jobs until ctx is cancelled
+func (w *Worker) loop(ctx, jobCtx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := w.processNext(jobCtx)
+		if err != nil {
+			w.logger.ErrorContext(ctx, "failed to fetch job", slog.String("error", err.Error()))
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// schedule promotes the due scheduled jobs and the jobs whose lease expired
+func (w *Worker) schedule(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.promote(ctx); err != nil && ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "failed to promote jobs", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) promote(ctx context.Context) error {
+	now := score(time.Now())
+
+	for _, queue := range w.queues {
+		for _, key := range []string{scheduledKey(queue), processingKey(queue)} {
+			n, err := promoteScript.Run(ctx, w.rdb, []string{key, readyKey(queue)}, now, 100).Int()
+			if err != nil {
+				return err
+			}
+			if n > 0 && key == processingKey(queue) {
+				w.logger.WarnContext(ctx, "requeued jobs whose lease expired",
+					slog.String("queue", queue),
+					slog.Int("count", n),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// processNext runs the next ready job of the queues, if any
+func (w *Worker) processNext(ctx context.Context) (bool, error) {
+	for _, queue := range w.queues {
+		leaseUntil := score(time.Now().Add(w.lease))
+
+		raw, err := fetchScript.Run(ctx, w.rdb, []string{readyKey(queue), processingKey(queue)}, leaseUntil).Text()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		w.process(ctx, queue, raw)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (w *Worker) process(ctx context.Context, queue, raw string) {
+	start := time.Now()
+
+	var job Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		w.logger.ErrorContext(ctx, "dropping invalid job", slog.String("queue", queue), slog.String("error", err.Error()))
+		w.rdb.ZRem(ctx, processingKey(queue), raw)
+		return
+	}
+
+	logger := w.logger.With(
+		slog.String("queue", queue),
+		slog.String("job_id", job.ID),
+		slog.String("job_type", job.Type),
+	)
+
+	err := w.run(ctx, &job)
+	job.Attempts++
+
+	status := StatusDone
+	if err != nil {
+		job.LastError = err.Error()
+		status = StatusRetry
+		if job.Attempts >= job.MaxAttempts {
+			status = StatusDead
+		}
+	}
+
+	if ferr := w.finish(ctx, queue, raw, &job, status); ferr != nil {
+		logger.ErrorContext(ctx, "failed to finish job", slog.String("error", ferr.Error()))
+	}
+
+	duration := time.Since(start)
+	w.metrics.ObserveJob(queue, job.Type, status, duration)
+
+	switch status {
+	case StatusDone:
+		logger.InfoContext(ctx, "job done", slog.Duration("duration", duration))
+	case StatusRetry:
+		logger.WarnContext(ctx, "job failed, will retry",
+			slog.Int("attempts", job.Attempts),
+			slog.String("error", job.LastError),
+		)
+	case StatusDead:
+		logger.ErrorContext(ctx, "job failed, moved to the dead-letter list",
+			slog.Int("attempts", job.Attempts),
+			slog.String("error", job.LastError),
+		)
+	}
+}
+
+// run calls the handler of the job, turning panics into errors
+func (w *Worker) run(ctx context.Context, job *Job) (err error) {
+	w.mu.RLock()
+	h, ok := w.handlers[job.Type]
+	w.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.lease)
+	defer cancel()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+
+	return h(ctx, job)
+}
+
+// finish removes the job from the processing set and, when it failed,
+// schedules its retry or moves it to the dead-letter list
+func (w *Worker) finish(ctx context.Context, queue, raw string, job *Job, status string) error {
+	// the job is done even if ctx is cancelled while draining
+	ctx = context.WithoutCancel(ctx)
+
+	var updated []byte
+	if status != StatusDone {
+		var err error
+		if updated, err = json.Marshal(job); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZRem(ctx, processingKey(queue), raw)
+
+		switch status {
+		case StatusRetry:
+			runAt := time.Now().Add(w.backoff(job.Attempts))
+			p.ZAdd(ctx, scheduledKey(queue), redis.Z{Score: score(runAt), Member: updated})
+		case StatusDead:
+			p.LPush(ctx, deadKey(queue), updated)
+		}
+
+		return nil
+	})
+
+	return err
+}
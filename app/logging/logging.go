@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Log formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type attrsKey struct{}
+
+// New builds a logger writing to w with the given level and format. Records
+// logged with a context get the attributes stored in it by WithAttrs and the
+// trace and span ids.
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// WithAttrs returns a copy of ctx whose log records get attrs added to them
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+
+	all := make([]slog.Attr, 0, len(existing)+len(attrs))
+	all = append(all, existing...)
+	all = append(all, attrs...)
+
+	return context.WithValue(ctx, attrsKey{}, all)
+}
+
+// contextHandler adds the attributes saved in the context to each record,
+// along with the ids of the current trace and span
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "loud", FormatJSON)
+	assert.Error(t, err)
+
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", FormatText)
+	require.NoError(t, err)
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "msg=shown")
+}
+
+func TestWithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", FormatJSON)
+	require.NoError(t, err)
+
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "req-1"))
+	userCtx := WithAttrs(ctx, slog.String("user_id", "user-1"))
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	userCtx = trace.ContextWithSpanContext(userCtx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	// the way services log, with the context of the request
+	logger.With(slog.String("service", "test")).InfoContext(userCtx, "saved", slog.Int("count", 2))
+	logger.WarnContext(ctx, "parent")
+	logger.Info("no context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+	}
+
+	assert.Equal(t, "req-1", records[0]["request_id"])
+	assert.Equal(t, "user-1", records[0]["user_id"])
+	assert.Equal(t, "test", records[0]["service"])
+	assert.Equal(t, float64(2), records[0]["count"])
+	assert.Equal(t, traceID.String(), records[0]["trace_id"])
+	assert.Equal(t, spanID.String(), records[0]["span_id"])
+
+	// adding attributes didn't change the parent context
+	assert.Equal(t, "req-1", records[1]["request_id"])
+	assert.NotContains(t, records[1], "user_id")
+	assert.NotContains(t, records[1], "trace_id")
+
+	assert.NotContains(t, records[2], "request_id")
+}
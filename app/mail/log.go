@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Log is a transport for development. It logs the messages and, when Dir is
+// set, writes them there as .eml files which mail clients can open.
+type Log struct {
+	Logger *slog.Logger
+	Dir    string
+}
+
+// Send implements Transport
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	attrs := []any{
+		slog.Any("to", msg.To),
+		slog.String("subject", msg.Subject),
+	}
+
+	if l.Dir == "" {
+		attrs = append(attrs, slog.String("text", msg.Text))
+		l.Logger.InfoContext(ctx, "email not sent, mailer is log", attrs...)
+		return nil
+	}
+
+	body, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := filepath.Join(l.Dir, fmt.Sprintf("%s.eml", time.Now().Format("20060102-150405.000000000")))
+	if err := os.WriteFile(name, body, 0o644); err != nil {
+		return err
+	}
+
+	attrs = append(attrs, slog.String("file", name))
+	l.Logger.InfoContext(ctx, "email written, mailer is log", attrs...)
+
+	return nil
+}
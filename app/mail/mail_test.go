@@ -0,0 +1,202 @@
+package mail
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBytes(t *testing.T) {
+	msg := Message{
+		From:    "App <no-reply@example.com>",
+		To:      []string{"jane@example.com"},
+		Subject: "Olá",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	}
+	require.NoError(t, msg.Validate())
+
+	b, err := msg.Bytes()
+	require.NoError(t, err)
+
+	m, err := mail.ReadMessage(strings.NewReader(string(b)))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Olá", subject)
+	assert.Contains(t, m.Header.Get("Message-ID"), "@example.com>")
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	var parts []string
+	r := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(p)
+		require.NoError(t, err)
+		parts = append(parts, p.Header.Get("Content-Type")+": "+string(body))
+	}
+
+	assert.Equal(t, []string{
+		"text/plain; charset=utf-8: plain body",
+		"text/html; charset=utf-8: <p>html body</p>",
+	}, parts)
+}
+
+func TestMessageBytesSinglePart(t *testing.T) {
+	for contentType, msg := range map[string]Message{
+		"text/plain": {From: "a@example.com", To: []string{"b@example.com"}, Text: "plain body = ok"},
+		"text/html":  {From: "a@example.com", To: []string{"b@example.com"}, HTML: "<p>html body</p>"},
+	} {
+		b, err := msg.Bytes()
+		require.NoError(t, err)
+
+		m, err := mail.ReadMessage(strings.NewReader(string(b)))
+		require.NoError(t, err)
+
+		mediaType, _, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, contentType, mediaType)
+
+		body, err := io.ReadAll(quotedprintable.NewReader(m.Body))
+		require.NoError(t, err)
+		assert.Equal(t, msg.Text+msg.HTML, string(body), contentType)
+	}
+}
+
+func TestMessageValidate(t *testing.T) {
+	valid := Message{From: "a@example.com", To: []string{"b@example.com"}, Text: "hi"}
+	assert.NoError(t, valid.Validate())
+
+	for name, msg := range map[string]Message{
+		"No Sender":     {To: []string{"b@example.com"}, Text: "hi"},
+		"No Recipients": {From: "a@example.com", Text: "hi"},
+		"Bad Recipient": {From: "a@example.com", To: []string{"nope"}, Text: "hi"},
+		"No Body":       {From: "a@example.com", To: []string{"b@example.com"}},
+	} {
+		assert.Error(t, msg.Validate(), name)
+	}
+}
+
+func TestTemplatesRender(t *testing.T) {
+	templates, err := LoadTemplates()
+	require.NoError(t, err)
+
+	data := map[string]string{
+		"Email": "jane@example.com",
+		"Link":  "https://app.example.com/reset-password/abc?x=<y>",
+	}
+
+	var msg Message
+	err = templates.Render(&msg, "reset_password", i18n.Embedded().Localizer("en"), data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Reset your password", msg.Subject)
+	assert.Contains(t, msg.Text, "the password of jane@example.com.")
+	assert.Contains(t, msg.Text, "https://app.example.com/reset-password/abc?x=<y>")
+	assert.Contains(t, msg.HTML, `href="https://app.example.com/reset-password/abc?x=%3cy%3e"`)
+	assert.Contains(t, msg.HTML, "<title>Reset your password</title>")
+	assert.Contains(t, msg.HTML, `<html lang="en">`)
+
+	var pt Message
+	err = templates.Render(&pt, "reset_password", i18n.Embedded().Localizer("pt"), data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Redefina sua senha", pt.Subject)
+	assert.Contains(t, pt.Text, "a senha de jane@example.com.")
+	assert.Contains(t, pt.HTML, `<html lang="pt">`)
+	// rendering in Portuguese didn't change the templates
+	require.NoError(t, templates.Render(&msg, "reset_password", i18n.Embedded().Localizer("en"), data))
+	assert.Equal(t, "Reset your password", msg.Subject)
+
+	assert.Error(t, templates.Render(&msg, "missing", i18n.Embedded().Localizer("en"), nil))
+}
+
+func TestSMTPSend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go fakeSMTPServer(ln, received)
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	s, err := NewSMTP(&SMTPConfig{Host: host, Port: port, Username: "user", Password: "secret", Encryption: "null"})
+	require.NoError(t, err)
+
+	err = s.Send(context.Background(), Message{
+		From: "App <no-reply@example.com>",
+		To:   []string{"Jane <jane@example.com>"},
+		Text: "hello",
+	})
+	require.NoError(t, err)
+
+	commands := <-received
+	assert.Contains(t, commands, "AUTH PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret")))
+	assert.Contains(t, commands, "MAIL FROM:<no-reply@example.com>")
+	assert.Contains(t, commands, "RCPT TO:<jane@example.com>")
+
+	_, err = NewSMTP(&SMTPConfig{Encryption: "rot13"})
+	assert.Error(t, err)
+}
+
+// fakeSMTPServer accepts one message and sends the commands it got
+func fakeSMTPServer(ln net.Listener, received chan<- []string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ready")
+
+	var commands []string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			break
+		}
+		commands = append(commands, line)
+
+		switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+		case "EHLO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			tp.PrintfLine("235 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			tp.ReadDotLines()
+			tp.PrintfLine("250 ok")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			received <- commands
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+
+	received <- commands
+}
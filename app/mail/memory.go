@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory keeps the messages instead of sending them, for tests
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Send implements Transport
+func (m *Memory) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
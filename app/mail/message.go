@@ -0,0 +1,149 @@
+// Package mail builds MIME messages and sends them through pluggable
+// transports
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message is an email with a plain-text body, an HTML body or both
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Transport sends messages
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Validate checks the message has the fields needed to send it
+func (m Message) Validate() error {
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %w", m.From, err)
+	}
+	if len(m.To) == 0 {
+		return errors.New("message has no recipients")
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid to address %q: %w", to, err)
+		}
+	}
+	if m.Text == "" && m.HTML == "" {
+		return errors.New("message has no body")
+	}
+
+	return nil
+}
+
+// Recipients returns the bare addresses of To
+func (m Message) Recipients() []string {
+	rcpts := make([]string, 0, len(m.To))
+	for _, to := range m.To {
+		if addr, err := mail.ParseAddress(to); err == nil {
+			rcpts = append(rcpts, addr.Address)
+		}
+	}
+
+	return rcpts
+}
+
+// Bytes encodes the message as MIME, multipart/alternative when it has both
+// bodies
+func (m Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := func(k, v string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, v)
+	}
+
+	header("From", m.From)
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(m.From))
+	header("MIME-Version", "1.0")
+
+	if m.Text == "" || m.HTML == "" {
+		contentType, body := "text/plain", m.Text
+		if m.HTML != "" {
+			contentType, body = "text/html", m.HTML
+		}
+
+		header("Content-Type", contentType+"; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	buf.WriteString("\r\n")
+
+	// the preferred part goes last
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", m.Text},
+		{"text/html", m.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = d
+		}
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
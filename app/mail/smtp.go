@@ -0,0 +1,158 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Encryption of the SMTP connection
+const (
+	EncryptionNone     = "none"
+	EncryptionSTARTTLS = "starttls"
+	EncryptionTLS      = "tls"
+)
+
+// SMTPConfig configures the SMTP transport
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// Encryption is one of none, starttls or tls (implicit TLS, also ssl)
+	Encryption string
+	Timeout    time.Duration
+}
+
+// SMTP sends messages to an SMTP server, authenticating with PLAIN when a
+// username is set
+type SMTP struct {
+	addr       string
+	host       string
+	auth       smtp.Auth
+	encryption string
+	timeout    time.Duration
+}
+
+// NewSMTP returns an SMTP transport
+func NewSMTP(c *SMTPConfig) (*SMTP, error) {
+	encryption, err := parseEncryption(c.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SMTP{
+		addr:       net.JoinHostPort(c.Host, c.Port),
+		host:       c.Host,
+		encryption: encryption,
+		timeout:    c.Timeout,
+	}
+	if s.timeout == 0 {
+		s.timeout = 10 * time.Second
+	}
+	if c.Username != "" {
+		s.auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+
+	return s, nil
+}
+
+// parseEncryption accepts the values used by other frameworks as well
+func parseEncryption(e string) (string, error) {
+	switch strings.ToLower(e) {
+	case "", "null", EncryptionNone:
+		return EncryptionNone, nil
+	case EncryptionSTARTTLS:
+		return EncryptionSTARTTLS, nil
+	case EncryptionTLS, "ssl":
+		return EncryptionTLS, nil
+	default:
+		return "", fmt.Errorf("invalid mail encryption %q", e)
+	}
+}
+
+// Send implements Transport
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	body, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.timeout)
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	if s.encryption == EncryptionTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig()}).DialContext(ctx, "tcp", s.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if s.encryption == EncryptionSTARTTLS {
+		if err := c.StartTLS(s.tlsConfig()); err != nil {
+			return err
+		}
+	}
+
+	if s.auth != nil {
+		if err := c.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(sender(msg.From)); err != nil {
+		return err
+	}
+	for _, to := range msg.Recipients() {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (s *SMTP) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12}
+}
+
+func sender(from string) string {
+	rcpts := Message{To: []string{from}}.Recipients()
+	if len(rcpts) == 0 {
+		return from
+	}
+
+	return rcpts[0]
+}
@@ -0,0 +1,113 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/opchaves/gin-web-app/app/i18n"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Templates renders messages from the embedded templates. An email called
+// name has a name.txt template for the plain-text body and a name.html one,
+// rendered inside layout.html, for the HTML body. Both define its subject.
+// Templates translate their texts with the T function, see i18n.Localizer.
+type Templates struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+}
+
+// LoadTemplates parses the embedded templates
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{
+		html: map[string]*htmltemplate.Template{},
+		text: map[string]*texttemplate.Template{},
+	}
+
+	files, err := fs.Glob(templateFS, "templates/*.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".txt")
+
+		text, err := texttemplate.New(path.Base(file)).Funcs(texttemplate.FuncMap(placeholderFuncs)).ParseFS(templateFS, file)
+		if err != nil {
+			return nil, err
+		}
+		t.text[name] = text
+
+		html, err := htmltemplate.New("layout.html").Funcs(htmltemplate.FuncMap(placeholderFuncs)).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+		t.html[name] = html
+	}
+
+	return t, nil
+}
+
+// placeholderFuncs stand for the functions of the language of a message
+// until it is rendered
+var placeholderFuncs = map[string]any{
+	"T":    func(key string, args ...any) string { return key },
+	"Lang": func() string { return "" },
+}
+
+// localizedFuncs are the functions translating a message with l
+func localizedFuncs(l *i18n.Localizer) map[string]any {
+	return map[string]any{
+		"T":    l.T,
+		"Lang": func() string { return l.Lang },
+	}
+}
+
+// Render sets the subject and bodies of msg from the templates of the email
+// called name, translated by l
+func (t *Templates) Render(msg *Message, name string, l *i18n.Localizer, data any) error {
+	text, ok := t.text[name]
+	if !ok {
+		return fmt.Errorf("no email template %q", name)
+	}
+
+	// the parsed templates are never executed, so they can be cloned to bind
+	// T to the language of the message
+	text, err := text.Clone()
+	if err != nil {
+		return err
+	}
+	text.Funcs(localizedFuncs(l))
+
+	html, err := t.html[name].Clone()
+	if err != nil {
+		return err
+	}
+	html.Funcs(localizedFuncs(l))
+
+	var subject, body, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return err
+	}
+	if err := text.ExecuteTemplate(&body, name+".txt", data); err != nil {
+		return err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return err
+	}
+
+	msg.Subject = strings.TrimSpace(subject.String())
+	msg.Text = body.String()
+	msg.HTML = htmlBody.String()
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+// Metrics holds the application collectors. Its methods are safe to call on a
+// nil *Metrics so services can be built without them, e.g. in tests.
+type Metrics struct {
+	registry       *prometheus.Registry
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	rateLimited    prometheus.Counter
+	mailSendFailed prometheus.Counter
+	jobsProcessed  *prometheus.CounterVec
+	jobDuration    *prometheus.HistogramVec
+	outboxEvents   *prometheus.CounterVec
+	outboxPending  prometheus.Gauge
+	outboxLag      prometheus.Gauge
+}
+
+// New creates the application metrics, including the database and redis
+// pool stats
+func New(db *pgxpool.Pool, rdb *redis.Client) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		rateLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "http_rate_limited_total",
+			Help: "Number of requests rejected by the rate limiter.",
+		}),
+		mailSendFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mail_send_failures_total",
+			Help: "Number of emails that failed to be sent.",
+		}),
+		jobsProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobs_processed_total",
+			Help: "Number of background jobs processed by queue, type and status.",
+		}, []string{"queue", "type", "status"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "job_duration_seconds",
+			Help:    "Background job run time by queue and type.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"queue", "type"}),
+		outboxEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbox_events_relayed_total",
+			Help: "Number of outbox events relayed by topic and status.",
+		}, []string{"topic", "status"}),
+		outboxPending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_pending_events",
+			Help: "Number of outbox events not published yet.",
+		}),
+		outboxLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_lag_seconds",
+			Help: "Age of the oldest outbox event not published yet.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.rateLimited,
+		m.mailSendFailed,
+		m.jobsProcessed,
+		m.jobDuration,
+		m.outboxEvents,
+		m.outboxPending,
+		m.outboxLag,
+	)
+
+	if db != nil {
+		m.registry.MustRegister(dbCollectors(db)...)
+	}
+
+	if rdb != nil {
+		m.registry.MustRegister(redisCollectors(rdb)...)
+	}
+
+	return m
+}
+
+// Register adds more collectors to the registry
+func (m *Metrics) Register(cs ...prometheus.Collector) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(cs...)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a finished HTTP request
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// RateLimited records a request rejected by the rate limiter
+func (m *Metrics) RateLimited() {
+	if m == nil {
+		return
+	}
+	m.rateLimited.Inc()
+}
+
+// MailSendFailed records an email that could not be sent
+func (m *Metrics) MailSendFailed() {
+	if m == nil {
+		return
+	}
+	m.mailSendFailed.Inc()
+}
+
+// ObserveJob records a processed background job. Status is done, retry or
+// dead.
+func (m *Metrics) ObserveJob(queue, jobType, status string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.jobsProcessed.WithLabelValues(queue, jobType, status).Inc()
+	m.jobDuration.WithLabelValues(queue, jobType).Observe(duration.Seconds())
+}
+
+// ObserveOutboxEvent records a relayed outbox event. Status is published or
+// failed.
+func (m *Metrics) ObserveOutboxEvent(topic, status string) {
+	if m == nil {
+		return
+	}
+	m.outboxEvents.WithLabelValues(topic, status).Inc()
+}
+
+// SetOutboxLag records how many outbox events are pending and how old the
+// oldest one is
+func (m *Metrics) SetOutboxLag(pending int64, lag time.Duration) {
+	if m == nil {
+		return
+	}
+	m.outboxPending.Set(float64(pending))
+	m.outboxLag.Set(lag.Seconds())
+}
+
+func dbCollectors(db *pgxpool.Pool) []prometheus.Collector {
+	gauge := func(name, help string, value func(s *pgxpool.Stat) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, func() float64 {
+			return value(db.Stat())
+		})
+	}
+	counter := func(name, help string, value func(s *pgxpool.Stat) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, func() float64 {
+			return value(db.Stat())
+		})
+	}
+
+	return []prometheus.Collector{
+		gauge("db_pool_acquired_connections", "Connections currently in use.",
+			func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }),
+		gauge("db_pool_idle_connections", "Idle connections in the pool.",
+			func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }),
+		gauge("db_pool_total_connections", "Total connections in the pool.",
+			func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }),
+		gauge("db_pool_max_connections", "Maximum size of the pool.",
+			func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }),
+		counter("db_pool_acquires_total", "Number of connections acquired from the pool.",
+			func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }),
+		counter("db_pool_empty_acquires_total", "Number of acquires that had to wait for a connection.",
+			func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }),
+		counter("db_pool_acquire_wait_seconds_total", "Time spent waiting to acquire connections.",
+			func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }),
+	}
+}
+
+func redisCollectors(rdb *redis.Client) []prometheus.Collector {
+	gauge := func(name, help string, value func(s *redis.PoolStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, func() float64 {
+			return value(rdb.PoolStats())
+		})
+	}
+	counter := func(name, help string, value func(s *redis.PoolStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, func() float64 {
+			return value(rdb.PoolStats())
+		})
+	}
+
+	return []prometheus.Collector{
+		gauge("redis_pool_total_connections", "Total connections in the pool.",
+			func(s *redis.PoolStats) float64 { return float64(s.TotalConns) }),
+		gauge("redis_pool_idle_connections", "Idle connections in the pool.",
+			func(s *redis.PoolStats) float64 { return float64(s.IdleConns) }),
+		counter("redis_pool_hits_total", "Number of times a free connection was found in the pool.",
+			func(s *redis.PoolStats) float64 { return float64(s.Hits) }),
+		counter("redis_pool_misses_total", "Number of times a free connection was not found in the pool.",
+			func(s *redis.PoolStats) float64 { return float64(s.Misses) }),
+		counter("redis_pool_timeouts_total", "Number of times a wait for a connection timed out.",
+			func(s *redis.PoolStats) float64 { return float64(s.Timeouts) }),
+		counter("redis_pool_stale_connections_total", "Number of stale connections removed from the pool.",
+			func(s *redis.PoolStats) float64 { return float64(s.StaleConns) }),
+	}
+}
@@ -42,14 +42,83 @@ const (
 	UnableAcceptError   = "Unable to accept the request. Try again later"
 )
 
+// Attachment Errors
+const (
+	InvalidAttachmentType = "file must be 'image/jpeg', 'image/png' or 'application/pdf'"
+)
+
+// Transaction Errors
+const (
+	EmptySearchQuery = "The search query has no words"
+	InvalidMinValue  = "Invalid min_value"
+	InvalidMaxValue  = "Invalid max_value"
+	SplitValue       = "The lines must add up to the value of the transaction"
+	InvalidCents     = "must have at most 2 decimal places"
+	ValueOutOfRange  = "must be between -99999999.99 and 99999999.99"
+	SplitLineSign    = "must not be zero and must have the sign of the transaction"
+	UnknownCategory  = "There is no such category in the workspace"
+	UnknownAccount   = "There is no such account in the workspace"
+	UnknownPayee     = "There is no such payee in the workspace"
+	CategoryRequired = "Set a category, add a rule that sets one or give the payee a default category"
+)
+
+// Rule Errors
+const (
+	InvalidPattern       = "must be a valid regular expression"
+	RuleWithoutCondition = "A rule needs at least one condition"
+	RuleWithoutAction    = "A rule needs at least one action"
+)
+
 // Generic Errors
 const (
 	InvalidId      = "Id given is not valid"
 	InvalidSession = "Provided session is invalid"
+	InvalidCSRF    = "Missing or invalid CSRF token"
+	InvalidLink    = "Invalid or expired link"
+	NoRoute        = "No route found. Go to /docs for a list of all routes"
 	ServerError    = "Something went wrong. Try again later"
 	Unauthorized   = "Not Authorized"
 )
 
+// MessageKeys are the keys of the messages in the i18n catalogues, under
+// errors. Messages without one are shown as is.
+var MessageKeys = map[string]string{
+	InvalidImageType:      "invalid_image_type",
+	InvalidCredentials:    "invalid_credentials",
+	DuplicateEmail:        "duplicate_email",
+	InvalidResetToken:     "invalid_reset_token",
+	InvalidAttachmentType: "invalid_attachment_type",
+	EmptySearchQuery:      "empty_search_query",
+	InvalidMinValue:       "invalid_min_value",
+	InvalidMaxValue:       "invalid_max_value",
+	SplitValue:            "split_value",
+	InvalidCents:          "invalid_cents",
+	ValueOutOfRange:       "value_out_of_range",
+	SplitLineSign:         "split_line_sign",
+	UnknownCategory:       "unknown_category",
+	UnknownAccount:        "unknown_account",
+	UnknownPayee:          "unknown_payee",
+	CategoryRequired:      "category_required",
+	InvalidPattern:        "invalid_pattern",
+	RuleWithoutCondition:  "rule_without_condition",
+	RuleWithoutAction:     "rule_without_action",
+	InvalidId:             "invalid_id",
+	InvalidSession:        "invalid_session",
+	InvalidCSRF:           "invalid_csrf",
+	InvalidLink:           "invalid_link",
+	NoRoute:               "no_route",
+	ServerError:           "server_error",
+	Unauthorized:          "unauthorized",
+}
+
+// MessageKey returns the i18n key of message, or "" when it has none
+func MessageKey(message string) string {
+	if key, ok := MessageKeys[message]; ok {
+		return "errors." + key
+	}
+	return ""
+}
+
 // Message Errors
 const (
 	MessageOrFileRequired = "Either a message or a file is required"
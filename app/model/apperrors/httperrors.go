@@ -14,39 +14,42 @@ const (
 	Authorization        Type = "AUTHORIZATION"        // Authentication Failures -
 	BadRequest           Type = "BADREQUEST"           // Validation errors / BadInput
 	Conflict             Type = "CONFLICT"             // Already exists (eg, create account with existent email) - 409
+	Forbidden            Type = "FORBIDDEN"            // Authenticated but not allowed - 403
 	Internal             Type = "INTERNAL"             // Server (500) and fallback errors
 	NotFound             Type = "NOTFOUND"             // For not finding resource
 	PayloadTooLarge      Type = "PAYLOADTOOLARGE"      // for uploading tons of JSON, or an image over the limit - 413
 	ServiceUnavailable   Type = "SERVICE_UNAVAILABLE"  // For long running handlers
+	TooManyRequests      Type = "TOO_MANY_REQUESTS"    // Rate limit exceeded - 429
 	UnsupportedMediaType Type = "UNSUPPORTEDMEDIATYPE" // for http 415
+	Validation           Type = "VALIDATION"           // Invalid fields, listed in the error - 400
 )
 
-// Error holds a custom error for the application
-// which is helpful in returning a consistent
-// error type/message from API endpoints
-type Error struct {
-	Type    Type   `json:"type"`
-	Message string `json:"message"`
-}
-
-// Error satisfies standard error interface
-// we can return errors from this package as
-// a regular old go _error_
-func (e *Error) Error() string {
-	return e.Message
+// Types lists every error type, in the order the docs show them
+var Types = []Type{
+	Authorization,
+	BadRequest,
+	Conflict,
+	Forbidden,
+	Internal,
+	NotFound,
+	PayloadTooLarge,
+	ServiceUnavailable,
+	TooManyRequests,
+	UnsupportedMediaType,
+	Validation,
 }
 
-// Status is a mapping errors to status codes
-// Of course, this is somewhat redundant since
-// our errors already map http status codes
-func (e *Error) Status() int {
-	switch e.Type {
+// Status returns the http status code of the errors of type t
+func (t Type) Status() int {
+	switch t {
 	case Authorization:
 		return http.StatusUnauthorized
-	case BadRequest:
+	case BadRequest, Validation:
 		return http.StatusBadRequest
 	case Conflict:
 		return http.StatusConflict
+	case Forbidden:
+		return http.StatusForbidden
 	case Internal:
 		return http.StatusInternalServerError
 	case NotFound:
@@ -55,6 +58,8 @@ func (e *Error) Status() int {
 		return http.StatusRequestEntityTooLarge
 	case ServiceUnavailable:
 		return http.StatusServiceUnavailable
+	case TooManyRequests:
+		return http.StatusTooManyRequests
 	case UnsupportedMediaType:
 		return http.StatusUnsupportedMediaType
 	default:
@@ -62,6 +67,68 @@ func (e *Error) Status() int {
 	}
 }
 
+// Description tells clients what the errors of type t mean
+func (t Type) Description() string {
+	switch t {
+	case Authorization:
+		return "Not logged in, or wrong credentials"
+	case BadRequest:
+		return "The request can't be done as asked"
+	case Conflict:
+		return "The resource already exists"
+	case Forbidden:
+		return "Logged in but not allowed, e.g. a missing CSRF token"
+	case Internal:
+		return "Something went wrong on our side"
+	case NotFound:
+		return "The resource or route doesn't exist, or isn't yours"
+	case PayloadTooLarge:
+		return "The body is over the size limit"
+	case ServiceUnavailable:
+		return "The request took too long"
+	case TooManyRequests:
+		return "Rate limit exceeded, see the X-RateLimit-Reset header"
+	case UnsupportedMediaType:
+		return "The file is not of an accepted type"
+	case Validation:
+		return "Some fields are invalid, see fields"
+	default:
+		return ""
+	}
+}
+
+// FieldError is a validation error of a single field
+type FieldError struct {
+	// The property containing the error
+	Field string `json:"field"`
+	// The specific error message
+	Message string `json:"message"`
+} //@name FieldError
+
+// Error holds a custom error for the application
+// which is helpful in returning a consistent
+// error type/message from API endpoints
+type Error struct {
+	Type    Type         `json:"type"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	// Key and Params translate Message, see the i18n package
+	Key    string            `json:"-"`
+	Params map[string]string `json:"-"`
+}
+
+// Error satisfies standard error interface
+// we can return errors from this package as
+// a regular old go _error_
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Status returns the http status code of the error
+func (e *Error) Status() int {
+	return e.Type.Status()
+}
+
 // Status checks the runtime type
 // of the error and returns an http
 // status code if the error is model.Error
@@ -82,6 +149,7 @@ func NewAuthorization(reason string) *Error {
 	return &Error{
 		Type:    Authorization,
 		Message: reason,
+		Key:     MessageKey(reason),
 	}
 }
 
@@ -90,14 +158,40 @@ func NewBadRequest(reason string) *Error {
 	return &Error{
 		Type:    BadRequest,
 		Message: fmt.Sprintf("Bad request. Reason: %v", reason),
+		Key:     "errors.bad_request",
+		Params:  map[string]string{"reason": reason},
 	}
 }
 
+// As returns err as an *Error when it is one
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// IsType reports whether err is an *Error of type t
+func IsType(err error, t Type) bool {
+	e, ok := As(err)
+	return ok && e.Type == t
+}
+
 // NewConflict to create an error for 409
 func NewConflict(name string, value string) *Error {
 	return &Error{
 		Type:    Conflict,
 		Message: fmt.Sprintf("resource: %v with value: %v already exists", name, value),
+		Key:     "errors.conflict",
+		Params:  map[string]string{"name": name, "value": value},
+	}
+}
+
+// NewForbidden to create a 403
+func NewForbidden(reason string) *Error {
+	return &Error{
+		Type:    Forbidden,
+		Message: reason,
+		Key:     MessageKey(reason),
 	}
 }
 
@@ -106,6 +200,7 @@ func NewInternal() *Error {
 	return &Error{
 		Type:    Internal,
 		Message: ServerError,
+		Key:     MessageKey(ServerError),
 	}
 }
 
@@ -114,6 +209,8 @@ func NewNotFound(name string, value string) *Error {
 	return &Error{
 		Type:    NotFound,
 		Message: fmt.Sprintf("resource: %v with value: %v not found", name, value),
+		Key:     "errors.not_found",
+		Params:  map[string]string{"name": name, "value": value},
 	}
 }
 
@@ -122,6 +219,11 @@ func NewPayloadTooLarge(maxBodySize int64, contentLength int64) *Error {
 	return &Error{
 		Type:    PayloadTooLarge,
 		Message: fmt.Sprintf("Max payload size of %v exceeded. Actual payload size: %v", maxBodySize, contentLength),
+		Key:     "errors.payload_too_large",
+		Params: map[string]string{
+			"max":    fmt.Sprint(maxBodySize),
+			"actual": fmt.Sprint(contentLength),
+		},
 	}
 }
 
@@ -130,6 +232,26 @@ func NewServiceUnavailable() *Error {
 	return &Error{
 		Type:    ServiceUnavailable,
 		Message: "Service unavailable or timed out",
+		Key:     "errors.service_unavailable",
+	}
+}
+
+// NewTooManyRequests to create an error for 429
+func NewTooManyRequests() *Error {
+	return &Error{
+		Type:    TooManyRequests,
+		Message: "Too many requests. Try again later",
+		Key:     "errors.too_many_requests",
+	}
+}
+
+// NewValidation to create a 400 listing the invalid fields
+func NewValidation(fields []FieldError) *Error {
+	return &Error{
+		Type:    Validation,
+		Message: "Some fields are invalid",
+		Fields:  fields,
+		Key:     "errors.validation",
 	}
 }
 
@@ -138,5 +260,6 @@ func NewUnsupportedMediaType(reason string) *Error {
 	return &Error{
 		Type:    UnsupportedMediaType,
 		Message: reason,
+		Key:     MessageKey(reason),
 	}
 }
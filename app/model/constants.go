@@ -3,4 +3,23 @@ package model
 // Application Constants
 const (
 	CookieName = "kommonei"
+
+	// RequestIDKey is the context key holding the request id
+	RequestIDKey = "requestId"
+	// RequestIDHeader carries the request id in requests and responses
+	RequestIDHeader = "X-Request-ID"
+
+	// WorkspaceKey is the context key holding the *Workspace of the request
+	WorkspaceKey = "workspace"
+
+	// CSRFKey is the session and context key holding the CSRF token
+	CSRFKey = "csrfToken"
+	// CSRFHeader carries the token on HTMX and API requests
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFFormField carries the token on regular form posts
+	CSRFFormField = "csrf_token"
+
+	// LanguageKey is the session key holding the language of the user's
+	// profile, and the context key holding the language of the request
+	LanguageKey = "language"
 )
@@ -1,27 +1,25 @@
 package model
 
-// ErrorsResponse contains a list of FieldError
-type ErrorsResponse struct {
-	Errors []FieldError `json:"errors"`
-} //@name ErrorsResponse
+import "github.com/opchaves/gin-web-app/app/model/apperrors"
 
 // FieldError is used to help extract validation errors
-type FieldError struct {
-	// The property containing the error
-	Field string `json:"field"`
-	// The specific error message
-	Message string `json:"message"`
-} //@name FieldError
+type FieldError = apperrors.FieldError
 
-// ErrorResponse holds a custom error for the application
+// ErrorResponse is the body of every error response
 type ErrorResponse struct {
 	Error HttpError `json:"error"`
 } //@name ErrorResponse
 
-// HttpError returns the Http error type and the specific message
+// HttpError tells clients what went wrong
 type HttpError struct {
-	// The Http Response as a string
-	Type string `json:"type"`
+	// Stable code clients can rely on, one of the apperrors types
+	Code apperrors.Type `json:"code"`
 	// The specific error message
 	Message string `json:"message"`
+	// The invalid fields of validation errors
+	Fields []FieldError `json:"fields,omitempty"`
+	// The id of the request, sent in the X-Request-ID header too
+	RequestID string `json:"request_id,omitempty"`
+	// Link to the docs of the code
+	Docs string `json:"docs"`
 } //@name HttpError
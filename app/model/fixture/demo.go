@@ -0,0 +1,384 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/utils"
+)
+
+// Category types. Values of income transactions are positive, expenses are
+// negative and a transfer is a pair of transactions cancelling each other.
+const (
+	CategoryIncome   = "income"
+	CategoryExpense  = "expense"
+	CategoryTransfer = "transfer"
+)
+
+// DemoOptions configures NewDemo
+type DemoOptions struct {
+	// Seed makes the generated data reproducible
+	Seed int64
+	// Scale multiplies the everyday spending and the income paying for it.
+	// 1 is a single household, use bigger values for load testing.
+	Scale int
+	// Months of history to generate
+	Months int
+	// End is the last day of the history, today when zero. Set it as well
+	// for the same seed to always give the same data.
+	End time.Time
+}
+
+// Demo is a workspace with accounts, a category tree and months of
+// transactions, ready to be inserted
+type Demo struct {
+	// User has DefaultPassword as password
+	User         *model.User
+	Workspace    *model.Workspace
+	Accounts     []model.CreateAccountsParams
+	Categories   []model.CreateCategoriesParams
+	Transactions []model.CreateTransactionsParams
+}
+
+// DemoSet returns a set inserting a demo workspace built with opts
+func DemoSet(opts DemoOptions) Set {
+	return func(ctx context.Context, q *model.Queries) error {
+		return NewDemo(opts).Insert(ctx, q)
+	}
+}
+
+// Insert adds the demo data to the database. The user and workspace IDs come
+// from the seed, so the same seed can't be inserted twice.
+func (d *Demo) Insert(ctx context.Context, q *model.Queries) error {
+	hashedPassword, err := utils.HashPassword(d.User.Password)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateUserWithId(ctx, model.CreateUserWithIdParams{
+		ID:        d.User.ID,
+		FirstName: d.User.FirstName,
+		LastName:  d.User.LastName,
+		Email:     d.User.Email,
+		Password:  hashedPassword,
+		Active:    d.User.Active,
+		Role:      d.User.Role,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create user %s: %w", d.User.Email, err)
+	}
+
+	_, err = q.CreateWorkspaceWithId(ctx, model.CreateWorkspaceWithIdParams{
+		ID:          d.Workspace.ID,
+		Name:        d.Workspace.Name,
+		Description: d.Workspace.Description,
+		Currency:    d.Workspace.Currency,
+		Language:    d.Workspace.Language,
+		UserID:      d.Workspace.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	if _, err := q.CreateAccounts(ctx, d.Accounts); err != nil {
+		return fmt.Errorf("failed to create accounts: %w", err)
+	}
+	if _, err := q.CreateCategories(ctx, d.Categories); err != nil {
+		return fmt.Errorf("failed to create categories: %w", err)
+	}
+	if _, err := q.CreateTransactions(ctx, d.Transactions); err != nil {
+		return fmt.Errorf("failed to create transactions: %w", err)
+	}
+
+	return nil
+}
+
+// NewDemo generates a demo workspace. Salary, bills, transfers and savings
+// interest happen on fixed days of the month while groceries, restaurants
+// and other everyday spending happen at random.
+func NewDemo(opts DemoOptions) *Demo {
+	if opts.Scale < 1 {
+		opts.Scale = 1
+	}
+	if opts.Months < 1 {
+		opts.Months = 6
+	}
+	if opts.End.IsZero() {
+		opts.End = time.Now()
+	}
+
+	g := &demoGenerator{
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		opts:     opts,
+		accounts: map[string]int{},
+		balances: map[string]int64{},
+		cats:     map[string]uuid.UUID{},
+	}
+
+	return g.generate()
+}
+
+type demoAccount struct {
+	key         string
+	name        string
+	accountType string
+	institution string
+	// opening balance in cents
+	opening int64
+}
+
+var demoAccounts = []demoAccount{
+	{"checking", "Checking", "checking", "First National Bank", 250000},
+	{"savings", "Savings", "savings", "First National Bank", 800000},
+	{"card", "Credit Card", "credit_card", "Visa", 0},
+	{"cash", "Wallet", "cash", "", 8000},
+}
+
+type demoCategory struct {
+	name     string
+	cType    string
+	children []string
+}
+
+var demoCategories = []demoCategory{
+	{"Income", CategoryIncome, []string{"Salary", "Freelance", "Interest"}},
+	{"Housing", CategoryExpense, []string{"Rent", "Utilities", "Internet"}},
+	{"Food", CategoryExpense, []string{"Groceries", "Restaurants", "Coffee"}},
+	{"Transport", CategoryExpense, []string{"Fuel", "Public Transport"}},
+	{"Bills", CategoryExpense, []string{"Phone", "Streaming", "Gym"}},
+	{"Shopping", CategoryExpense, []string{"Clothes", "Electronics"}},
+	{"Health", CategoryExpense, []string{"Pharmacy"}},
+	{"Transfers", CategoryTransfer, nil},
+}
+
+// demoSpending is everyday spending happening perWeek times on average
+type demoSpending struct {
+	category string
+	perWeek  float64
+	// min and max in cents
+	min, max  int64
+	accounts  []string
+	merchants []string
+}
+
+var demoSpendings = []demoSpending{
+	{"Groceries", 2, 2500, 18000, []string{"checking", "card"}, []string{"Whole Foods", "Trader Joe's", "Safeway", "Costco"}},
+	{"Restaurants", 1.5, 1500, 8500, []string{"card"}, []string{"Chipotle", "Olive Garden", "Sushi Place", "Pizza Hut", "Thai Kitchen"}},
+	{"Coffee", 4, 350, 700, []string{"card", "cash"}, []string{"Starbucks", "Blue Bottle", "Corner Cafe"}},
+	{"Fuel", 0.8, 3500, 7500, []string{"card"}, []string{"Shell", "Chevron", "BP"}},
+	{"Public Transport", 1, 275, 3000, []string{"card", "cash"}, []string{"Metro", "City Bus", "Uber"}},
+	{"Clothes", 0.3, 2000, 15000, []string{"card"}, []string{"H&M", "Uniqlo", "Zara"}},
+	{"Electronics", 0.1, 1500, 40000, []string{"card"}, []string{"Best Buy", "Amazon", "Apple Store"}},
+	{"Pharmacy", 0.25, 800, 6000, []string{"card", "cash"}, []string{"CVS Pharmacy", "Walgreens"}},
+}
+
+type demoGenerator struct {
+	rng  *rand.Rand
+	opts DemoOptions
+	demo *Demo
+
+	// accounts maps keys to their index in demo.Accounts
+	accounts map[string]int
+	// balances in cents by account key
+	balances map[string]int64
+	cats     map[string]uuid.UUID
+}
+
+func (g *demoGenerator) generate() *Demo {
+	firstName := pick(g.rng, []string{"Alex", "Sam", "Jordan", "Taylor", "Morgan", "Casey", "Riley"})
+	lastName := pick(g.rng, []string{"Smith", "Garcia", "Johnson", "Lee", "Brown", "Martin", "Silva"})
+
+	user := &model.User{
+		ID:        g.uuid(),
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     fmt.Sprintf("demo+%d@example.com", g.opts.Seed),
+		Password:  DefaultPassword,
+		Role:      "user",
+		Active:    true,
+	}
+
+	name := fmt.Sprintf("%s's workspace", firstName)
+	g.demo = &Demo{
+		User: user,
+		Workspace: &model.Workspace{
+			ID:          g.uuid(),
+			Name:        name,
+			Description: pgtype.Text{String: name, Valid: true},
+			Currency:    "usd",
+			Language:    "en-us",
+			UserID:      user.ID,
+		},
+	}
+
+	for _, a := range demoAccounts {
+		g.accounts[a.key] = len(g.demo.Accounts)
+		g.balances[a.key] = a.opening
+		g.demo.Accounts = append(g.demo.Accounts, model.CreateAccountsParams{
+			ID:                   g.uuid(),
+			Name:                 a.name,
+			FinancialInstitution: pgtype.Text{String: a.institution, Valid: a.institution != ""},
+			AccountType:          pgtype.Text{String: a.accountType, Valid: true},
+			UserID:               user.ID,
+			WorkspaceID:          g.demo.Workspace.ID,
+		})
+	}
+
+	// parents come first so they exist when the children are inserted
+	for _, c := range demoCategories {
+		parent := g.category(c.name, c.cType, uuid.NullUUID{})
+		for _, child := range c.children {
+			g.category(child, c.cType, uuid.NullUUID{UUID: parent, Valid: true})
+		}
+	}
+
+	end := time.Date(g.opts.End.Year(), g.opts.End.Month(), g.opts.End.Day(), 0, 0, 0, 0, time.UTC)
+	for day := end.AddDate(0, -g.opts.Months, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		g.monthly(day)
+		g.everyday(day)
+	}
+
+	for key, i := range g.accounts {
+		g.demo.Accounts[i].Balance = money(g.balances[key])
+	}
+
+	return g.demo
+}
+
+// monthly adds the transactions happening on fixed days of the month
+func (g *demoGenerator) monthly(day time.Time) {
+	scale := int64(g.opts.Scale)
+
+	switch day.Day() {
+	case 1:
+		g.add(day, "Rent", "checking", "Maple Street Apartments", -150000*scale)
+	case 3:
+		g.add(day, "Gym", "card", "Planet Fitness", -3999)
+	case 5:
+		g.add(day, "Internet", "checking", "Comcast", -5999)
+	case 8:
+		g.transfer(day, "checking", "cash", g.between(100, 200)*100)
+	case 10:
+		g.add(day, "Utilities", "checking", "City Power & Light", -g.between(6000, 14000)*scale)
+	case 12:
+		g.add(day, "Streaming", "card", "Netflix", -1549)
+		g.add(day, "Streaming", "card", "Spotify", -1099)
+	case 15:
+		g.add(day, "Phone", "checking", "T-Mobile", -4500)
+	case 18:
+		if g.rng.Float64() < 0.3 {
+			g.add(day, "Freelance", "checking", "Upwork", g.between(30000, 150000))
+		}
+	case 25:
+		g.add(day, "Salary", "checking", "Acme Corp Payroll", 425000*scale)
+	case 26:
+		g.transfer(day, "checking", "savings", 50000*scale)
+	case 28:
+		// pay off the credit card
+		if owed := -g.balances["card"]; owed > 0 {
+			g.transfer(day, "checking", "card", owed)
+		}
+	}
+
+	if day.AddDate(0, 0, 1).Day() == 1 {
+		// 4% a year
+		g.add(day, "Interest", "savings", "Savings Interest", g.balances["savings"]*4/100/12)
+	}
+}
+
+// everyday adds the random spending of a day
+func (g *demoGenerator) everyday(day time.Time) {
+	weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+
+	for i := 0; i < g.opts.Scale; i++ {
+		for _, s := range demoSpendings {
+			p := s.perWeek / 7
+			if weekend && s.category == "Restaurants" {
+				p *= 2
+			}
+			if g.rng.Float64() >= p {
+				continue
+			}
+
+			value := g.between(s.min, s.max)
+			account := pick(g.rng, s.accounts)
+			if account == "cash" && g.balances["cash"] < value {
+				account = "card"
+			}
+
+			g.add(day, s.category, account, pick(g.rng, s.merchants), -value)
+		}
+	}
+}
+
+func (g *demoGenerator) category(name, cType string, parent uuid.NullUUID) uuid.UUID {
+	id := g.uuid()
+	g.cats[name] = id
+	g.demo.Categories = append(g.demo.Categories, model.CreateCategoriesParams{
+		ID:          id,
+		Name:        name,
+		CType:       cType,
+		ParentID:    parent,
+		UserID:      g.demo.User.ID,
+		WorkspaceID: g.demo.Workspace.ID,
+	})
+
+	return id
+}
+
+// add appends a transaction of value cents at a random time of day
+func (g *demoGenerator) add(day time.Time, category, account, title string, value int64) {
+	g.addAt(g.timeOfDay(day), category, account, title, value)
+}
+
+func (g *demoGenerator) addAt(handledAt time.Time, category, account, title string, value int64) {
+	g.balances[account] += value
+	g.demo.Transactions = append(g.demo.Transactions, model.CreateTransactionsParams{
+		ID:          g.uuid(),
+		Title:       title,
+		Currency:    pgtype.Text{String: g.demo.Workspace.Currency, Valid: true},
+		Value:       money(value),
+		UserID:      g.demo.User.ID,
+		WorkspaceID: g.demo.Workspace.ID,
+		CategoryID:  g.cats[category],
+		AccountID:   g.demo.Accounts[g.accounts[account]].ID,
+		HandledAt:   pgtype.Timestamp{Time: handledAt, Valid: true},
+	})
+}
+
+// transfer moves value cents between two accounts
+func (g *demoGenerator) transfer(day time.Time, from, to string, value int64) {
+	handledAt := g.timeOfDay(day)
+	fromName := g.demo.Accounts[g.accounts[from]].Name
+	toName := g.demo.Accounts[g.accounts[to]].Name
+
+	g.addAt(handledAt, "Transfers", from, "Transfer to "+toName, -value)
+	g.addAt(handledAt, "Transfers", to, "Transfer from "+fromName, value)
+}
+
+func (g *demoGenerator) timeOfDay(day time.Time) time.Time {
+	return day.Add(time.Duration(g.between(8*60, 22*60)) * time.Minute)
+}
+
+func (g *demoGenerator) between(min, max int64) int64 {
+	return g.rng.Int63n(max-min+1) + min
+}
+
+func (g *demoGenerator) uuid() uuid.UUID {
+	return uuid.Must(uuid.NewRandomFromReader(g.rng))
+}
+
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.Intn(len(items))]
+}
+
+// money converts cents to a numeric
+func money(cents int64) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(cents), Exp: -2, Valid: true}
+}
@@ -0,0 +1,76 @@
+package fixture
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDemo(t *testing.T) {
+	end := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+	opts := DemoOptions{Seed: 42, Scale: 1, Months: 3, End: end}
+
+	demo := NewDemo(opts)
+
+	t.Run("Same Seed Same Data", func(t *testing.T) {
+		assert.Equal(t, demo, NewDemo(opts))
+	})
+
+	t.Run("Other Seed Other Data", func(t *testing.T) {
+		other := NewDemo(DemoOptions{Seed: 7, Scale: 1, Months: 3, End: end})
+		assert.NotEqual(t, demo.User.ID, other.User.ID)
+		assert.NotEqual(t, len(demo.Transactions), len(other.Transactions))
+	})
+
+	t.Run("Category Tree", func(t *testing.T) {
+		seen := map[uuid.UUID]bool{}
+		for _, c := range demo.Categories {
+			if c.ParentID.Valid {
+				assert.True(t, seen[c.ParentID.UUID], "parent of %s comes first", c.Name)
+			}
+			seen[c.ID] = true
+		}
+	})
+
+	t.Run("Transactions", func(t *testing.T) {
+		accounts := map[uuid.UUID]int64{}
+		for _, a := range demo.Accounts {
+			accounts[a.ID] = 0
+		}
+		types := map[uuid.UUID]string{}
+		for _, c := range demo.Categories {
+			types[c.ID] = c.CType
+		}
+
+		var transfers, salaries int64
+		for _, tr := range demo.Transactions {
+			assert.Contains(t, accounts, tr.AccountID)
+			assert.Contains(t, types, tr.CategoryID)
+			assert.False(t, tr.HandledAt.Time.After(end.AddDate(0, 0, 1)))
+			assert.False(t, tr.HandledAt.Time.Before(end.AddDate(0, -3, 0)))
+
+			value := tr.Value.Int.Int64()
+			switch types[tr.CategoryID] {
+			case CategoryTransfer:
+				transfers += value
+			case CategoryExpense:
+				assert.Negative(t, value)
+			case CategoryIncome:
+				assert.Positive(t, value)
+			}
+			if tr.Title == "Acme Corp Payroll" {
+				salaries++
+			}
+		}
+
+		assert.Zero(t, transfers, "transfers cancel each other")
+		assert.EqualValues(t, 3, salaries)
+	})
+
+	t.Run("Scale", func(t *testing.T) {
+		scaled := NewDemo(DemoOptions{Seed: 42, Scale: 5, Months: 3, End: end})
+		assert.Greater(t, len(scaled.Transactions), 3*len(demo.Transactions))
+	})
+}
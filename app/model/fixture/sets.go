@@ -0,0 +1,92 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/utils"
+)
+
+// DefaultPassword is the password of every seeded user
+const DefaultPassword = "password1234"
+
+// Set inserts a group of fixtures using the given queries
+type Set func(ctx context.Context, q *model.Queries) error
+
+// Sets are the fixture sets that can be seeded by name
+var Sets = map[string]Set{
+	"users": Users(10),
+	"demo":  DemoSet(DemoOptions{Seed: 1, Scale: 1, Months: 6}),
+}
+
+// SetNames returns the names of the available fixture sets
+func SetNames() []string {
+	names := make([]string, 0, len(Sets))
+	for name := range Sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Users returns a set creating n random users, each with its own workspace
+func Users(n int) Set {
+	return func(ctx context.Context, q *model.Queries) error {
+		for i := 0; i < n; i++ {
+			if _, _, err := CreateUser(ctx, q, GetMockUser()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// CreateUser inserts the given user with DefaultPassword when it has none,
+// along with a workspace like the one created on registration
+func CreateUser(ctx context.Context, q *model.Queries, u *model.User) (*model.User, *model.Workspace, error) {
+	password := u.Password
+	if password == "" {
+		password = DefaultPassword
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	role := u.Role
+	if role == "" {
+		role = "user"
+	}
+
+	user, err := q.CreateUser(ctx, model.CreateUserParams{
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Email:     u.Email,
+		Password:  hashedPassword,
+		Active:    true,
+		Role:      role,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create user %s: %w", u.Email, err)
+	}
+
+	name := fmt.Sprintf("%s's workspace", user.FirstName)
+	workspace, err := q.CreateWorkspace(ctx, model.CreateWorkspaceParams{
+		Name:        name,
+		Description: pgtype.Text{String: name, Valid: true},
+		Currency:    "usd",
+		Language:    "en-us",
+		UserID:      user.ID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create workspace for %s: %w", u.Email, err)
+	}
+
+	return user, workspace, nil
+}
@@ -5,6 +5,8 @@
 package model
 
 import (
+	"encoding/json"
+
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -23,6 +25,33 @@ type Account struct {
 	DeletedAt            pgtype.Timestamp `json:"deleted_at"`
 }
 
+type Attachment struct {
+	ID            uuid.UUID        `json:"id"`
+	TransactionID uuid.UUID        `json:"transaction_id"`
+	WorkspaceID   uuid.UUID        `json:"workspace_id"`
+	UserID        uuid.UUID        `json:"user_id"`
+	Filename      string           `json:"filename"`
+	ContentType   string           `json:"content_type"`
+	Size          int64            `json:"size"`
+	StorageKey    string           `json:"storage_key"`
+	ThumbnailKey  pgtype.Text      `json:"thumbnail_key"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID          int64            `json:"id"`
+	ActorID     uuid.NullUUID    `json:"actor_id"`
+	WorkspaceID uuid.NullUUID    `json:"workspace_id"`
+	EntityType  string           `json:"entity_type"`
+	EntityID    uuid.NullUUID    `json:"entity_id"`
+	Action      string           `json:"action"`
+	Before      json.RawMessage  `json:"before"`
+	After       json.RawMessage  `json:"after"`
+	IpAddress   pgtype.Text      `json:"ip_address"`
+	RequestID   pgtype.Text      `json:"request_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
 type Category struct {
 	ID          uuid.UUID        `json:"id"`
 	Name        string           `json:"name"`
@@ -33,6 +62,54 @@ type Category struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	ParentID    uuid.NullUUID    `json:"parent_id"`
+}
+
+type CategoryCorrection struct {
+	ID             uuid.UUID        `json:"id"`
+	TransactionID  uuid.UUID        `json:"transaction_id"`
+	WorkspaceID    uuid.UUID        `json:"workspace_id"`
+	UserID         uuid.UUID        `json:"user_id"`
+	Title          string           `json:"title"`
+	FromCategoryID uuid.UUID        `json:"from_category_id"`
+	ToCategoryID   uuid.UUID        `json:"to_category_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type JobRun struct {
+	ID          uuid.UUID        `json:"id"`
+	Kind        string           `json:"kind"`
+	Status      string           `json:"status"`
+	Input       json.RawMessage  `json:"input"`
+	Result      json.RawMessage  `json:"result"`
+	Error       json.RawMessage  `json:"error"`
+	UserID      uuid.UUID        `json:"user_id"`
+	WorkspaceID uuid.UUID        `json:"workspace_id"`
+	FinishedAt  pgtype.Timestamp `json:"finished_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type OutboxEvent struct {
+	ID          int64            `json:"id"`
+	Topic       string           `json:"topic"`
+	Payload     json.RawMessage  `json:"payload"`
+	Attempts    int32            `json:"attempts"`
+	LastError   pgtype.Text      `json:"last_error"`
+	AvailableAt pgtype.Timestamp `json:"available_at"`
+	PublishedAt pgtype.Timestamp `json:"published_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type Payee struct {
+	ID          uuid.UUID        `json:"id"`
+	Name        string           `json:"name"`
+	Aliases     []string         `json:"aliases"`
+	CategoryID  uuid.NullUUID    `json:"category_id"`
+	WorkspaceID uuid.UUID        `json:"workspace_id"`
+	UserID      uuid.UUID        `json:"user_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 }
 
 type Profile struct {
@@ -46,20 +123,78 @@ type Profile struct {
 	DeletedAt pgtype.Timestamp `json:"deleted_at"`
 }
 
-type Transaction struct {
+type Rule struct {
+	ID            uuid.UUID        `json:"id"`
+	Name          string           `json:"name"`
+	Position      int32            `json:"position"`
+	Active        bool             `json:"active"`
+	TitlePattern  pgtype.Text      `json:"title_pattern"`
+	NotePattern   pgtype.Text      `json:"note_pattern"`
+	MinValue      pgtype.Numeric   `json:"min_value"`
+	MaxValue      pgtype.Numeric   `json:"max_value"`
+	AccountID     uuid.NullUUID    `json:"account_id"`
+	SetCategoryID uuid.NullUUID    `json:"set_category_id"`
+	AddTags       []string         `json:"add_tags"`
+	SetTitle      pgtype.Text      `json:"set_title"`
+	WorkspaceID   uuid.UUID        `json:"workspace_id"`
+	UserID        uuid.UUID        `json:"user_id"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type Tag struct {
 	ID          uuid.UUID        `json:"id"`
-	Title       string           `json:"title"`
-	Note        pgtype.Text      `json:"note"`
-	Currency    pgtype.Text      `json:"currency"`
-	Value       pgtype.Numeric   `json:"value"`
-	UserID      uuid.UUID        `json:"user_id"`
+	Name        string           `json:"name"`
 	WorkspaceID uuid.UUID        `json:"workspace_id"`
-	CategoryID  uuid.UUID        `json:"category_id"`
-	AccountID   uuid.UUID        `json:"account_id"`
-	HandledAt   pgtype.Timestamp `json:"handled_at"`
+	UserID      uuid.UUID        `json:"user_id"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
-	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+}
+
+type Transaction struct {
+	ID           uuid.UUID        `json:"id"`
+	Title        string           `json:"title"`
+	Note         pgtype.Text      `json:"note"`
+	Currency     pgtype.Text      `json:"currency"`
+	Value        pgtype.Numeric   `json:"value"`
+	UserID       uuid.UUID        `json:"user_id"`
+	WorkspaceID  uuid.UUID        `json:"workspace_id"`
+	CategoryID   uuid.UUID        `json:"category_id"`
+	AccountID    uuid.UUID        `json:"account_id"`
+	HandledAt    pgtype.Timestamp `json:"handled_at"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	DeletedAt    pgtype.Timestamp `json:"deleted_at"`
+	SearchVector interface{}      `json:"search_vector"`
+	PayeeID      uuid.NullUUID    `json:"payee_id"`
+}
+
+type TransactionLine struct {
+	TransactionID uuid.UUID        `json:"transaction_id"`
+	SplitID       uuid.NullUUID    `json:"split_id"`
+	WorkspaceID   uuid.UUID        `json:"workspace_id"`
+	AccountID     uuid.UUID        `json:"account_id"`
+	CategoryID    uuid.UUID        `json:"category_id"`
+	Value         pgtype.Numeric   `json:"value"`
+	HandledAt     pgtype.Timestamp `json:"handled_at"`
+	PayeeID       uuid.NullUUID    `json:"payee_id"`
+}
+
+type TransactionSplit struct {
+	ID            uuid.UUID        `json:"id"`
+	TransactionID uuid.UUID        `json:"transaction_id"`
+	WorkspaceID   uuid.UUID        `json:"workspace_id"`
+	CategoryID    uuid.UUID        `json:"category_id"`
+	Value         pgtype.Numeric   `json:"value"`
+	Note          pgtype.Text      `json:"note"`
+	Position      int32            `json:"position"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type TransactionTag struct {
+	TransactionID uuid.UUID        `json:"transaction_id"`
+	TagID         uuid.UUID        `json:"tag_id"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
 }
 
 type User struct {
@@ -76,6 +211,39 @@ type User struct {
 	DeletedAt pgtype.Timestamp `json:"deleted_at"`
 }
 
+type Webhook struct {
+	ID          uuid.UUID        `json:"id"`
+	Url         string           `json:"url"`
+	Secret      string           `json:"secret"`
+	Events      []string         `json:"events"`
+	Description pgtype.Text      `json:"description"`
+	Active      bool             `json:"active"`
+	UserID      uuid.UUID        `json:"user_id"`
+	WorkspaceID uuid.UUID        `json:"workspace_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID        `json:"id"`
+	WebhookID      uuid.UUID        `json:"webhook_id"`
+	WorkspaceID    uuid.UUID        `json:"workspace_id"`
+	EventID        string           `json:"event_id"`
+	Event          string           `json:"event"`
+	Payload        json.RawMessage  `json:"payload"`
+	Status         string           `json:"status"`
+	Attempts       int32            `json:"attempts"`
+	ResponseStatus pgtype.Int4      `json:"response_status"`
+	ResponseBody   pgtype.Text      `json:"response_body"`
+	LastError      pgtype.Text      `json:"last_error"`
+	DurationMs     pgtype.Int4      `json:"duration_ms"`
+	ReplayOf       uuid.NullUUID    `json:"replay_of"`
+	DeliveredAt    pgtype.Timestamp `json:"delivered_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
 type Workspace struct {
 	ID          uuid.UUID        `json:"id"`
 	Name        string           `json:"name"`
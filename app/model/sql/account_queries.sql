@@ -0,0 +1,11 @@
+-- name: GetWorkspaceAccounts :many
+SELECT * FROM accounts WHERE workspace_id = $1 AND deleted_at IS NULL ORDER BY name;
+
+-- name: CreateAccounts :copyfrom
+INSERT INTO accounts ("id", "name", "description", "balance", "financial_institution", "account_type", "user_id", "workspace_id") VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: DeleteAccounts :exec
+DELETE FROM accounts;
+
+-- name: CountWorkspaceAccounts :one
+SELECT count(*) FROM accounts WHERE workspace_id = @workspace_id AND id = ANY(@ids::uuid[]) AND deleted_at IS NULL;
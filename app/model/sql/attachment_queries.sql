@@ -0,0 +1,12 @@
+-- name: GetTransactionAttachments :many
+SELECT * FROM attachments WHERE transaction_id = $1 AND workspace_id = $2 ORDER BY created_at, id;
+
+-- name: GetTransactionAttachment :one
+SELECT * FROM attachments WHERE id = $1 AND transaction_id = $2 AND workspace_id = $3;
+
+-- name: CreateAttachment :one
+INSERT INTO attachments ("id", "transaction_id", "workspace_id", "user_id", "filename", "content_type", "size", "storage_key", "thumbnail_key")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+
+-- name: DeleteAttachment :one
+DELETE FROM attachments WHERE id = $1 AND transaction_id = $2 AND workspace_id = $3 RETURNING *;
@@ -0,0 +1,16 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_logs ("actor_id", "workspace_id", "entity_type", "entity_id", "action", "before", "after", "ip_address", "request_id")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetAuditLogs :many
+SELECT * FROM audit_logs
+WHERE (sqlc.narg('workspace_id')::uuid IS NULL OR workspace_id = sqlc.narg('workspace_id'))
+  AND (sqlc.narg('entity_type')::varchar IS NULL OR entity_type = sqlc.narg('entity_type'))
+  AND (sqlc.narg('entity_id')::uuid IS NULL OR entity_id = sqlc.narg('entity_id'))
+  AND (sqlc.narg('action')::varchar IS NULL OR action = sqlc.narg('action'))
+  AND (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('from')::timestamp IS NULL OR created_at >= sqlc.narg('from'))
+  AND (sqlc.narg('to')::timestamp IS NULL OR created_at < sqlc.narg('to'))
+  AND (sqlc.narg('before')::bigint IS NULL OR id < sqlc.narg('before'))
+ORDER BY id DESC
+LIMIT @lim;
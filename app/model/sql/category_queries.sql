@@ -0,0 +1,11 @@
+-- name: GetWorkspaceCategories :many
+SELECT * FROM categories WHERE workspace_id = $1 AND deleted_at IS NULL ORDER BY name;
+
+-- name: CreateCategories :copyfrom
+INSERT INTO categories ("id", "name", "description", "c_type", "parent_id", "user_id", "workspace_id") VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: DeleteCategories :exec
+DELETE FROM categories;
+
+-- name: CountWorkspaceCategories :one
+SELECT count(*) FROM categories WHERE workspace_id = @workspace_id AND id = ANY(@ids::uuid[]) AND deleted_at IS NULL;
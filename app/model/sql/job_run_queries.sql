@@ -0,0 +1,17 @@
+-- name: CreateJobRun :one
+INSERT INTO job_runs ("kind", "input", "user_id", "workspace_id") VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetWorkspaceJobRun :one
+SELECT * FROM job_runs WHERE id = $1 AND workspace_id = $2;
+
+-- name: LockPendingJobRun :one
+SELECT * FROM job_runs WHERE id = $1 AND status = 'pending' FOR UPDATE;
+
+-- name: FinishJobRun :exec
+UPDATE job_runs SET
+  status = $2,
+  result = $3,
+  error = $4,
+  finished_at = now(),
+  updated_at = now()
+WHERE id = $1;
@@ -0,0 +1,28 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox_events ("topic", "payload") VALUES ($1, $2);
+
+-- name: ClaimOutboxEvents :many
+SELECT * FROM outbox_events
+WHERE published_at IS NULL AND available_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events SET published_at = now(), attempts = attempts + 1 WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events SET
+  attempts = attempts + 1,
+  last_error = @last_error,
+  available_at = now() + (@retry_in_seconds::integer * interval '1 second')
+WHERE id = @id;
+
+-- name: GetOutboxLag :one
+SELECT count(*) AS pending,
+  COALESCE(EXTRACT(EPOCH FROM now()::timestamp - min(created_at)), 0)::float8 AS lag_seconds
+FROM outbox_events
+WHERE published_at IS NULL;
+
+-- name: DeletePublishedOutboxEvents :exec
+DELETE FROM outbox_events WHERE published_at < now() - (@older_than_seconds::integer * interval '1 second');
@@ -0,0 +1,17 @@
+-- name: GetWorkspacePayees :many
+SELECT * FROM payees WHERE workspace_id = $1 ORDER BY lower(name);
+
+-- name: CountWorkspacePayees :one
+SELECT count(*) FROM payees WHERE workspace_id = @workspace_id AND id = ANY(@ids::uuid[]);
+
+-- name: CreatePayee :one
+INSERT INTO payees ("name", "aliases", "category_id", "workspace_id", "user_id") VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdatePayee :one
+UPDATE payees SET "name" = $3, "aliases" = $4, "category_id" = $5, "updated_at" = now()
+WHERE id = $1 AND workspace_id = $2
+RETURNING *;
+
+-- name: DeletePayee :execrows
+DELETE FROM payees WHERE id = $1 AND workspace_id = $2;
@@ -0,0 +1,51 @@
+-- name: GetCategoryReport :many
+SELECT l.category_id, c.name AS category_name, c.c_type, c.parent_id,
+  sum(l.value)::numeric AS total, count(*) AS lines
+FROM transaction_lines l
+JOIN categories c ON c.id = l.category_id
+WHERE l.workspace_id = @workspace_id
+  AND (sqlc.narg('account_id')::uuid IS NULL OR l.account_id = sqlc.narg('account_id'))
+  AND (sqlc.narg('category_id')::uuid IS NULL OR l.category_id = sqlc.narg('category_id'))
+  AND (sqlc.narg('tag')::varchar IS NULL OR EXISTS (
+    SELECT 1 FROM transaction_tags ft JOIN tags fg ON fg.id = ft.tag_id
+    WHERE ft.transaction_id = l.transaction_id AND lower(fg.name) = lower(sqlc.narg('tag'))
+  ))
+  AND (sqlc.narg('from')::timestamp IS NULL OR l.handled_at >= sqlc.narg('from'))
+  AND (sqlc.narg('to')::timestamp IS NULL OR l.handled_at < sqlc.narg('to'))
+GROUP BY l.category_id, c.name, c.c_type, c.parent_id
+ORDER BY total, c.name;
+
+-- name: GetTagReport :many
+SELECT g.id AS tag_id, g.name AS tag_name,
+  sum(l.value)::numeric AS total, count(DISTINCT l.transaction_id) AS transactions
+FROM tags g
+JOIN transaction_tags tt ON tt.tag_id = g.id
+JOIN transaction_lines l ON l.transaction_id = tt.transaction_id
+WHERE g.workspace_id = @workspace_id
+  AND (sqlc.narg('account_id')::uuid IS NULL OR l.account_id = sqlc.narg('account_id'))
+  AND (sqlc.narg('category_id')::uuid IS NULL OR l.category_id = sqlc.narg('category_id'))
+  AND (sqlc.narg('tag')::varchar IS NULL OR EXISTS (
+    SELECT 1 FROM transaction_tags ft JOIN tags fg ON fg.id = ft.tag_id
+    WHERE ft.transaction_id = l.transaction_id AND lower(fg.name) = lower(sqlc.narg('tag'))
+  ))
+  AND (sqlc.narg('from')::timestamp IS NULL OR l.handled_at >= sqlc.narg('from'))
+  AND (sqlc.narg('to')::timestamp IS NULL OR l.handled_at < sqlc.narg('to'))
+GROUP BY g.id, g.name
+ORDER BY total, g.name;
+
+-- name: GetPayeeReport :many
+SELECT p.id AS payee_id, p.name AS payee_name,
+  sum(l.value)::numeric AS total, count(DISTINCT l.transaction_id) AS transactions
+FROM payees p
+JOIN transaction_lines l ON l.payee_id = p.id
+WHERE p.workspace_id = @workspace_id
+  AND (sqlc.narg('account_id')::uuid IS NULL OR l.account_id = sqlc.narg('account_id'))
+  AND (sqlc.narg('category_id')::uuid IS NULL OR l.category_id = sqlc.narg('category_id'))
+  AND (sqlc.narg('tag')::varchar IS NULL OR EXISTS (
+    SELECT 1 FROM transaction_tags ft JOIN tags fg ON fg.id = ft.tag_id
+    WHERE ft.transaction_id = l.transaction_id AND lower(fg.name) = lower(sqlc.narg('tag'))
+  ))
+  AND (sqlc.narg('from')::timestamp IS NULL OR l.handled_at >= sqlc.narg('from'))
+  AND (sqlc.narg('to')::timestamp IS NULL OR l.handled_at < sqlc.narg('to'))
+GROUP BY p.id, p.name
+ORDER BY total, p.name;
@@ -0,0 +1,46 @@
+-- name: GetWorkspaceRules :many
+SELECT * FROM rules WHERE workspace_id = $1 ORDER BY position, created_at;
+
+-- name: CreateRule :one
+INSERT INTO rules (
+  "name", "position", "active", "title_pattern", "note_pattern", "min_value", "max_value",
+  "account_id", "set_category_id", "add_tags", "set_title", "workspace_id", "user_id"
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING *;
+
+-- name: UpdateRule :one
+UPDATE rules SET "name" = $3, "position" = $4, "active" = $5, "title_pattern" = $6, "note_pattern" = $7,
+  "min_value" = $8, "max_value" = $9, "account_id" = $10, "set_category_id" = $11, "add_tags" = $12,
+  "set_title" = $13, "updated_at" = now()
+WHERE id = $1 AND workspace_id = $2
+RETURNING *;
+
+-- name: DeleteRule :execrows
+DELETE FROM rules WHERE id = $1 AND workspace_id = $2;
+
+-- name: GetRuleCandidates :many
+SELECT t.id, t.title, t.note, t.value, t.account_id, t.category_id,
+  coalesce(array_agg(g.name ORDER BY lower(g.name)) FILTER (WHERE g.id IS NOT NULL), '{}')::varchar[] AS tags,
+  EXISTS (SELECT 1 FROM category_corrections c WHERE c.transaction_id = t.id) AS corrected
+FROM transactions t
+LEFT JOIN transaction_tags tt ON tt.transaction_id = t.id
+LEFT JOIN tags g ON g.id = tt.tag_id
+WHERE t.workspace_id = @workspace_id
+  AND t.deleted_at IS NULL
+  AND t.id > @after
+  AND (sqlc.narg('from')::timestamp IS NULL OR t.handled_at >= sqlc.narg('from'))
+  AND (sqlc.narg('to')::timestamp IS NULL OR t.handled_at < sqlc.narg('to'))
+GROUP BY t.id
+ORDER BY t.id
+LIMIT @lim;
+
+-- name: CreateCategoryCorrection :exec
+INSERT INTO category_corrections ("transaction_id", "workspace_id", "user_id", "title", "from_category_id", "to_category_id")
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetCategoryCorrections :many
+SELECT DISTINCT ON (c.transaction_id) c.title, c.to_category_id
+FROM category_corrections c
+JOIN transactions t ON t.id = c.transaction_id
+WHERE c.workspace_id = $1 AND t.deleted_at IS NULL AND t.category_id = c.to_category_id
+ORDER BY c.transaction_id, c.created_at DESC;
@@ -0,0 +1,30 @@
+-- name: GetWorkspaceTags :many
+SELECT g.id, g.name, g.created_at, count(t.id) AS transactions
+FROM tags g
+LEFT JOIN transaction_tags tt ON tt.tag_id = g.id
+LEFT JOIN transactions t ON t.id = tt.transaction_id AND t.deleted_at IS NULL
+WHERE g.workspace_id = $1
+GROUP BY g.id
+ORDER BY lower(g.name);
+
+-- name: GetTransactionTags :many
+SELECT g.* FROM tags g
+JOIN transaction_tags tt ON tt.tag_id = g.id
+WHERE tt.transaction_id = $1 AND g.workspace_id = $2
+ORDER BY lower(g.name);
+
+-- name: UpsertTag :one
+INSERT INTO tags ("name", "workspace_id", "user_id") VALUES ($1, $2, $3)
+ON CONFLICT ("workspace_id", lower("name")) DO UPDATE SET "updated_at" = tags.updated_at
+RETURNING *;
+
+-- name: DeleteTag :execrows
+DELETE FROM tags WHERE id = $1 AND workspace_id = $2;
+
+-- name: AddTransactionTags :exec
+INSERT INTO transaction_tags ("transaction_id", "tag_id")
+SELECT @transaction_id::uuid, unnest(@tag_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTransactionTags :exec
+DELETE FROM transaction_tags WHERE transaction_id = $1;
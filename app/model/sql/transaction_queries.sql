@@ -0,0 +1,70 @@
+-- name: CountWorkspaceTransactions :one
+SELECT count(*) FROM transactions WHERE workspace_id = $1 AND deleted_at IS NULL;
+
+-- name: CreateTransactions :copyfrom
+INSERT INTO transactions ("id", "title", "note", "currency", "value", "user_id", "workspace_id", "category_id", "account_id", "handled_at", "payee_id") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: DeleteTransactions :exec
+DELETE FROM transactions;
+
+-- name: GetWorkspaceTransactionValue :one
+SELECT value FROM transactions WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL FOR UPDATE;
+
+-- name: SearchTransactions :many
+SELECT t.id, t.title, t.note, t.currency, t.value, t.category_id, t.account_id, t.handled_at,
+  c.name AS category_name, a.name AS account_name,
+  ts_rank_cd(t.search_vector, s.query)::real AS rank,
+  ts_headline(s.config, t.title, s.query, 'HighlightAll=true, StartSel=' || chr(57344) || ', StopSel=' || chr(57345))::text AS title_snippet,
+  ts_headline(s.config, coalesce(t.note, ''), s.query, 'MaxFragments=2, MaxWords=20, MinWords=5, StartSel=' || chr(57344) || ', StopSel=' || chr(57345))::text AS note_snippet
+FROM transactions t
+JOIN categories c ON c.id = t.category_id
+JOIN accounts a ON a.id = t.account_id
+CROSS JOIN LATERAL (
+  SELECT search_config(@language::varchar) AS config, to_tsquery(search_config(@language::varchar), @query::text) AS query
+) s
+WHERE t.workspace_id = @workspace_id
+  AND t.deleted_at IS NULL
+  AND t.search_vector @@ s.query
+  AND (sqlc.narg('account_id')::uuid IS NULL OR t.account_id = sqlc.narg('account_id'))
+  AND (sqlc.narg('category_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM transaction_lines l WHERE l.transaction_id = t.id AND l.category_id = sqlc.narg('category_id')
+  ))
+  AND (sqlc.narg('from')::timestamp IS NULL OR t.handled_at >= sqlc.narg('from'))
+  AND (sqlc.narg('to')::timestamp IS NULL OR t.handled_at < sqlc.narg('to'))
+  AND (sqlc.narg('min_value')::numeric IS NULL OR t.value >= sqlc.narg('min_value'))
+  AND (sqlc.narg('max_value')::numeric IS NULL OR t.value <= sqlc.narg('max_value'))
+  AND (sqlc.narg('tag')::varchar IS NULL OR EXISTS (
+    SELECT 1 FROM transaction_tags tt JOIN tags g ON g.id = tt.tag_id
+    WHERE tt.transaction_id = t.id AND lower(g.name) = lower(sqlc.narg('tag'))
+  ))
+ORDER BY rank DESC, t.handled_at DESC, t.id
+LIMIT @lim OFFSET @off;
+
+-- name: WorkspaceTransactionExists :one
+SELECT EXISTS (SELECT 1 FROM transactions WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL);
+
+-- name: CreateTransaction :one
+INSERT INTO transactions ("title", "note", "currency", "value", "user_id", "workspace_id", "category_id", "account_id", "handled_at", "payee_id")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, title, note, currency, value, user_id, workspace_id, category_id, account_id, handled_at, created_at, updated_at, payee_id;
+
+-- name: LockWorkspaceTransaction :one
+SELECT id, title, note, category_id, payee_id FROM transactions WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL FOR UPDATE;
+
+-- name: UpdateTransaction :one
+UPDATE transactions SET "title" = $3, "note" = $4, "category_id" = $5, "updated_at" = now()
+WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL
+RETURNING id, title, note, currency, value, user_id, workspace_id, category_id, account_id, handled_at, created_at, updated_at, payee_id;
+
+-- name: SetTransactionPayee :exec
+UPDATE transactions SET "payee_id" = $3, "updated_at" = now() WHERE id = $1 AND workspace_id = $2;
+
+-- name: GetUnlinkedTransactions :many
+SELECT id, title FROM transactions
+WHERE workspace_id = @workspace_id AND deleted_at IS NULL AND payee_id IS NULL AND id > @after
+ORDER BY id
+LIMIT @lim;
+
+-- name: LinkTransactionsPayee :execrows
+UPDATE transactions SET "payee_id" = @payee_id, "updated_at" = now()
+WHERE workspace_id = @workspace_id AND id = ANY(@ids::uuid[]) AND payee_id IS NULL;
@@ -0,0 +1,9 @@
+-- name: GetTransactionSplits :many
+SELECT * FROM transaction_splits WHERE transaction_id = $1 AND workspace_id = $2 ORDER BY position;
+
+-- name: CreateTransactionSplit :one
+INSERT INTO transaction_splits ("transaction_id", "workspace_id", "category_id", "value", "note", "position")
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: DeleteTransactionSplits :exec
+DELETE FROM transaction_splits WHERE transaction_id = $1 AND workspace_id = $2;
@@ -4,6 +4,12 @@ SELECT * FROM users WHERE id = $1 LIMIT 1;
 -- name: GetUserByEmail :one
 SELECT * FROM users WHERE email = $1 LIMIT 1;
 
+-- name: GetUserLanguage :one
+SELECT "language" FROM profiles
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at
+LIMIT 1;
+
 -- name: ListUsers :many
 SELECT * FROM users ORDER BY id;
 
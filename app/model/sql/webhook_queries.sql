@@ -0,0 +1,52 @@
+-- name: GetWorkspaceWebhooks :many
+SELECT * FROM webhooks WHERE workspace_id = $1 AND deleted_at IS NULL ORDER BY created_at;
+
+-- name: GetWorkspaceWebhook :one
+SELECT * FROM webhooks WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL;
+
+-- name: GetEventWebhooks :many
+SELECT * FROM webhooks
+WHERE workspace_id = @workspace_id AND @event::varchar = ANY(events) AND active AND deleted_at IS NULL;
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks ("url", "secret", "events", "description", "user_id", "workspace_id") VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: DeleteWebhook :execrows
+UPDATE webhooks SET
+  active = false,
+  deleted_at = now(),
+  updated_at = now()
+WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL;
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC, id
+LIMIT $2 OFFSET $3;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries WHERE id = $1;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries ("webhook_id", "workspace_id", "event_id", "event", "payload") VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT ("webhook_id", "event_id") WHERE replay_of IS NULL DO UPDATE SET updated_at = now()
+RETURNING *;
+
+-- name: ReplayWebhookDelivery :one
+INSERT INTO webhook_deliveries ("webhook_id", "workspace_id", "event_id", "event", "payload", "replay_of")
+SELECT d.webhook_id, d.workspace_id, d.event_id, d.event, d.payload, d.id
+FROM webhook_deliveries d
+WHERE d.id = $1 AND d.webhook_id = $2
+RETURNING *;
+
+-- name: UpdateWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries SET
+  status = $2,
+  attempts = attempts + 1,
+  response_status = $3,
+  response_body = $4,
+  last_error = $5,
+  duration_ms = $6,
+  delivered_at = CASE WHEN $2 = 'succeeded' THEN now() ELSE delivered_at END,
+  updated_at = now()
+WHERE id = $1;
@@ -11,4 +11,7 @@ INSERT INTO workspaces ("name", "description", "currency", "language", "user_id"
 UPDATE workspaces SET "name" = $2, "description" = $3, "currency" = $4, "language" = $5 WHERE id = $1;
 
 -- name: DeleteWorkspaces :exec
-DELETE FROM workspaces;
\ No newline at end of file
+DELETE FROM workspaces;
+
+-- name: CreateWorkspaceWithId :one
+INSERT INTO workspaces ("id", "name", "description", "currency", "language", "user_id") VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
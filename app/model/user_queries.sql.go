@@ -163,6 +163,20 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (*User, error)
 	return &i, err
 }
 
+const getUserLanguage = `-- name: GetUserLanguage :one
+SELECT "language" FROM profiles
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at
+LIMIT 1
+`
+
+func (q *Queries) GetUserLanguage(ctx context.Context, userID uuid.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getUserLanguage, userID)
+	var language string
+	err := row.Scan(&language)
+	return language, err
+}
+
 const hardDeleteUser = `-- name: HardDeleteUser :exec
 DELETE FROM users WHERE id = $1
 `
@@ -47,6 +47,43 @@ func (q *Queries) CreateWorkspace(ctx context.Context, arg CreateWorkspaceParams
 	return &i, err
 }
 
+const createWorkspaceWithId = `-- name: CreateWorkspaceWithId :one
+INSERT INTO workspaces ("id", "name", "description", "currency", "language", "user_id") VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, name, description, currency, language, user_id, created_at, updated_at, deleted_at
+`
+
+type CreateWorkspaceWithIdParams struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	Currency    string      `json:"currency"`
+	Language    string      `json:"language"`
+	UserID      uuid.UUID   `json:"user_id"`
+}
+
+func (q *Queries) CreateWorkspaceWithId(ctx context.Context, arg CreateWorkspaceWithIdParams) (*Workspace, error) {
+	row := q.db.QueryRow(ctx, createWorkspaceWithId,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Currency,
+		arg.Language,
+		arg.UserID,
+	)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Currency,
+		&i.Language,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return &i, err
+}
+
 const deleteWorkspaces = `-- name: DeleteWorkspaces :exec
 DELETE FROM workspaces
 `
@@ -0,0 +1,57 @@
+// Package outbox implements the transactional outbox pattern.
+//
+// Side effects of a database change, like emails or webhooks, are written as
+// events in the outbox_events table inside the same transaction as the change
+// with Publish. They are lost with it when the transaction rolls back and kept
+// when it commits. A Relay then reads the pending events and passes them to
+// the handlers subscribed to their topic, at least once, so handlers must be
+// idempotent or cheap to repeat, e.g. enqueueing a job.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// Event is a side effect waiting to be published. Payload is decoded by the
+// handlers of its Topic.
+type Event struct {
+	ID        int64           `json:"id"`
+	Topic     string          `json:"topic"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Decode unmarshals the payload into v
+func (e *Event) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Publish writes an event with the payload encoded as JSON. q must run in the
+// transaction of the change the event belongs to, see model.Queries.WithTx.
+func Publish(ctx context.Context, q *model.Queries, topic string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", topic, err)
+	}
+
+	return q.CreateOutboxEvent(ctx, model.CreateOutboxEventParams{
+		Topic:   topic,
+		Payload: b,
+	})
+}
+
+func newEvent(e *model.OutboxEvent) *Event {
+	return &Event{
+		ID:        e.ID,
+		Topic:     e.Topic,
+		Payload:   e.Payload,
+		Attempts:  int(e.Attempts),
+		CreatedAt: e.CreatedAt.Time,
+	}
+}
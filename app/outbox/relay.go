@@ -0,0 +1,241 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/jobs"
+	"github.com/opchaves/gin-web-app/app/metrics"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// HandlerFunc handles an event. Returning an error publishes it again later
+// to all the handlers of its topic.
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+// Event statuses reported to the metrics
+const (
+	StatusPublished = "published"
+	StatusFailed    = "failed"
+)
+
+// RelayConfig configures a relay
+type RelayConfig struct {
+	Db     *pgxpool.Pool
+	Logger *slog.Logger
+	// BatchSize is how many events are claimed at once
+	BatchSize int
+	// PollInterval is how long to wait when there are no pending events
+	PollInterval time.Duration
+	// Retention is how long published events are kept
+	Retention time.Duration
+	// Backoff returns how long to wait before publishing a failed event again
+	// after the given number of attempts
+	Backoff func(attempts int) time.Duration
+	Metrics *metrics.Metrics
+}
+
+// Relay publishes the pending events to the handlers of their topic
+type Relay struct {
+	db           *pgxpool.Pool
+	q            *model.Queries
+	logger       *slog.Logger
+	batchSize    int
+	pollInterval time.Duration
+	retention    time.Duration
+	backoff      func(attempts int) time.Duration
+	metrics      *metrics.Metrics
+
+	mu       sync.RWMutex
+	handlers map[string][]HandlerFunc
+}
+
+// NewRelay returns a relay, subscribe the handlers before running it
+func NewRelay(c *RelayConfig) *Relay {
+	r := &Relay{
+		db:           c.Db,
+		q:            model.New(c.Db),
+		logger:       c.Logger,
+		batchSize:    c.BatchSize,
+		pollInterval: c.PollInterval,
+		retention:    c.Retention,
+		backoff:      c.Backoff,
+		metrics:      c.Metrics,
+		handlers:     map[string][]HandlerFunc{},
+	}
+
+	if r.batchSize < 1 {
+		r.batchSize = 100
+	}
+	if r.pollInterval == 0 {
+		r.pollInterval = time.Second
+	}
+	if r.retention == 0 {
+		r.retention = 7 * 24 * time.Hour
+	}
+	if r.backoff == nil {
+		r.backoff = jobs.ExponentialBackoff(10*time.Second, time.Hour)
+	}
+
+	return r
+}
+
+// Subscribe adds a handler of the events of a topic
+func (r *Relay) Subscribe(topic string, h HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[topic] = append(r.handlers[topic], h)
+}
+
+// Run publishes events until ctx is cancelled. The batch being published
+// when it is cancelled is finished first.
+func (r *Relay) Run(ctx context.Context) {
+	r.logger.Info("outbox relay started", slog.Int("batch_size", r.batchSize))
+
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for ctx.Err() == nil {
+		n, err := r.RelayOnce(context.WithoutCancel(ctx))
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to relay outbox events", slog.String("error", err.Error()))
+		}
+
+		if err := r.observeLag(ctx); err != nil && ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "failed to read outbox lag", slog.String("error", err.Error()))
+		}
+
+		if err == nil && n == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-cleanup.C:
+			if err := r.Cleanup(ctx); err != nil && ctx.Err() == nil {
+				r.logger.ErrorContext(ctx, "failed to delete published outbox events", slog.String("error", err.Error()))
+			}
+		case <-time.After(r.pollInterval):
+		}
+	}
+
+	r.logger.Info("outbox relay stopped")
+}
+
+// RelayOnce publishes a batch of pending events and returns how many were
+// claimed. Events are locked while being published, so relays in other
+// processes skip them.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+
+	events, err := q.ClaimOutboxEvents(ctx, int32(r.batchSize))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range events {
+		event := newEvent(e)
+
+		logger := r.logger.With(
+			slog.Int64("event_id", event.ID),
+			slog.String("topic", event.Topic),
+		)
+
+		if herr := r.publish(ctx, event); herr != nil {
+			retryIn := r.backoff(event.Attempts + 1)
+			logger.WarnContext(ctx, "outbox event failed, will retry",
+				slog.Int("attempts", event.Attempts+1),
+				slog.Duration("retry_in", retryIn),
+				slog.String("error", herr.Error()),
+			)
+
+			err = q.MarkOutboxEventFailed(ctx, model.MarkOutboxEventFailedParams{
+				ID:             event.ID,
+				LastError:      pgtype.Text{String: herr.Error(), Valid: true},
+				RetryInSeconds: int32(retryIn.Seconds()),
+			})
+			r.metrics.ObserveOutboxEvent(event.Topic, StatusFailed)
+		} else {
+			logger.DebugContext(ctx, "outbox event published")
+
+			err = q.MarkOutboxEventPublished(ctx, event.ID)
+			r.metrics.ObserveOutboxEvent(event.Topic, StatusPublished)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+// RelayAll publishes the pending events until there are none left, e.g. in
+// tests or scripts. It returns how many events were claimed.
+func (r *Relay) RelayAll(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := r.RelayOnce(ctx)
+		total += n
+		if err != nil || n < r.batchSize {
+			return total, err
+		}
+	}
+}
+
+// Cleanup deletes the events published before the retention period
+func (r *Relay) Cleanup(ctx context.Context) error {
+	return r.q.DeletePublishedOutboxEvents(ctx, int32(r.retention.Seconds()))
+}
+
+// publish calls the handlers of the event, turning panics into errors.
+// Events without handlers are published as is.
+func (r *Relay) publish(ctx context.Context, e *Event) (err error) {
+	r.mu.RLock()
+	handlers := r.handlers[e.Topic]
+	r.mu.RUnlock()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("outbox handler panicked: %v", p)
+		}
+	}()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Relay) observeLag(ctx context.Context) error {
+	lag, err := r.q.GetOutboxLag(ctx)
+	if err != nil {
+		return err
+	}
+
+	age := time.Duration(max(lag.LagSeconds, 0) * float64(time.Second))
+	r.metrics.SetOutboxLag(lag.Pending, age)
+
+	return nil
+}
@@ -1,15 +1,21 @@
 package app
 
 import (
-	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/opchaves/gin-web-app/app/handler"
 	"github.com/opchaves/gin-web-app/app/handler/middleware"
 	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
 	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/app/storage"
 )
 
+// multipartOverhead is how much bigger than the files upload bodies may be
+const multipartOverhead = 64 << 10
+
 func SetRoutes(c *Config) {
 	queries := model.New(c.Db)
 	redisService := service.NewRedisService(&service.RDConfig{
@@ -17,20 +23,64 @@ func SetRoutes(c *Config) {
 		Logger: c.Logger,
 		Redis:  c.RedisClient,
 	})
-	mailService := service.NewMailService(&service.MailConfig{
-		Username:   c.Cfg.MailUsername,
-		Password:   c.Cfg.MailPassword,
-		Origin:     c.Cfg.MailHost,
-		Port:       c.Cfg.MailPort,
-		Encryption: c.Cfg.MailEncryption,
-		Logger:     c.Logger,
-	})
 	userService := service.NewUserService(&service.USConfig{
 		Db:           c.Db,
 		Q:            queries,
 		Logger:       c.Logger,
 		RedisService: redisService,
-		MailService:  mailService,
+		MailService:  c.MailService,
+	})
+
+	workspaceService := service.NewWorkspaceService(&service.ServiceConfig{
+		Db:     c.Db,
+		Q:      queries,
+		Logger: c.Logger,
+	})
+
+	auditService := service.NewAuditService(&service.ServiceConfig{
+		Db:     c.Db,
+		Q:      queries,
+		Logger: c.Logger,
+	})
+
+	transactionService := service.NewTransactionService(&service.ServiceConfig{
+		Db:     c.Db,
+		Q:      queries,
+		Logger: c.Logger,
+	})
+
+	tagService := service.NewTagService(&service.ServiceConfig{
+		Db:     c.Db,
+		Q:      queries,
+		Logger: c.Logger,
+	})
+
+	reportService := service.NewReportService(&service.ServiceConfig{
+		Db:     c.Db,
+		Q:      queries,
+		Logger: c.Logger,
+	})
+
+	ruleService := service.NewRuleService(&service.ServiceConfig{
+		Db:     c.Db,
+		Q:      queries,
+		Logger: c.Logger,
+	})
+
+	payeeService := service.NewPayeeService(&service.ServiceConfig{
+		Db:     c.Db,
+		Q:      queries,
+		Logger: c.Logger,
+	})
+
+	attachmentService := service.NewAttachmentService(&service.AttachmentConfig{
+		Db:            c.Db,
+		Q:             queries,
+		Logger:        c.Logger,
+		Storage:       c.Storage,
+		MaxBytes:      c.MaxUploadBytes,
+		ThumbnailSize: c.Cfg.ThumbnailSize,
+		URLExpiry:     time.Duration(c.Cfg.StorageURLExpiry) * time.Second,
 	})
 
 	h := &handler.Handler{
@@ -40,24 +90,128 @@ func SetRoutes(c *Config) {
 		MaxBodyBytes: c.MaxBodyBytes,
 		UserService:  userService,
 		RedisService: redisService,
-		MailService:  mailService,
+		MailService:  c.MailService,
+
+		WorkspaceService:   workspaceService,
+		WebhookService:     c.WebhookService,
+		JobRunService:      c.JobRunService,
+		AuditService:       auditService,
+		TransactionService: transactionService,
+		AttachmentService:  attachmentService,
+		TagService:         tagService,
+		ReportService:      reportService,
+		RuleService:        ruleService,
+		PayeeService:       payeeService,
+	}
+	h.Files, _ = c.Storage.(*storage.Local)
+
+	if c.Cfg.MetricsPort == "" && c.Cfg.MetricsPassword != "" {
+		c.Router.GET("/metrics",
+			gin.BasicAuth(gin.Accounts{c.Cfg.MetricsUsername: c.Cfg.MetricsPassword}),
+			gin.WrapH(c.Metrics.Handler()),
+		)
+	} else if c.Cfg.MetricsPort == "" {
+		c.Logger.Warn("metrics are not served, set METRICS_PORT or METRICS_PASSWORD")
 	}
 
 	c.Router.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "No route found. Go to docs for a list of all routes",
+		c.Error(&apperrors.Error{
+			Type:    apperrors.NotFound,
+			Message: apperrors.NoRoute,
+			Key:     apperrors.MessageKey(apperrors.NoRoute),
 		})
 	})
 
-	c.Router.GET("/", h.GetHome)
-	c.Router.POST("/add-car", h.PostAddCar)
+	// Routes that need a longer timeout or bigger bodies, like uploads, go in
+	// their own group with their own limits
+	router := c.Router.Group("",
+		middleware.Timeout(c.TimeoutDuration),
+		middleware.BodyLimit(c.MaxBodyBytes),
+	)
+
+	router.GET("/", h.GetHome)
+	if h.Files != nil {
+		router.GET(strings.TrimSuffix(storage.FilesPath, "/")+"/*key", h.GetFile)
+	}
+	router.POST("/add-car", h.PostAddCar)
+
+	router.GET("/docs", h.GetDocs)
+	router.GET("/docs/openapi.json", h.GetOpenAPIJSON)
+	router.GET("/docs/openapi.yaml", h.GetOpenAPIYAML)
 
-	authGroup := c.Router.Group("/auth")
+	authGroup := router.Group("/auth")
 	authGroup.POST("/register", h.Register)
 	authGroup.POST("/login", h.Login)
 	authGroup.POST("/logout", h.Logout)
 	authGroup.POST("/forgot-password", h.ForgotPassword)
+	authGroup.GET("/csrf", h.GetCSRFToken)
 
 	authGroup.Use(middleware.AuthUser(c.Logger))
 	authGroup.GET("/me", h.GetCurrent)
+
+	workspaceGroup := router.Group("/workspaces/:workspaceId",
+		middleware.AuthUser(c.Logger),
+		middleware.Workspace(workspaceService),
+	)
+	workspaceGroup.GET("/audit-logs", h.GetAuditLogs)
+	workspaceGroup.POST("/transactions", h.CreateTransaction)
+	workspaceGroup.POST("/transactions/import", h.ImportTransactions)
+	workspaceGroup.GET("/transactions/search", h.SearchTransactions)
+	workspaceGroup.PATCH("/transactions/:transactionId", h.UpdateTransaction)
+	workspaceGroup.GET("/transactions/:transactionId/splits", h.GetTransactionSplits)
+	workspaceGroup.PUT("/transactions/:transactionId/splits", h.SplitTransaction)
+	workspaceGroup.GET("/transactions/:transactionId/tags", h.GetTransactionTags)
+	workspaceGroup.PUT("/transactions/:transactionId/tags", h.SetTransactionTags)
+	workspaceGroup.GET("/transactions/:transactionId/attachments", h.GetAttachments)
+	workspaceGroup.GET("/transactions/:transactionId/attachments/:attachmentId", h.GetAttachment)
+	workspaceGroup.GET("/transactions/:transactionId/attachments/:attachmentId/download", h.DownloadAttachment)
+	workspaceGroup.DELETE("/transactions/:transactionId/attachments/:attachmentId", h.DeleteAttachment)
+
+	workspaceGroup.GET("/tags", h.GetTags)
+	workspaceGroup.DELETE("/tags/:tagId", h.DeleteTag)
+
+	workspaceGroup.GET("/rules", h.GetRules)
+	workspaceGroup.POST("/rules", h.CreateRule)
+	workspaceGroup.POST("/rules/run", h.RunRules)
+	workspaceGroup.GET("/rules/suggestions", h.GetRuleSuggestions)
+	workspaceGroup.PUT("/rules/:ruleId", h.UpdateRule)
+	workspaceGroup.DELETE("/rules/:ruleId", h.DeleteRule)
+
+	workspaceGroup.GET("/payees", h.GetPayees)
+	workspaceGroup.POST("/payees", h.CreatePayee)
+	workspaceGroup.PUT("/payees/:payeeId", h.UpdatePayee)
+	workspaceGroup.DELETE("/payees/:payeeId", h.DeletePayee)
+
+	workspaceGroup.GET("/reports/categories", h.GetCategoryReport)
+	workspaceGroup.GET("/reports/tags", h.GetTagReport)
+	workspaceGroup.GET("/reports/payees", h.GetPayeeReport)
+	workspaceGroup.POST("/reports", h.GenerateReport)
+
+	workspaceGroup.GET("/jobs/:jobId", h.GetJob)
+
+	workspaceGroup.GET("/webhooks", h.GetWebhooks)
+	workspaceGroup.POST("/webhooks", h.CreateWebhook)
+	workspaceGroup.DELETE("/webhooks/:webhookId", h.DeleteWebhook)
+	workspaceGroup.GET("/webhooks/:webhookId/deliveries", h.GetWebhookDeliveries)
+	workspaceGroup.POST("/webhooks/:webhookId/deliveries/:deliveryId/replay", h.ReplayWebhookDelivery)
+
+	uploadGroup := c.Router.Group("/workspaces/:workspaceId",
+		middleware.Timeout(c.UploadTimeout),
+		// room for the multipart headers around the file
+		middleware.BodyLimit(c.MaxUploadBytes+multipartOverhead),
+		middleware.AuthUser(c.Logger),
+		middleware.Workspace(workspaceService),
+	)
+	uploadGroup.POST("/transactions/:transactionId/attachments", h.UploadAttachment)
+}
+
+// SetProbeRoutes registers the liveness and readiness probes
+func SetProbeRoutes(c *Config) {
+	h := &handler.Handler{
+		Logger:        c.Logger,
+		HealthService: c.Health,
+	}
+
+	c.Router.GET("/healthz", h.GetHealthz)
+	c.Router.GET("/readyz", h.GetReadyz)
 }
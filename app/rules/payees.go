@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Payee is a compiled payee. The titles of its transactions contain the
+// words of its name or match one of its aliases, e.g. "AMZN MKTP US*2K4"
+// and "Amazon.com" both belong to "Amazon" with the alias "^amzn".
+type Payee struct {
+	ID         uuid.UUID
+	Name       *regexp.Regexp
+	Aliases    []*regexp.Regexp
+	CategoryID uuid.NullUUID
+}
+
+// NamePattern compiles the pattern matching the titles containing the words
+// of a payee name, whatever is between them
+func NamePattern(name string) *regexp.Regexp {
+	ws := words(name)
+	if len(ws) == 0 {
+		return regexp.MustCompile("(?i)" + regexp.QuoteMeta(name))
+	}
+
+	return regexp.MustCompile(`(?i)(^|\P{L})` + wordsPattern(ws) + `(\P{L}|$)`)
+}
+
+// Match reports whether title belongs to the payee
+func (p *Payee) Match(title string) bool {
+	if p.Name != nil && p.Name.MatchString(title) {
+		return true
+	}
+	for _, alias := range p.Aliases {
+		if alias.MatchString(title) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindPayee returns the first of the payees title belongs to, nil if none
+func FindPayee(payees []*Payee, title string) *Payee {
+	for _, p := range payees {
+		if p.Match(title) {
+			return p
+		}
+	}
+
+	return nil
+}
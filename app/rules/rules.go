@@ -0,0 +1,144 @@
+// Package rules categorizes transactions with the rules of their workspace
+// and suggests new rules from the categories users corrected by hand.
+//
+// A rule matches the transactions meeting all of its conditions: title and
+// note patterns, a range of values and an account. Rules run in order and
+// every rule matching a transaction applies its actions to it, so later
+// rules see the title the earlier ones set. The first rule setting the
+// category or the title wins, the tags of all of them are added.
+package rules
+
+import (
+	"math/big"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// MaxPatternLength is the max length of a title or note pattern
+const MaxPatternLength = 200
+
+// Transaction is what the rules match and change
+type Transaction struct {
+	Title      string
+	Note       string
+	Value      *big.Rat
+	AccountID  uuid.UUID
+	CategoryID uuid.UUID
+	Tags       []string
+}
+
+// Rule is a compiled rule. Nil and zero conditions match every transaction,
+// zero actions change nothing.
+type Rule struct {
+	ID uuid.UUID
+
+	Title     *regexp.Regexp
+	Note      *regexp.Regexp
+	MinValue  *big.Rat
+	MaxValue  *big.Rat
+	AccountID uuid.NullUUID
+
+	CategoryID uuid.NullUUID
+	Tags       []string
+	Rename     string
+}
+
+// Pattern compiles a title or note pattern. Patterns are Go regular
+// expressions and match regardless of case.
+func Pattern(expr string) (*regexp.Regexp, error) {
+	if len(expr) > MaxPatternLength {
+		return nil, &syntax.Error{Code: syntax.ErrLarge, Expr: expr}
+	}
+
+	return regexp.Compile("(?i)" + expr)
+}
+
+// Match reports whether t meets all the conditions of the rule
+func (r *Rule) Match(t *Transaction) bool {
+	if r.Title != nil && !r.Title.MatchString(t.Title) {
+		return false
+	}
+	if r.Note != nil && !r.Note.MatchString(t.Note) {
+		return false
+	}
+	if r.MinValue != nil && (t.Value == nil || t.Value.Cmp(r.MinValue) < 0) {
+		return false
+	}
+	if r.MaxValue != nil && (t.Value == nil || t.Value.Cmp(r.MaxValue) > 0) {
+		return false
+	}
+	if r.AccountID.Valid && r.AccountID.UUID != t.AccountID {
+		return false
+	}
+
+	return true
+}
+
+// Apply runs the rules on t in order and returns the ids of the ones that
+// matched it
+func Apply(rules []*Rule, t *Transaction) []uuid.UUID {
+	var matched []uuid.UUID
+	var categorySet, titleSet bool
+
+	for _, r := range rules {
+		if !r.Match(t) {
+			continue
+		}
+		matched = append(matched, r.ID)
+
+		if r.CategoryID.Valid && !categorySet {
+			t.CategoryID = r.CategoryID.UUID
+			categorySet = true
+		}
+		if r.Rename != "" && !titleSet {
+			t.Title = r.Rename
+			titleSet = true
+		}
+		t.Tags = addTags(t.Tags, r.Tags)
+	}
+
+	return matched
+}
+
+// addTags appends the tags missing from tags, names differing only in case
+// are the same tag
+func addTags(tags []string, add []string) []string {
+	for _, name := range add {
+		found := false
+		for _, tag := range tags {
+			if strings.EqualFold(tag, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, name)
+		}
+	}
+
+	return tags
+}
+
+// words returns the words of a title in lower case. Numbers and symbols
+// aren't words, they change from one transaction to the next, e.g. in
+// "UBER *TRIP 4411".
+func words(title string) []string {
+	return strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// wordsPattern returns a pattern matching the words in a row, whatever is
+// between them
+func wordsPattern(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, w := range words {
+		quoted = append(quoted, regexp.QuoteMeta(w))
+	}
+
+	return strings.Join(quoted, `\P{L}+`)
+}
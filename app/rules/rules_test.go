@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pattern(t *testing.T, expr string) *regexp.Regexp {
+	re, err := Pattern(expr)
+	require.NoError(t, err)
+	return re
+}
+
+func value(s string) *big.Rat {
+	r, _ := new(big.Rat).SetString(s)
+	return r
+}
+
+func TestPattern(t *testing.T) {
+	re := pattern(t, `^uber\s+trip`)
+	assert.True(t, re.MatchString("UBER Trip 4411"))
+	assert.False(t, re.MatchString("Paypal uber trip"))
+
+	_, err := Pattern("(unclosed")
+	assert.Error(t, err)
+
+	_, err = Pattern(strings.Repeat("a", MaxPatternLength+1))
+	assert.Error(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	account := uuid.New()
+	r := &Rule{
+		Title:     pattern(t, "coffee"),
+		Note:      pattern(t, "work"),
+		MinValue:  value("-10"),
+		MaxValue:  value("-2.50"),
+		AccountID: uuid.NullUUID{UUID: account, Valid: true},
+	}
+	tx := Transaction{Title: "Coffee shop", Note: "With work people", Value: value("-4.20"), AccountID: account}
+
+	assert.True(t, r.Match(&tx))
+
+	tests := map[string]func(t *Transaction){
+		"title":   func(t *Transaction) { t.Title = "Tea shop" },
+		"note":    func(t *Transaction) { t.Note = "" },
+		"min":     func(t *Transaction) { t.Value = value("-10.01") },
+		"max":     func(t *Transaction) { t.Value = value("-2.49") },
+		"account": func(t *Transaction) { t.AccountID = uuid.New() },
+	}
+	for name, change := range tests {
+		other := tx
+		change(&other)
+		assert.False(t, r.Match(&other), name)
+	}
+
+	assert.True(t, (&Rule{}).Match(&Transaction{}), "no conditions")
+}
+
+func TestApply(t *testing.T) {
+	food, transport := uuid.New(), uuid.New()
+	rules := []*Rule{
+		{ID: uuid.New(), Title: pattern(t, "^uber eats"), CategoryID: uuid.NullUUID{UUID: food, Valid: true}, Tags: []string{"Delivery"}},
+		{ID: uuid.New(), Title: pattern(t, "^uber"), CategoryID: uuid.NullUUID{UUID: transport, Valid: true}, Rename: "Uber", Tags: []string{"uber", "delivery"}},
+		{ID: uuid.New(), Title: pattern(t, "^uber$"), Rename: "Uber ride", Tags: []string{"Renamed"}},
+		{ID: uuid.New(), Title: pattern(t, "^lyft"), CategoryID: uuid.NullUUID{UUID: transport, Valid: true}},
+	}
+
+	tx := Transaction{Title: "UBER EATS 1234", Tags: []string{"Work"}}
+	matched := Apply(rules, &tx)
+
+	assert.Equal(t, []uuid.UUID{rules[0].ID, rules[1].ID, rules[2].ID}, matched)
+	assert.Equal(t, food, tx.CategoryID, "the first category wins")
+	assert.Equal(t, "Uber", tx.Title, "the first title wins")
+	assert.Equal(t, []string{"Work", "Delivery", "uber", "Renamed"}, tx.Tags)
+
+	tx = Transaction{Title: "Bakery"}
+	assert.Empty(t, Apply(rules, &tx))
+	assert.Equal(t, uuid.Nil, tx.CategoryID)
+}
+
+func TestSuggest(t *testing.T) {
+	food, transport := uuid.New(), uuid.New()
+	corrections := []Correction{
+		{Title: "UBER *TRIP 4411", CategoryID: transport},
+		{Title: "Uber Trip help.uber.com", CategoryID: transport},
+		{Title: "uber trip 12", CategoryID: transport},
+		{Title: "Uber Trip help.uber.com", CategoryID: transport},
+		{Title: "Uber Eats", CategoryID: food},
+		{Title: "Bakery Joe", CategoryID: food},
+		{Title: "Bakery Ann", CategoryID: food},
+		{Title: "1234", CategoryID: food},
+	}
+
+	suggestions := Suggest(corrections, nil, 2)
+	require.Len(t, suggestions, 2)
+
+	uber := suggestions[0]
+	assert.Equal(t, "uber trip", uber.Name)
+	assert.Equal(t, `uber\P{L}+trip`, uber.TitlePattern)
+	assert.Equal(t, transport, uber.SetCategoryID)
+	assert.Equal(t, 4, uber.Corrections)
+	assert.Equal(t, []string{"UBER *TRIP 4411", "Uber Trip help.uber.com", "uber trip 12"}, uber.Examples)
+
+	re := pattern(t, uber.TitlePattern)
+	for _, c := range corrections[:4] {
+		assert.True(t, re.MatchString(c.Title), c.Title)
+	}
+
+	assert.Equal(t, "bakery", suggestions[1].Name)
+	assert.Equal(t, food, suggestions[1].SetCategoryID)
+
+	assert.Empty(t, Suggest(corrections, nil, 5))
+
+	// a rule already categorizes the trips
+	rules := []*Rule{{Title: pattern(t, "uber"), CategoryID: uuid.NullUUID{UUID: transport, Valid: true}}}
+	suggestions = Suggest(corrections, rules, 2)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "bakery", suggestions[0].Name)
+}
+
+func TestFindPayee(t *testing.T) {
+	shopping := uuid.New()
+	amazon := &Payee{
+		ID:         uuid.New(),
+		Name:       NamePattern("Amazon"),
+		Aliases:    []*regexp.Regexp{pattern(t, `^amzn\b`)},
+		CategoryID: uuid.NullUUID{UUID: shopping, Valid: true},
+	}
+	joe := &Payee{ID: uuid.New(), Name: NamePattern("Bakery Joe")}
+	payees := []*Payee{amazon, joe}
+
+	for _, title := range []string{"AMZN MKTP US*2K4", "Amazon.com", "amazon prime"} {
+		assert.Equal(t, amazon, FindPayee(payees, title), title)
+	}
+	assert.Equal(t, joe, FindPayee(payees, "BAKERY  JOE 12"))
+
+	assert.Nil(t, FindPayee(payees, "Amazonas Cafe"), "part of a word")
+	assert.Nil(t, FindPayee(payees, "Bakery Ann"))
+	assert.Nil(t, FindPayee(payees, "Paypal amzn"))
+
+	assert.True(t, NamePattern("7-11").MatchString("STORE 7-11 #4"), "no words")
+}
@@ -0,0 +1,143 @@
+package rules
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MinCorrections is how many transactions users correct alike before a rule
+// is suggested by default
+const MinCorrections = 3
+
+// MaxExamples is the number of titles of a suggestion
+const MaxExamples = 3
+
+// Correction is the category a user gave to a transaction by hand
+type Correction struct {
+	Title      string
+	CategoryID uuid.UUID
+}
+
+// Suggestion is a rule setting the category of the transactions with titles
+// like the ones users corrected
+type Suggestion struct {
+	Name          string    `json:"name"`
+	TitlePattern  string    `json:"title_pattern"`
+	SetCategoryID uuid.UUID `json:"set_category_id"`
+	// Number of corrections the rule would have made
+	Corrections int `json:"corrections"`
+	// Titles of the corrected transactions
+	Examples []string `json:"examples"`
+}
+
+type group struct {
+	words      []string
+	categoryID uuid.UUID
+	titles     []string
+}
+
+// Suggest groups the corrections by the first word of their titles and
+// their category, and suggests a rule for the groups of at least min. The
+// pattern of a rule matches the words all the titles of its group start
+// with. Groups the rules already categorize are left out. The most
+// corrected come first.
+func Suggest(corrections []Correction, rules []*Rule, min int) []*Suggestion {
+	groups := map[string]*group{}
+	var keys []string
+
+	for _, c := range corrections {
+		ws := words(c.Title)
+		if len(ws) == 0 {
+			continue
+		}
+
+		key := ws[0] + " " + c.CategoryID.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &group{words: ws, categoryID: c.CategoryID}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.words = commonPrefix(g.words, ws)
+		g.titles = append(g.titles, c.Title)
+	}
+
+	suggestions := []*Suggestion{}
+	for _, key := range keys {
+		g := groups[key]
+		if len(g.titles) < min || categorized(rules, g) {
+			continue
+		}
+
+		suggestions = append(suggestions, &Suggestion{
+			Name:          strings.Join(g.words, " "),
+			TitlePattern:  wordsPattern(g.words),
+			SetCategoryID: g.categoryID,
+			Corrections:   len(g.titles),
+			Examples:      examples(g.titles),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Corrections > suggestions[j].Corrections
+	})
+
+	return suggestions
+}
+
+// categorized reports whether a rule sets the category of the group to all
+// of its titles
+func categorized(rules []*Rule, g *group) bool {
+	for _, r := range rules {
+		if !r.CategoryID.Valid || r.CategoryID.UUID != g.categoryID || r.Title == nil {
+			continue
+		}
+
+		all := true
+		for _, title := range g.titles {
+			if !r.Title.MatchString(title) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+
+	return false
+}
+
+func commonPrefix(a, b []string) []string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	return a[:n]
+}
+
+// examples returns the first distinct titles
+func examples(titles []string) []string {
+	out := []string{}
+	for _, title := range titles {
+		if len(out) == MaxExamples {
+			break
+		}
+
+		found := false
+		for _, t := range out {
+			if t == title {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, title)
+		}
+	}
+
+	return out
+}
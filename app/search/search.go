@@ -0,0 +1,45 @@
+// Package search turns what users type in a search box into Postgres text
+// search queries and renders the snippets Postgres highlights.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// MaxTerms is the number of terms of a query, the rest are ignored
+const MaxTerms = 10
+
+// The markers ts_headline puts around matches, see SearchTransactions. They
+// are private use characters, U+E000 and U+E001, so they are not in the
+// text being highlighted and survive HTML escaping.
+const (
+	StartSel = "\ue000"
+	StopSel  = "\ue001"
+)
+
+// Query returns a to_tsquery query matching all the words of q as prefixes,
+// e.g. "coffee sh" matches "Coffee shop". It is empty when q has no words.
+func Query(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > MaxTerms {
+		words = words[:MaxTerms]
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// Highlight escapes a snippet as HTML and wraps its matches in <mark> tags
+func Highlight(snippet string) string {
+	s := html.EscapeString(snippet)
+	s = strings.ReplaceAll(s, StartSel, "<mark>")
+	return strings.ReplaceAll(s, StopSel, "</mark>")
+}
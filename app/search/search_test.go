@@ -0,0 +1,34 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	tests := map[string]string{
+		"coffee":              "coffee:*",
+		"  Coffee   Shop ":    "coffee:* & shop:*",
+		"café & (bar) | !x:*": "café:* & bar:* & x:*",
+		"O'Reilly":            "o:* & reilly:*",
+		"":                    "",
+		"!&|()":               "",
+	}
+
+	for q, want := range tests {
+		assert.Equal(t, want, Query(q), q)
+	}
+}
+
+func TestQueryMaxTerms(t *testing.T) {
+	q := Query(strings.Repeat("a ", MaxTerms+5))
+	assert.Equal(t, MaxTerms, strings.Count(q, ":*"))
+}
+
+func TestHighlight(t *testing.T) {
+	snippet := "Lunch at " + StartSel + "Joe" + StopSel + "'s <b>" + StartSel + "diner" + StopSel + "</b>"
+
+	assert.Equal(t, "Lunch at <mark>Joe</mark>&#39;s &lt;b&gt;<mark>diner</mark>&lt;/b&gt;", Highlight(snippet))
+}
@@ -2,20 +2,37 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"runtime/debug"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/gin-contrib/sessions"
 	sessionRedis "github.com/gin-contrib/sessions/redis"
-	"github.com/gin-gonic/contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/handler"
+	"github.com/opchaves/gin-web-app/app/handler/middleware"
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/opchaves/gin-web-app/app/jobs"
+	"github.com/opchaves/gin-web-app/app/logging"
+	"github.com/opchaves/gin-web-app/app/mail"
+	"github.com/opchaves/gin-web-app/app/metrics"
 	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/outbox"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/app/storage"
+	"github.com/opchaves/gin-web-app/app/tracing"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+	migrations "github.com/opchaves/gin-web-app/db"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"github.com/ulule/limiter/v3"
 	mgin "github.com/ulule/limiter/v3/drivers/middleware/gin"
@@ -34,36 +51,104 @@ type Config struct {
 	RedisClient     *redis.Client
 	Logger          *slog.Logger
 	Router          *gin.Engine
+	Metrics         *metrics.Metrics
+	Health          service.HealthService
+	MailService     service.MailService
+	WebhookService  service.WebhookService
+	JobRunService   service.JobRunService
+	Jobs            *jobs.Queue
+	Worker          *jobs.Worker
+	Outbox          *outbox.Relay
+	Storage         storage.Storage
+	I18n            *i18n.Catalog
 	TimeoutDuration time.Duration
 	MaxBodyBytes    int64
+	UploadTimeout   time.Duration
+	MaxUploadBytes  int64
+	// ShutdownTracing flushes pending spans
+	ShutdownTracing func(context.Context) error
 }
 
+// Deps are the connections and services New creates from the config unless
+// they are given, e.g. by tests swapping in their own
+type Deps struct {
+	Db            *pgxpool.Pool
+	RedisClient   *redis.Client
+	MailTransport mail.Transport
+}
+
+// Setup loads the config from the environment and builds the app
 func Setup() (*Config, error) {
 	ctx := context.Background()
 	cfg, logger, err := initialize(ctx)
 
 	if err != nil {
-		logger.Error("failed to initialize", slog.AnyValue(err))
+		slog.Error("failed to initialize", slog.Any("error", err))
 		return nil, err
 	}
 
-	logger.Debug("Connecting to database...")
+	return New(ctx, cfg, logger, &Deps{})
+}
 
-	db, err := pgxpool.New(ctx, cfg.DatabaseUrl)
+// New builds the app from the given config and dependencies
+func New(ctx context.Context, cfg *config.Config, logger *slog.Logger, deps *Deps) (*Config, error) {
+	shutdownTracing, err := tracing.Setup(ctx, &tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		ServiceName: cfg.TracingServiceName,
+		Version:     Version,
+		SampleRatio: cfg.TracingSampleRatio,
+		Writer:      os.Stdout,
+	})
 	if err != nil {
-		logger.Error("failed to connect to database", slog.String("error", err.Error()))
+		logger.Error("failed to set up tracing", slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	// Initialize redis connection
-	opt, err := redis.ParseURL(cfg.RedisUrl)
-	if err != nil {
-		logger.Error("error parsing the redis url", slog.String("error", err.Error()))
-		return nil, err
+	if cfg.AutoMigrate {
+		logger.Info("Running migrations...")
+
+		lockTimeout := time.Duration(cfg.MigrateLockTimeout) * time.Second
+		if err := migrations.AutoMigrate(cfg.DatabaseUrl, lockTimeout, logger); err != nil {
+			logger.Error("failed to migrate database", slog.String("error", err.Error()))
+			return nil, err
+		}
 	}
 
-	logger.Debug("Connecting to redis...")
-	rdb := redis.NewClient(opt)
+	db := deps.Db
+	if db == nil {
+		logger.Debug("Connecting to database...")
+
+		poolConfig, err := pgxpool.ParseConfig(cfg.DatabaseUrl)
+		if err != nil {
+			logger.Error("invalid database url", slog.String("error", err.Error()))
+			return nil, err
+		}
+		poolConfig.ConnConfig.Tracer = otelpgx.NewTracer()
+
+		db, err = pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			logger.Error("failed to connect to database", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+
+	rdb := deps.RedisClient
+	if rdb == nil {
+		// Initialize redis connection
+		opt, err := redis.ParseURL(cfg.RedisUrl)
+		if err != nil {
+			logger.Error("error parsing the redis url", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		logger.Debug("Connecting to redis...")
+		rdb = redis.NewClient(opt)
+
+		if err := redisotel.InstrumentTracing(rdb); err != nil {
+			logger.Error("failed to instrument redis", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
 
 	// verify redis connection
 	_, err = rdb.Ping(ctx).Result()
@@ -73,9 +158,106 @@ func Setup() (*Config, error) {
 		return nil, err
 	}
 
-	corsConfig := cors.DefaultConfig()
-	router := gin.Default()
-	router.Use(cors.New(corsConfig))
+	corsMiddleware, err := middleware.CORS(cfg, logger)
+	if err != nil {
+		logger.Error("invalid cors config", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	appMetrics := metrics.New(db, rdb)
+
+	queue := jobs.NewQueue(rdb)
+	worker := jobs.NewWorker(&jobs.WorkerConfig{
+		Redis:        rdb,
+		Logger:       logger,
+		Queues:       cfg.JobQueues,
+		Concurrency:  cfg.JobConcurrency,
+		Lease:        time.Duration(cfg.JobLease) * time.Second,
+		DrainTimeout: time.Duration(cfg.JobDrainTimeout) * time.Second,
+		Metrics:      appMetrics,
+	})
+
+	relay := outbox.NewRelay(&outbox.RelayConfig{
+		Db:           db,
+		Logger:       logger,
+		BatchSize:    cfg.OutboxBatchSize,
+		PollInterval: time.Duration(cfg.OutboxPollInterval) * time.Millisecond,
+		Retention:    time.Duration(cfg.OutboxRetention) * time.Hour,
+		Metrics:      appMetrics,
+	})
+
+	mailTransport := deps.MailTransport
+	if mailTransport == nil {
+		mailTransport, err = NewMailTransport(cfg, logger)
+		if err != nil {
+			logger.Error("invalid mail config", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+
+	files, err := NewStorage(cfg)
+	if err != nil {
+		logger.Error("invalid storage config", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	catalog, err := i18n.Load(&i18n.Config{
+		Default: cfg.DefaultLanguage,
+		Dir:     cfg.LocalesDir,
+	})
+	if err != nil {
+		logger.Error("failed to load translations", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	mailTemplates, err := mail.LoadTemplates()
+	if err != nil {
+		logger.Error("failed to load email templates", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	mailService := service.NewMailService(&service.MailConfig{
+		Transport: mailTransport,
+		Queue:     queue,
+		Templates: mailTemplates,
+		From:      cfg.MailFrom,
+		BaseURL:   cfg.PublicURL,
+		Logger:    logger,
+		Metrics:   appMetrics,
+	})
+
+	webhookService := service.NewWebhookService(&service.WHConfig{
+		Db:     db,
+		Q:      model.New(db),
+		Logger: logger,
+		Queue:  queue,
+		Sender: webhooks.NewSender(time.Duration(cfg.WebhookTimeout)*time.Second, "Kommonei-Webhooks/"+Version, cfg.WebhookAllowPrivate),
+	})
+
+	jobRunService := service.NewJobRunService(&service.JRConfig{
+		Db:     db,
+		Q:      model.New(db),
+		Logger: logger,
+		Queue:  queue,
+	})
+
+	router := gin.New()
+	// client addresses go in the audit log, X-Forwarded-For is only read
+	// from the proxies in front of the app
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	// the tracing middleware goes first so the access log has the trace id
+	router.Use(tracing.Middleware(cfg.TracingServiceName))
+	router.Use(middleware.RequestID(), middleware.AuditActor(), middleware.Logger(logger), middleware.Recovery())
+	router.Use(middleware.Metrics(appMetrics))
+	// renders the errors handlers add with c.Error, inside the logger and
+	// metrics so they see the final status
+	router.Use(middleware.Errors(logger))
+	// let handlers pass gin.Context as a context.Context that gets cancelled
+	// with the request, e.g. by the timeout middleware
+	router.ContextWithFallback = true
+	router.Use(corsMiddleware)
 	router.LoadHTMLGlob(cfg.TemplatesGlob)
 	router.Static("/assets", cfg.AssetsDir)
 
@@ -84,12 +266,48 @@ func Setup() (*Config, error) {
 		Cfg:             cfg,
 		Logger:          logger,
 		Router:          router,
+		Metrics:         appMetrics,
+		MailService:     mailService,
+		WebhookService:  webhookService,
+		JobRunService:   jobRunService,
+		Jobs:            queue,
+		Worker:          worker,
+		Outbox:          relay,
+		Storage:         files,
+		I18n:            catalog,
+		ShutdownTracing: shutdownTracing,
 		RedisClient:     rdb,
 		Ctx:             ctx,
 		TimeoutDuration: time.Duration(cfg.HandlerTimeOut) * time.Second,
 		MaxBodyBytes:    cfg.MaxBodyBytes,
+		UploadTimeout:   time.Duration(cfg.UploadTimeout) * time.Second,
+		MaxUploadBytes:  cfg.UploadMaxBytes,
 	}
 
+	migrationVersion, err := migrations.LatestVersion()
+	if err != nil {
+		logger.Error("failed to read migrations", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	smtpAddr := ""
+	if cfg.HealthCheckSMTP {
+		smtpAddr = net.JoinHostPort(cfg.MailHost, cfg.MailPort)
+	}
+
+	config.Health = service.NewHealthService(&service.HSConfig{
+		Logger:           logger,
+		Db:               db,
+		Redis:            rdb,
+		MigrationVersion: migrationVersion,
+		SMTPAddr:         smtpAddr,
+		Timeout:          time.Duration(cfg.HealthCheckTimeout) * time.Second,
+	})
+
+	// Probes are registered before the session, CSRF and rate limit
+	// middlewares so they don't go through them
+	SetProbeRoutes(config)
+
 	redisURL := rdb.Options().Addr
 	password := rdb.Options().Password
 
@@ -111,6 +329,8 @@ func Setup() (*Config, error) {
 	})
 
 	router.Use(sessions.Sessions(model.CookieName, store))
+	router.Use(middleware.Language(catalog))
+	router.Use(middleware.CSRF(logger))
 
 	// add rate limit
 	rate := limiter.Rate{
@@ -120,33 +340,99 @@ func Setup() (*Config, error) {
 
 	limitStore, _ := sredis.NewStore(rdb)
 
-	rateLimiter := mgin.NewMiddleware(limiter.New(limitStore, rate))
+	rateLimiter := mgin.NewMiddleware(limiter.New(limitStore, rate),
+		mgin.WithLimitReachedHandler(func(c *gin.Context) {
+			appMetrics.RateLimited()
+			c.Error(apperrors.NewTooManyRequests())
+		}),
+		mgin.WithErrorHandler(func(c *gin.Context, err error) {
+			c.Error(err)
+		}),
+	)
 	router.Use(rateLimiter)
 
+	if err := handler.RegisterValidations(); err != nil {
+		return nil, err
+	}
+
 	SetRoutes(config)
+	SetJobs(config)
+	SetEvents(config)
 
 	return config, nil
 }
 
-func initialize(ctx context.Context) (*config.Config, *slog.Logger, error) {
-	handler := slog.NewJSONHandler(os.Stdout, nil)
-	buildInfo, _ := debug.ReadBuildInfo()
-	logger := slog.New(handler).WithGroup("program_info")
-	loggerChild := logger.With(
-		slog.Int("pid", os.Getpid()),
-		slog.String("version", Version),
-		slog.String("go_version", buildInfo.GoVersion),
-	)
+// NewMailTransport returns the transport of the mailer set in the config
+func NewMailTransport(cfg *config.Config, logger *slog.Logger) (mail.Transport, error) {
+	switch cfg.MailMailer {
+	case "smtp":
+		return mail.NewSMTP(&mail.SMTPConfig{
+			Host:       cfg.MailHost,
+			Port:       cfg.MailPort,
+			Username:   cfg.MailUsername,
+			Password:   cfg.MailPassword,
+			Encryption: cfg.MailEncryption,
+		})
+	case "log":
+		return &mail.Log{Logger: logger, Dir: cfg.MailDir}, nil
+	case "memory":
+		return &mail.Memory{}, nil
+	default:
+		return nil, fmt.Errorf("invalid mailer %q", cfg.MailMailer)
+	}
+}
+
+// NewStorage returns the storage of the driver set in the config
+func NewStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		secret := cfg.StorageSecret
+		if secret == "" {
+			secret = cfg.SessionSecret
+		}
+		return storage.NewLocal(&storage.LocalConfig{
+			Dir:     cfg.StorageDir,
+			BaseURL: cfg.PublicURL,
+			Secret:  secret,
+		}), nil
+	case "s3":
+		return storage.NewS3(&storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("invalid storage driver %q", cfg.StorageDriver)
+	}
+}
 
+func initialize(ctx context.Context) (*config.Config, *slog.Logger, error) {
 	if gin.Mode() != gin.ReleaseMode {
 		err := godotenv.Load()
 		if err != nil {
-			loggerChild.Error("Error loading .env file", slog.AnyValue(err))
-			return nil, nil, err
+			return nil, nil, fmt.Errorf("error loading .env file: %w", err)
 		}
 	}
 
 	cfg, err := config.LoadConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buildInfo, _ := debug.ReadBuildInfo()
+	loggerChild := logger.With(slog.Group("program_info",
+		slog.Int("pid", os.Getpid()),
+		slog.String("version", Version),
+		slog.String("go_version", buildInfo.GoVersion),
+	))
 
-	return &cfg, loggerChild, err
+	return &cfg, loggerChild, nil
 }
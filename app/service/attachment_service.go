@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/attachments"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/storage"
+)
+
+// AttachmentResponse has signed URLs to download the file and its thumbnail
+// instead of where they are stored
+type AttachmentResponse struct {
+	*model.Attachment
+	StorageKey   bool      `json:"storage_key,omitempty"`
+	ThumbnailKey bool      `json:"thumbnail_key,omitempty"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+} //@name AttachmentResponse
+
+type AttachmentService interface {
+	List(ctx context.Context, workspaceId uuid.UUID, transactionId string) ([]*AttachmentResponse, error)
+	Get(ctx context.Context, workspaceId uuid.UUID, transactionId string, id string) (*AttachmentResponse, error)
+	// Upload stores the file, checking its size and content type, with a
+	// thumbnail when it is an image
+	Upload(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, transactionId string, file *multipart.FileHeader) (*AttachmentResponse, error)
+	Delete(ctx context.Context, workspaceId uuid.UUID, transactionId string, id string) error
+}
+
+type attachmentService struct {
+	Q             *model.Queries
+	Logger        *slog.Logger
+	Db            *pgxpool.Pool
+	Storage       storage.Storage
+	MaxBytes      int64
+	ThumbnailSize int
+	URLExpiry     time.Duration
+}
+
+type AttachmentConfig struct {
+	Q       *model.Queries
+	Logger  *slog.Logger
+	Db      *pgxpool.Pool
+	Storage storage.Storage
+	// MaxBytes is the max size of a file
+	MaxBytes int64
+	// ThumbnailSize is the max width and height of the thumbnails
+	ThumbnailSize int
+	// URLExpiry is how long the download URLs work
+	URLExpiry time.Duration
+}
+
+func NewAttachmentService(c *AttachmentConfig) AttachmentService {
+	return &attachmentService{
+		Q:             c.Q,
+		Logger:        c.Logger,
+		Db:            c.Db,
+		Storage:       c.Storage,
+		MaxBytes:      c.MaxBytes,
+		ThumbnailSize: c.ThumbnailSize,
+		URLExpiry:     c.URLExpiry,
+	}
+}
+
+// List implements AttachmentService.
+func (s *attachmentService) List(ctx context.Context, workspaceId uuid.UUID, transactionId string) ([]*AttachmentResponse, error) {
+	txId, err := workspaceTransaction(ctx, s.Q, workspaceId, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.Q.GetTransactionAttachments(ctx, model.GetTransactionAttachmentsParams{
+		TransactionID: txId,
+		WorkspaceID:   workspaceId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*AttachmentResponse, 0, len(items))
+	for _, item := range items {
+		r, err := s.response(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+
+	return res, nil
+}
+
+// Get implements AttachmentService.
+func (s *attachmentService) Get(ctx context.Context, workspaceId uuid.UUID, transactionId string, id string) (*AttachmentResponse, error) {
+	txId, err := uuid.Parse(transactionId)
+	if err != nil {
+		return nil, apperrors.NewNotFound("transaction", transactionId)
+	}
+	attachmentId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apperrors.NewNotFound("attachment", id)
+	}
+
+	item, err := s.Q.GetTransactionAttachment(ctx, model.GetTransactionAttachmentParams{
+		ID:            attachmentId,
+		TransactionID: txId,
+		WorkspaceID:   workspaceId,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("attachment", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(ctx, item)
+}
+
+// Upload implements AttachmentService.
+func (s *attachmentService) Upload(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, transactionId string, file *multipart.FileHeader) (*AttachmentResponse, error) {
+	txId, err := workspaceTransaction(ctx, s.Q, workspaceId, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Size > s.MaxBytes {
+		return nil, apperrors.NewPayloadTooLarge(s.MaxBytes, file.Size)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, attachments.SniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	contentType, err := attachments.Detect(head[:n], file.Header.Get("Content-Type"), file.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	key := fmt.Sprintf("workspaces/%s/attachments/%s%s", workspaceId, id, attachments.ContentTypes[contentType])
+
+	var thumbnail []byte
+	if attachments.IsImage(contentType) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		thumbnail, err = attachments.Thumbnail(f, s.ThumbnailSize)
+		if errors.Is(err, attachments.ErrTooManyPixels) {
+			s.Logger.InfoContext(ctx, "image too large for a thumbnail", slog.String("attachment_id", id.String()))
+		} else if err != nil {
+			// it looked like an image but isn't one
+			return nil, apperrors.NewUnsupportedMediaType(apperrors.InvalidImageType)
+		}
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := s.Storage.Put(ctx, key, f, file.Size, contentType); err != nil {
+		return nil, err
+	}
+	stored := []string{key}
+
+	params := model.CreateAttachmentParams{
+		ID:            id,
+		TransactionID: txId,
+		WorkspaceID:   workspaceId,
+		UserID:        userId,
+		Filename:      attachments.Filename(file.Filename, "attachment"+attachments.ContentTypes[contentType]),
+		ContentType:   contentType,
+		Size:          file.Size,
+		StorageKey:    key,
+	}
+
+	if thumbnail != nil {
+		thumbKey := fmt.Sprintf("workspaces/%s/attachments/%s_thumb.jpg", workspaceId, id)
+		err := s.Storage.Put(ctx, thumbKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), attachments.ThumbnailType)
+		if err != nil {
+			s.remove(ctx, stored...)
+			return nil, err
+		}
+		stored = append(stored, thumbKey)
+		params.ThumbnailKey = pgtype.Text{String: thumbKey, Valid: true}
+	}
+
+	item, err := s.Q.CreateAttachment(ctx, params)
+	if err != nil {
+		s.remove(ctx, stored...)
+		return nil, err
+	}
+
+	return s.response(ctx, item)
+}
+
+// Delete implements AttachmentService.
+func (s *attachmentService) Delete(ctx context.Context, workspaceId uuid.UUID, transactionId string, id string) error {
+	txId, err := uuid.Parse(transactionId)
+	if err != nil {
+		return apperrors.NewNotFound("transaction", transactionId)
+	}
+	attachmentId, err := uuid.Parse(id)
+	if err != nil {
+		return apperrors.NewNotFound("attachment", id)
+	}
+
+	item, err := s.Q.DeleteAttachment(ctx, model.DeleteAttachmentParams{
+		ID:            attachmentId,
+		TransactionID: txId,
+		WorkspaceID:   workspaceId,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperrors.NewNotFound("attachment", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	keys := []string{item.StorageKey}
+	if item.ThumbnailKey.Valid {
+		keys = append(keys, item.ThumbnailKey.String)
+	}
+	s.remove(ctx, keys...)
+
+	return nil
+}
+
+func (s *attachmentService) response(ctx context.Context, item *model.Attachment) (*AttachmentResponse, error) {
+	res := &AttachmentResponse{
+		Attachment: item,
+		ExpiresAt:  time.Now().Add(s.URLExpiry).UTC(),
+	}
+
+	var err error
+	res.URL, err = s.Storage.SignedURL(ctx, item.StorageKey, s.URLExpiry, item.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.ThumbnailKey.Valid {
+		res.ThumbnailURL, err = s.Storage.SignedURL(ctx, item.ThumbnailKey.String, s.URLExpiry, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// remove deletes stored files, only logging the errors as the files are
+// not referenced anymore
+func (s *attachmentService) remove(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.Storage.Delete(ctx, key); err != nil {
+			s.Logger.WarnContext(ctx, "failed to delete attachment file",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
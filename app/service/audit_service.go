@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+type AuditLogsInput struct {
+	EntityType string `form:"entity_type" binding:"omitempty,oneof=account category payee rule tag transaction transaction_split user"`
+	EntityID   string `form:"entity_id" binding:"omitempty,uuid"`
+	Action     string `form:"action" binding:"omitempty,max=50"`
+	ActorID    string `form:"actor_id" binding:"omitempty,uuid"`
+	// RFC 3339 times, To is exclusive
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+	// Before is the next cursor of the previous page
+	Before int64 `form:"before" binding:"omitempty,min=1"`
+	// Max 100, defaults to 50
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type AuditLogsResponse struct {
+	Data []*model.AuditLog `json:"data"`
+	// Next is the before cursor of the next page, if any
+	Next *int64 `json:"next"`
+} //@name AuditLogsResponse
+
+type AuditService interface {
+	// List returns a page of the audit log of a workspace, newest first
+	List(ctx context.Context, workspaceId uuid.UUID, input *AuditLogsInput) (*AuditLogsResponse, error)
+}
+
+type auditService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+}
+
+func NewAuditService(c *ServiceConfig) AuditService {
+	return &auditService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+	}
+}
+
+// List implements AuditService.
+func (s *auditService) List(ctx context.Context, workspaceId uuid.UUID, input *AuditLogsInput) (*AuditLogsResponse, error) {
+	limit := input.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	filter := audit.Filter{
+		WorkspaceID: audit.ID(workspaceId),
+		EntityType:  input.EntityType,
+		Action:      input.Action,
+		From:        input.From,
+		To:          input.To,
+	}
+	if id, err := uuid.Parse(input.EntityID); err == nil {
+		filter.EntityID = audit.ID(id)
+	}
+	if id, err := uuid.Parse(input.ActorID); err == nil {
+		filter.ActorID = audit.ID(id)
+	}
+
+	entries, err := audit.List(ctx, s.Q, filter, input.Before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &AuditLogsResponse{Data: entries}
+	if len(entries) == limit {
+		res.Next = &entries[len(entries)-1].ID
+	}
+	if res.Data == nil {
+		res.Data = []*model.AuditLog{}
+	}
+
+	return res, nil
+}
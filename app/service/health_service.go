@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// Health statuses
+const (
+	HealthOK          = "ok"
+	HealthFailing     = "failing"
+	HealthUnavailable = "unavailable"
+	HealthDraining    = "draining"
+)
+
+// Errors of the failing checks. The probes aren't authenticated, so the
+// causes, which may name hosts and ports, are only logged.
+const (
+	HealthCheckFailed   = "check failed"
+	HealthCheckTimedOut = "check timed out"
+)
+
+// HealthCheck is the result of checking a single dependency
+type HealthCheck struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+} //@name HealthCheck
+
+// HealthReport holds the result of every readiness check
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+} //@name HealthReport
+
+type HealthService interface {
+	Ready(ctx context.Context) (*HealthReport, bool)
+	SetReady(ready bool)
+}
+
+type healthService struct {
+	Logger           *slog.Logger
+	Db               *pgxpool.Pool
+	Redis            *redis.Client
+	MigrationVersion uint
+	SMTPAddr         string
+	Timeout          time.Duration
+	ready            atomic.Bool
+}
+
+type HSConfig struct {
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+	Redis  *redis.Client
+	// MigrationVersion is the schema version the database must be at
+	MigrationVersion uint
+	// SMTPAddr is checked only when set
+	SMTPAddr string
+	// Timeout applies to each check
+	Timeout time.Duration
+}
+
+func NewHealthService(c *HSConfig) HealthService {
+	s := &healthService{
+		Logger:           c.Logger,
+		Db:               c.Db,
+		Redis:            c.Redis,
+		MigrationVersion: c.MigrationVersion,
+		SMTPAddr:         c.SMTPAddr,
+		Timeout:          c.Timeout,
+	}
+	s.ready.Store(true)
+
+	return s
+}
+
+// SetReady implements HealthService. Setting it to false makes Ready fail
+// without running the checks, so load balancers stop sending traffic.
+func (s *healthService) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Ready implements HealthService. It runs every check concurrently, each
+// with its own timeout.
+func (s *healthService) Ready(ctx context.Context) (*HealthReport, bool) {
+	if !s.ready.Load() {
+		return &HealthReport{Status: HealthDraining}, false
+	}
+
+	checks := map[string]func(ctx context.Context) error{
+		"database":   s.checkDatabase,
+		"redis":      s.checkRedis,
+		"migrations": s.checkMigrations,
+	}
+	if s.SMTPAddr != "" {
+		checks["smtp"] = s.checkSMTP
+	}
+
+	report := &HealthReport{
+		Status: HealthOK,
+		Checks: make(map[string]HealthCheck, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := runCheck(ctx, check)
+			result := HealthCheck{Status: HealthOK, Duration: time.Since(start).String()}
+
+			if err != nil {
+				s.Logger.WarnContext(ctx, "readiness check failed", slog.String("check", name), slog.String("error", err.Error()))
+				result.Status = HealthFailing
+				result.Error = HealthCheckFailed
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					result.Error = HealthCheckTimedOut
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = HealthUnavailable
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return report, report.Status == HealthOK
+}
+
+// runCheck returns when the check does or its context is done, whichever
+// comes first. Clients like redis only honour their own timeouts.
+func runCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- check(ctx)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *healthService) checkDatabase(ctx context.Context) error {
+	return s.Db.Ping(ctx)
+}
+
+func (s *healthService) checkRedis(ctx context.Context) error {
+	return s.Redis.Ping(ctx).Err()
+}
+
+func (s *healthService) checkMigrations(ctx context.Context) error {
+	var version int64
+	var dirty bool
+
+	err := s.Db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("database is dirty at version %d", version)
+	}
+
+	if version != int64(s.MigrationVersion) {
+		return fmt.Errorf("database is at version %d, expected %d", version, s.MigrationVersion)
+	}
+
+	return nil
+}
+
+func (s *healthService) checkSMTP(ctx context.Context) error {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", s.SMTPAddr)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// silentListener accepts connections and never answers, so the clients
+// wait until their context is done
+func silentListener(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var conns []net.Conn
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+
+	t.Cleanup(func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+
+	return ln.Addr().String()
+}
+
+func TestHealthReady(t *testing.T) {
+	addr := silentListener(t)
+
+	pool, err := pgxpool.New(context.Background(), "postgres://user:secret@"+addr+"/app")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	rdb := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	defer rdb.Close()
+
+	timeout := 200 * time.Millisecond
+	s := NewHealthService(&HSConfig{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Db:       pool,
+		Redis:    rdb,
+		SMTPAddr: addr,
+		Timeout:  timeout,
+	})
+
+	t.Run("Checks Time Out", func(t *testing.T) {
+		start := time.Now()
+		report, ok := s.Ready(context.Background())
+		elapsed := time.Since(start)
+
+		assert.False(t, ok)
+		assert.Equal(t, HealthUnavailable, report.Status)
+		// the checks run concurrently, each with its own timeout
+		assert.Less(t, elapsed, 3*timeout)
+
+		for _, name := range []string{"database", "redis", "migrations"} {
+			check := report.Checks[name]
+			assert.Equal(t, HealthFailing, check.Status, name)
+			assert.Equal(t, HealthCheckTimedOut, check.Error, name)
+		}
+		// the listener accepts connections
+		assert.Equal(t, HealthOK, report.Checks["smtp"].Status)
+	})
+
+	t.Run("Causes Aren't Shown", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		refused := ln.Addr().String()
+		ln.Close()
+
+		rdb := redis.NewClient(&redis.Options{Addr: refused, MaxRetries: -1})
+		defer rdb.Close()
+
+		s := NewHealthService(&HSConfig{
+			Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Db:       pool,
+			Redis:    rdb,
+			SMTPAddr: refused,
+			Timeout:  timeout,
+		})
+
+		report, ok := s.Ready(context.Background())
+		assert.False(t, ok)
+
+		for _, name := range []string{"redis", "smtp"} {
+			check := report.Checks[name]
+			assert.Equal(t, HealthFailing, check.Status, name)
+			assert.Equal(t, HealthCheckFailed, check.Error, name)
+		}
+		for name, check := range report.Checks {
+			assert.False(t, strings.Contains(check.Error, "127.0.0.1"), name)
+		}
+	})
+
+	t.Run("Draining", func(t *testing.T) {
+		s.SetReady(false)
+
+		start := time.Now()
+		report, ok := s.Ready(context.Background())
+		assert.False(t, ok)
+		assert.Equal(t, &HealthReport{Status: HealthDraining}, report)
+		assert.Less(t, time.Since(start), timeout, "the checks didn't run")
+
+		s.SetReady(true)
+		report, _ = s.Ready(context.Background())
+		assert.NotEmpty(t, report.Checks)
+	})
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/jobs"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+)
+
+// Job run kinds
+const (
+	// JobRunImport imports ImportTransactionsInput, its result is an
+	// ImportResult
+	JobRunImport = "import"
+	// JobRunReport generates ReportInput, its result are the rows of the
+	// report
+	JobRunReport = "report"
+)
+
+// Job run statuses
+const (
+	JobRunPending   = "pending"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// Types of the jobs running the job runs
+const (
+	JobImportTransactions = "import_transactions"
+	JobGenerateReport     = "generate_report"
+)
+
+// JobRunMessage is the job payload of a run, with the request that started
+// it for the audit log
+type JobRunMessage struct {
+	RunID     uuid.UUID `json:"run_id"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// JobRunError is how the error of a failed run is saved, with what
+// translates its message
+type JobRunError struct {
+	Type    apperrors.Type         `json:"type"`
+	Message string                 `json:"message"`
+	Fields  []apperrors.FieldError `json:"fields,omitempty"`
+	Key     string                 `json:"key,omitempty"`
+	Params  map[string]string      `json:"params,omitempty"`
+}
+
+// AppError returns the saved error as it was returned
+func (e *JobRunError) AppError() *apperrors.Error {
+	return &apperrors.Error{
+		Type:    e.Type,
+		Message: e.Message,
+		Fields:  e.Fields,
+		Key:     e.Key,
+		Params:  e.Params,
+	}
+}
+
+// JobRunResponse is a job run without its input
+type JobRunResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Kind        string    `json:"kind"`
+	Status      string    `json:"status"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	// The result of a succeeded run, see the kinds
+	Result json.RawMessage `json:"result,omitempty"`
+	// Why a run failed, e.g. the invalid fields of an import
+	Error      *model.HttpError `json:"error,omitempty"`
+	FinishedAt pgtype.Timestamp `json:"finished_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+} //@name JobRun
+
+type JobRunService interface {
+	// Start saves a run of the given kind and queues its job
+	Start(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, kind string, input any) (*model.JobRun, error)
+	// Get returns a run of the workspace
+	Get(ctx context.Context, workspaceId uuid.UUID, id string) (*model.JobRun, error)
+	// Run runs a pending run and saves its result with the changes it made.
+	// Errors of the input, like invalid fields, fail it. Other errors are
+	// returned to retry it, unless it is the last attempt.
+	Run(ctx context.Context, msg *JobRunMessage, lastAttempt bool) error
+}
+
+type jobRunService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+	Queue  *jobs.Queue
+}
+
+type JRConfig struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+	Queue  *jobs.Queue
+}
+
+func NewJobRunService(c *JRConfig) JobRunService {
+	return &jobRunService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+		Queue:  c.Queue,
+	}
+}
+
+// Start implements JobRunService.
+func (s *jobRunService) Start(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, kind string, input any) (*model.JobRun, error) {
+	queue, jobType, err := jobRunJob(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.Q.CreateJobRun(ctx, model.CreateJobRunParams{
+		Kind:        kind,
+		Input:       b,
+		UserID:      userId,
+		WorkspaceID: workspaceId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// queued once it's saved, so the job finds it
+	actor := audit.ActorFrom(ctx)
+	_, err = s.Queue.Enqueue(ctx, queue, jobType, JobRunMessage{
+		RunID:     run.ID,
+		IPAddress: actor.IPAddress,
+		RequestID: actor.RequestID,
+	})
+	if err != nil {
+		if err := s.finish(ctx, s.Q, run.ID, nil, apperrors.NewInternal()); err != nil {
+			s.Logger.ErrorContext(ctx, "failed to fail job run", slog.String("run_id", run.ID.String()), slog.String("error", err.Error()))
+		}
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// Get implements JobRunService.
+func (s *jobRunService) Get(ctx context.Context, workspaceId uuid.UUID, id string) (*model.JobRun, error) {
+	runId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apperrors.NewNotFound("job", id)
+	}
+
+	run, err := s.Q.GetWorkspaceJobRun(ctx, model.GetWorkspaceJobRunParams{
+		ID:          runId,
+		WorkspaceID: workspaceId,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("job", id)
+	}
+
+	return run, err
+}
+
+// Run implements JobRunService.
+func (s *jobRunService) Run(ctx context.Context, msg *JobRunMessage, lastAttempt bool) error {
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qTx := s.Q.WithTx(tx)
+
+	// the run stays locked until it's finished, so a job running twice waits
+	// and then finds it finished
+	run, err := qTx.LockPendingJobRun(ctx, msg.RunID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx = audit.WithUserID(audit.WithActor(ctx, audit.Actor{
+		IPAddress: msg.IPAddress,
+		RequestID: msg.RequestID,
+	}), run.UserID)
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return err
+	}
+
+	result, runErr := s.run(ctx, qTx, run)
+	if runErr == nil {
+		if err := s.finish(ctx, qTx, run.ID, result, nil); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	// nothing the run did is kept
+	if err := tx.Rollback(ctx); err != nil {
+		return err
+	}
+
+	e, ok := apperrors.As(runErr)
+	if !ok || e.Type == apperrors.Internal {
+		if !lastAttempt {
+			return runErr
+		}
+		s.Logger.ErrorContext(ctx, "job run failed",
+			slog.String("run_id", run.ID.String()),
+			slog.String("kind", run.Kind),
+			slog.String("error", runErr.Error()),
+		)
+		e = apperrors.NewInternal()
+	}
+
+	return s.finish(ctx, s.Q, run.ID, nil, e)
+}
+
+// run runs the run with the queries of its transaction and returns its
+// result
+func (s *jobRunService) run(ctx context.Context, q *model.Queries, run *model.JobRun) (any, error) {
+	switch run.Kind {
+	case JobRunImport:
+		var input ImportTransactionsInput
+		if err := json.Unmarshal(run.Input, &input); err != nil {
+			return nil, err
+		}
+
+		workspace, err := q.GetWorkspaceByID(ctx, run.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+
+		return importTransactions(ctx, q, workspace, run.UserID, &input)
+	case JobRunReport:
+		var input ReportInput
+		if err := json.Unmarshal(run.Input, &input); err != nil {
+			return nil, err
+		}
+
+		reports := &reportService{Q: q, Logger: s.Logger}
+		return reports.Generate(ctx, run.WorkspaceID, &input)
+	}
+
+	return nil, fmt.Errorf("unknown job run kind %q", run.Kind)
+}
+
+// finish saves the result of the run, or its error when not nil
+func (s *jobRunService) finish(ctx context.Context, q *model.Queries, runId uuid.UUID, result any, e *apperrors.Error) error {
+	params := model.FinishJobRunParams{
+		ID:     runId,
+		Status: JobRunSucceeded,
+	}
+
+	var err error
+	if e != nil {
+		params.Status = JobRunFailed
+		params.Error, err = json.Marshal(&JobRunError{
+			Type:    e.Type,
+			Message: e.Message,
+			Fields:  e.Fields,
+			Key:     e.Key,
+			Params:  e.Params,
+		})
+	} else {
+		params.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		return err
+	}
+
+	return q.FinishJobRun(ctx, params)
+}
+
+// jobRunJob returns the queue and the type of the jobs of a kind of run
+func jobRunJob(kind string) (string, string, error) {
+	switch kind {
+	case JobRunImport:
+		return jobs.QueueImports, JobImportTransactions, nil
+	case JobRunReport:
+		return jobs.QueueReports, JobGenerateReport, nil
+	}
+
+	return "", "", fmt.Errorf("unknown job run kind %q", kind)
+}
@@ -1,54 +1,136 @@
 package service
 
 import (
-	"fmt"
+	"context"
 	"log/slog"
-	"net/smtp"
+	"net/url"
+	"strings"
+
+	"github.com/opchaves/gin-web-app/app/i18n"
+	"github.com/opchaves/gin-web-app/app/jobs"
+	"github.com/opchaves/gin-web-app/app/mail"
+	"github.com/opchaves/gin-web-app/app/metrics"
+	"github.com/opchaves/gin-web-app/app/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// JobSendEmail is the type of the jobs sending a mail.Message
+const JobSendEmail = "send_email"
+
 type mailService struct {
-	Username   string
-	Password   string
-	Origin     string
-	Port       string
-	Encryption string
-	Logger     *slog.Logger
+	Transport mail.Transport
+	Queue     *jobs.Queue
+	Templates *mail.Templates
+	From      string
+	BaseURL   string
+	Logger    *slog.Logger
+	Metrics   *metrics.Metrics
 }
 
 type MailConfig struct {
-	Username   string
-	Password   string
-	Origin     string
-	Port       string
-	Encryption string
-	Logger     *slog.Logger
+	Transport mail.Transport
+	// Queue makes Send enqueue the messages instead of sending them
+	Queue     *jobs.Queue
+	Templates *mail.Templates
+	// From is used for messages without a sender
+	From string
+	// BaseURL is the public URL of the app used in links
+	BaseURL string
+	Logger  *slog.Logger
+	Metrics *metrics.Metrics
 }
 
 type MailService interface {
-	SendResetEmail(email string, token string) error
+	// Send queues the message when there is a job queue, delivers it otherwise
+	Send(ctx context.Context, msg mail.Message) error
+	// Deliver sends the message through the transport right away
+	Deliver(ctx context.Context, msg mail.Message) error
+	SendResetEmail(ctx context.Context, email string, token string) error
+	SendWelcomeEmail(ctx context.Context, email string, firstName string) error
 }
 
 func NewMailService(c *MailConfig) MailService {
 	return &mailService{
-		Username:   c.Username,
-		Password:   c.Password,
-		Origin:     c.Origin,
-		Port:       c.Port,
-		Encryption: c.Encryption,
-		Logger:     c.Logger,
+		Transport: c.Transport,
+		Queue:     c.Queue,
+		Templates: c.Templates,
+		From:      c.From,
+		BaseURL:   strings.TrimSuffix(c.BaseURL, "/"),
+		Logger:    c.Logger,
+		Metrics:   c.Metrics,
 	}
 }
 
-// SendResetMail sends a password reset email with the given reset token
-func (s *mailService) SendResetEmail(email string, token string) error {
-	msg := "From: " + s.Username + "\n" +
-		"To: " + email + "\n" +
-		"Subject: Reset Email\n\n" +
-		fmt.Sprintf("<a href=\"%s/reset-password/%s\">Reset Password</a>", "http://localhost:8080", token)
+// Send implements MailService
+func (s *mailService) Send(ctx context.Context, msg mail.Message) error {
+	if s.Queue == nil {
+		return s.Deliver(ctx, msg)
+	}
 
-	err := smtp.SendMail(s.Origin+":"+s.Port,
-		smtp.CRAMMD5Auth(s.Username, s.Password),
-		s.Username, []string{email}, []byte(msg))
+	if msg.From == "" {
+		msg.From = s.From
+	}
+	if err := msg.Validate(); err != nil {
+		return err
+	}
 
+	_, err := s.Queue.Enqueue(ctx, jobs.QueueEmails, JobSendEmail, msg)
 	return err
 }
+
+// Deliver implements MailService
+func (s *mailService) Deliver(ctx context.Context, msg mail.Message) error {
+	ctx, span := tracing.Start(ctx, "mail.send", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	if msg.From == "" {
+		msg.From = s.From
+	}
+	span.SetAttributes(attribute.String("mail.subject", msg.Subject))
+
+	err := msg.Validate()
+	if err == nil {
+		err = s.Transport.Send(ctx, msg)
+	}
+
+	if err != nil {
+		s.Metrics.MailSendFailed()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// SendResetEmail sends a password reset email with the given reset token
+func (s *mailService) SendResetEmail(ctx context.Context, email string, token string) error {
+	msg := mail.Message{To: []string{email}}
+
+	err := s.Templates.Render(&msg, "reset_password", i18n.From(ctx), map[string]string{
+		"Email": email,
+		"Link":  s.BaseURL + "/reset-password/" + url.PathEscape(token),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.Send(ctx, msg)
+}
+
+// SendWelcomeEmail sends the email welcoming a new user
+func (s *mailService) SendWelcomeEmail(ctx context.Context, email string, firstName string) error {
+	msg := mail.Message{To: []string{email}}
+
+	err := s.Templates.Render(&msg, "welcome", i18n.From(ctx), map[string]string{
+		"Email":     email,
+		"FirstName": firstName,
+		"Link":      s.BaseURL + "/",
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.Send(ctx, msg)
+}
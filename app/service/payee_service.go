@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/rules"
+)
+
+// payeeBatch is the number of transactions read at once when linking a
+// payee to them
+const payeeBatch = 500
+
+type PayeeInput struct {
+	// Unique in the workspace regardless of case. Titles containing its
+	// words belong to the payee.
+	Name string `json:"name" binding:"required,max=100"`
+	// Patterns of the other titles of the payee, regular expressions
+	// matching regardless of case, e.g. "^amzn" for "Amazon"
+	Aliases []string `json:"aliases" binding:"max=20,dive,required,max=200"`
+	// Category of the new transactions of the payee no rule categorizes
+	CategoryID string `json:"category_id" binding:"omitempty,uuid"`
+}
+
+// SavedPayee is a payee with the number of transactions it was linked to
+type SavedPayee struct {
+	*model.Payee
+	// Transactions without a payee whose titles belong to this one
+	Linked int64 `json:"linked"`
+}
+
+type PayeeService interface {
+	List(ctx context.Context, workspaceId uuid.UUID) ([]*model.Payee, error)
+	// Create adds a payee and links it to the transactions without one
+	// whose titles belong to it
+	Create(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, input *PayeeInput) (*SavedPayee, error)
+	// Update replaces the payee and links it like Create. The transactions
+	// linked already keep their payee.
+	Update(ctx context.Context, workspaceId uuid.UUID, id string, input *PayeeInput) (*SavedPayee, error)
+	// Delete removes the payee, its transactions are left without one
+	Delete(ctx context.Context, workspaceId uuid.UUID, id string) error
+}
+
+type payeeService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+}
+
+func NewPayeeService(c *ServiceConfig) PayeeService {
+	return &payeeService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+	}
+}
+
+// List implements PayeeService.
+func (s *payeeService) List(ctx context.Context, workspaceId uuid.UUID) ([]*model.Payee, error) {
+	list, err := s.Q.GetWorkspacePayees(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []*model.Payee{}
+	}
+
+	return list, nil
+}
+
+// Create implements PayeeService.
+func (s *payeeService) Create(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, input *PayeeInput) (*SavedPayee, error) {
+	params, err := payeeParams(input)
+	if err != nil {
+		return nil, err
+	}
+	params.WorkspaceID = workspaceId
+	params.UserID = userId
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	if params.CategoryID.Valid {
+		if err := checkCategories(ctx, qTx, workspaceId, []uuid.UUID{params.CategoryID.UUID}); err != nil {
+			return nil, err
+		}
+	}
+
+	payee, err := qTx.CreatePayee(ctx, params)
+	if isDuplicateKeyError(err) {
+		return nil, apperrors.NewConflict("payee", params.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := linkPayee(ctx, qTx, payee)
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, tx.Commit(ctx)
+}
+
+// Update implements PayeeService.
+func (s *payeeService) Update(ctx context.Context, workspaceId uuid.UUID, id string, input *PayeeInput) (*SavedPayee, error) {
+	payeeId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apperrors.NewNotFound("payee", id)
+	}
+
+	params, err := payeeParams(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	if params.CategoryID.Valid {
+		if err := checkCategories(ctx, qTx, workspaceId, []uuid.UUID{params.CategoryID.UUID}); err != nil {
+			return nil, err
+		}
+	}
+
+	payee, err := qTx.UpdatePayee(ctx, model.UpdatePayeeParams{
+		ID:          payeeId,
+		WorkspaceID: workspaceId,
+		Name:        params.Name,
+		Aliases:     params.Aliases,
+		CategoryID:  params.CategoryID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("payee", id)
+	}
+	if isDuplicateKeyError(err) {
+		return nil, apperrors.NewConflict("payee", params.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := linkPayee(ctx, qTx, payee)
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, tx.Commit(ctx)
+}
+
+// Delete implements PayeeService.
+func (s *payeeService) Delete(ctx context.Context, workspaceId uuid.UUID, id string) error {
+	payeeId, err := uuid.Parse(id)
+	if err != nil {
+		return apperrors.NewNotFound("payee", id)
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return err
+	}
+
+	n, err := s.Q.WithTx(tx).DeletePayee(ctx, model.DeletePayeeParams{
+		ID:          payeeId,
+		WorkspaceID: workspaceId,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperrors.NewNotFound("payee", id)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// workspacePayees returns the payees of the workspace, by name
+func workspacePayees(ctx context.Context, q *model.Queries, workspaceId uuid.UUID) ([]*rules.Payee, error) {
+	list, err := q.GetWorkspacePayees(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	payees := make([]*rules.Payee, 0, len(list))
+	for _, p := range list {
+		payee, err := compilePayee(p)
+		if err != nil {
+			return nil, err
+		}
+		payees = append(payees, payee)
+	}
+
+	return payees, nil
+}
+
+// compilePayee converts a payee to the one of the rules package, its
+// aliases were validated when it was saved
+func compilePayee(p *model.Payee) (*rules.Payee, error) {
+	payee := &rules.Payee{
+		ID:         p.ID,
+		Name:       rules.NamePattern(p.Name),
+		CategoryID: p.CategoryID,
+	}
+
+	for _, alias := range p.Aliases {
+		re, err := rules.Pattern(alias)
+		if err != nil {
+			return nil, err
+		}
+		payee.Aliases = append(payee.Aliases, re)
+	}
+
+	return payee, nil
+}
+
+// linkPayee links the payee to the transactions of its workspace without
+// one whose titles belong to it
+func linkPayee(ctx context.Context, q *model.Queries, p *model.Payee) (*SavedPayee, error) {
+	payee, err := compilePayee(p)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := &SavedPayee{Payee: p}
+	params := model.GetUnlinkedTransactionsParams{
+		WorkspaceID: p.WorkspaceID,
+		Lim:         payeeBatch,
+	}
+
+	for {
+		rows, err := q.GetUnlinkedTransactions(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []uuid.UUID
+		for _, row := range rows {
+			if payee.Match(row.Title) {
+				ids = append(ids, row.ID)
+			}
+		}
+
+		if len(ids) > 0 {
+			n, err := q.LinkTransactionsPayee(ctx, model.LinkTransactionsPayeeParams{
+				PayeeID:     uuid.NullUUID{UUID: p.ID, Valid: true},
+				WorkspaceID: p.WorkspaceID,
+				Ids:         ids,
+			})
+			if err != nil {
+				return nil, err
+			}
+			saved.Linked += n
+		}
+
+		if len(rows) < payeeBatch {
+			break
+		}
+		params.After = rows[len(rows)-1].ID
+	}
+
+	return saved, nil
+}
+
+// payeeParams validates the input and converts it to the params of the
+// payee
+func payeeParams(input *PayeeInput) (model.CreatePayeeParams, error) {
+	params := model.CreatePayeeParams{
+		Name:    strings.TrimSpace(input.Name),
+		Aliases: []string{},
+	}
+	if id, err := uuid.Parse(input.CategoryID); err == nil {
+		params.CategoryID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	for _, alias := range input.Aliases {
+		if _, err := rules.Pattern(alias); err != nil {
+			return params, apperrors.NewValidation([]apperrors.FieldError{
+				{Field: "Aliases", Message: apperrors.InvalidPattern},
+			})
+		}
+		params.Aliases = append(params.Aliases, alias)
+	}
+
+	return params, nil
+}
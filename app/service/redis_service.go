@@ -48,12 +48,12 @@ func (s *redisService) SetResetToken(ctx context.Context, id string) (string, er
 	uid, err := gonanoid.New()
 
 	if err != nil {
-		s.Logger.Error("failed to generate id: %v\n", err.Error())
+		s.Logger.ErrorContext(ctx, "failed to generate id", slog.String("error", err.Error()))
 		return "", apperrors.NewInternal()
 	}
 
 	if err = s.Redis.Set(ctx, fmt.Sprintf("%s:%s", ForgotPasswordPrefix, uid), id, 24*time.Hour).Err(); err != nil {
-		s.Logger.Error("failed to set link in redis: %v\n", err.Error())
+		s.Logger.ErrorContext(ctx, "failed to set link in redis", slog.String("error", err.Error()))
 		return "", apperrors.NewInternal()
 	}
 
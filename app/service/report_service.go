@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// ReportFilter holds the filters of the reports. Split transactions count
+// as their lines, so the category of a line is what category_id matches.
+type ReportFilter struct {
+	AccountID  string `json:"account_id" form:"account_id" binding:"omitempty,uuid"`
+	CategoryID string `json:"category_id" form:"category_id" binding:"omitempty,uuid"`
+	// Name of a tag of the transactions, case insensitive
+	Tag string `json:"tag" form:"tag" binding:"omitempty,max=50"`
+	// RFC 3339 times of handled_at, To is exclusive
+	From time.Time `json:"from" form:"from"`
+	To   time.Time `json:"to" form:"to"`
+}
+
+// Reports generated in the background
+const (
+	ReportCategories = "categories"
+	ReportPayees     = "payees"
+	ReportTags       = "tags"
+)
+
+// ReportInput is a report to generate in the background, with its filters
+type ReportInput struct {
+	// One of categories, payees and tags
+	Report string `json:"report" binding:"required,oneof=categories payees tags"`
+	ReportFilter
+}
+
+type ReportService interface {
+	// Categories returns the total and number of lines of each category
+	Categories(ctx context.Context, workspaceId uuid.UUID, filter *ReportFilter) ([]*model.GetCategoryReportRow, error)
+	// Payees returns the total and number of transactions of each payee
+	Payees(ctx context.Context, workspaceId uuid.UUID, filter *ReportFilter) ([]*model.GetPayeeReportRow, error)
+	// Tags returns the total and number of transactions of each tag
+	Tags(ctx context.Context, workspaceId uuid.UUID, filter *ReportFilter) ([]*model.GetTagReportRow, error)
+	// Generate returns the rows of the report of the input, like the method
+	// of its name
+	Generate(ctx context.Context, workspaceId uuid.UUID, input *ReportInput) (any, error)
+}
+
+type reportService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+}
+
+func NewReportService(c *ServiceConfig) ReportService {
+	return &reportService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+	}
+}
+
+// Categories implements ReportService.
+func (s *reportService) Categories(ctx context.Context, workspaceId uuid.UUID, filter *ReportFilter) ([]*model.GetCategoryReportRow, error) {
+	rows, err := s.Q.GetCategoryReport(ctx, model.GetCategoryReportParams(filter.params(workspaceId)))
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []*model.GetCategoryReportRow{}
+	}
+
+	return rows, nil
+}
+
+// Payees implements ReportService.
+func (s *reportService) Payees(ctx context.Context, workspaceId uuid.UUID, filter *ReportFilter) ([]*model.GetPayeeReportRow, error) {
+	rows, err := s.Q.GetPayeeReport(ctx, model.GetPayeeReportParams(filter.params(workspaceId)))
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []*model.GetPayeeReportRow{}
+	}
+
+	return rows, nil
+}
+
+// Tags implements ReportService.
+func (s *reportService) Tags(ctx context.Context, workspaceId uuid.UUID, filter *ReportFilter) ([]*model.GetTagReportRow, error) {
+	rows, err := s.Q.GetTagReport(ctx, filter.params(workspaceId))
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []*model.GetTagReportRow{}
+	}
+
+	return rows, nil
+}
+
+// Generate implements ReportService.
+func (s *reportService) Generate(ctx context.Context, workspaceId uuid.UUID, input *ReportInput) (any, error) {
+	switch input.Report {
+	case ReportCategories:
+		return s.Categories(ctx, workspaceId, &input.ReportFilter)
+	case ReportPayees:
+		return s.Payees(ctx, workspaceId, &input.ReportFilter)
+	case ReportTags:
+		return s.Tags(ctx, workspaceId, &input.ReportFilter)
+	}
+
+	return nil, fmt.Errorf("unknown report %q", input.Report)
+}
+
+// params converts the filter, the binding validated the ids. All the
+// reports take the same params.
+func (f *ReportFilter) params(workspaceId uuid.UUID) model.GetTagReportParams {
+	params := model.GetTagReportParams{
+		WorkspaceID: workspaceId,
+		Tag:         pgtype.Text{String: strings.TrimSpace(f.Tag), Valid: strings.TrimSpace(f.Tag) != ""},
+		From:        pgtype.Timestamp{Time: f.From.UTC(), Valid: !f.From.IsZero()},
+		To:          pgtype.Timestamp{Time: f.To.UTC(), Valid: !f.To.IsZero()},
+	}
+	if id, err := uuid.Parse(f.AccountID); err == nil {
+		params.AccountID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	if id, err := uuid.Parse(f.CategoryID); err == nil {
+		params.CategoryID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	return params
+}
@@ -0,0 +1,597 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/outbox"
+	"github.com/opchaves/gin-web-app/app/rules"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+)
+
+// MaxRuleChanges is the number of changes listed by a run of the rules, the
+// rest are only counted
+const MaxRuleChanges = 500
+
+// ruleBatch is the number of transactions a run of the rules reads and
+// changes at once, in a database transaction of their own
+const ruleBatch = 500
+
+type RuleInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+	// Rules run by position, lowest first
+	Position int32 `json:"position" binding:"min=0,max=10000"`
+	// Defaults to true
+	Active *bool `json:"active"`
+	// Conditions, at least one. The patterns are regular expressions matching
+	// regardless of case and the values are inclusive, negative for expenses.
+	TitlePattern string `json:"title_pattern" binding:"omitempty,max=200"`
+	NotePattern  string `json:"note_pattern" binding:"omitempty,max=200"`
+	MinValue     string `json:"min_value" binding:"omitempty,numeric"`
+	MaxValue     string `json:"max_value" binding:"omitempty,numeric"`
+	AccountID    string `json:"account_id" binding:"omitempty,uuid"`
+	// Actions, at least one
+	SetCategoryID string   `json:"set_category_id" binding:"omitempty,uuid"`
+	AddTags       []string `json:"add_tags" binding:"max=20,dive,required,max=50"`
+	SetTitle      string   `json:"set_title" binding:"omitempty,max=200"`
+}
+
+type RunRulesInput struct {
+	// Lists the changes without making them
+	DryRun bool `json:"dry_run"`
+	// Rules to run, inactive ones too. All the active rules by default.
+	RuleIDs []string `json:"rule_ids" binding:"max=100,dive,uuid"`
+	// RFC 3339 times of handled_at, To is exclusive
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Changes the transactions whose category users corrected by hand too,
+	// they are skipped by default
+	IncludeCorrected bool `json:"include_corrected"`
+}
+
+type SuggestRulesInput struct {
+	// Corrections alike needed for a suggestion, defaults to 3
+	Min int `form:"min" binding:"omitempty,min=2,max=100"`
+}
+
+// RuleChange is what the rules changed in a transaction, or would have
+type RuleChange struct {
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Title         string     `json:"title"`
+	CategoryID    uuid.UUID  `json:"category_id"`
+	NewTitle      string     `json:"new_title,omitempty"`
+	NewCategoryID *uuid.UUID `json:"new_category_id,omitempty"`
+	AddTags       []string   `json:"add_tags,omitempty"`
+	// Rules matching the transaction, in the order they ran
+	Rules []uuid.UUID `json:"rules"`
+	// Users corrected the category by hand, the transaction is skipped
+	// without IncludeCorrected
+	Corrected bool `json:"corrected"`
+}
+
+type RunRulesResult struct {
+	DryRun bool `json:"dry_run"`
+	// Number of transactions changed
+	Changed int `json:"changed"`
+	// Number of transactions skipped because their category was corrected
+	Skipped int `json:"skipped"`
+	// The first MaxRuleChanges changes, the skipped ones too
+	Changes []*RuleChange `json:"changes"`
+}
+
+type RuleService interface {
+	// List returns the rules of the workspace in the order they run
+	List(ctx context.Context, workspaceId uuid.UUID) ([]*model.Rule, error)
+	Create(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, input *RuleInput) (*model.Rule, error)
+	// Update replaces the rule, it applies to the transactions created from
+	// now on
+	Update(ctx context.Context, workspaceId uuid.UUID, id string, input *RuleInput) (*model.Rule, error)
+	Delete(ctx context.Context, workspaceId uuid.UUID, id string) error
+	// Run applies the rules to the transactions of the workspace, changing
+	// the category of split transactions but not the one of their lines. The
+	// transactions are changed a batch at a time, a failed run keeps the
+	// batches before.
+	Run(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, input *RunRulesInput) (*RunRulesResult, error)
+	// Suggestions returns rules categorizing the transactions like the users
+	// did by hand
+	Suggestions(ctx context.Context, workspaceId uuid.UUID, input *SuggestRulesInput) ([]*rules.Suggestion, error)
+}
+
+type ruleService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+}
+
+func NewRuleService(c *ServiceConfig) RuleService {
+	return &ruleService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+	}
+}
+
+// List implements RuleService.
+func (s *ruleService) List(ctx context.Context, workspaceId uuid.UUID) ([]*model.Rule, error) {
+	list, err := s.Q.GetWorkspaceRules(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []*model.Rule{}
+	}
+
+	return list, nil
+}
+
+// Create implements RuleService.
+func (s *ruleService) Create(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, input *RuleInput) (*model.Rule, error) {
+	params, err := ruleParams(input)
+	if err != nil {
+		return nil, err
+	}
+	params.WorkspaceID = workspaceId
+	params.UserID = userId
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	if err := checkRuleIds(ctx, qTx, workspaceId, &params); err != nil {
+		return nil, err
+	}
+
+	rule, err := qTx.CreateRule(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, tx.Commit(ctx)
+}
+
+// Update implements RuleService.
+func (s *ruleService) Update(ctx context.Context, workspaceId uuid.UUID, id string, input *RuleInput) (*model.Rule, error) {
+	ruleId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apperrors.NewNotFound("rule", id)
+	}
+
+	params, err := ruleParams(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	if err := checkRuleIds(ctx, qTx, workspaceId, &params); err != nil {
+		return nil, err
+	}
+
+	rule, err := qTx.UpdateRule(ctx, model.UpdateRuleParams{
+		ID:            ruleId,
+		WorkspaceID:   workspaceId,
+		Name:          params.Name,
+		Position:      params.Position,
+		Active:        params.Active,
+		TitlePattern:  params.TitlePattern,
+		NotePattern:   params.NotePattern,
+		MinValue:      params.MinValue,
+		MaxValue:      params.MaxValue,
+		AccountID:     params.AccountID,
+		SetCategoryID: params.SetCategoryID,
+		AddTags:       params.AddTags,
+		SetTitle:      params.SetTitle,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("rule", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, tx.Commit(ctx)
+}
+
+// Delete implements RuleService.
+func (s *ruleService) Delete(ctx context.Context, workspaceId uuid.UUID, id string) error {
+	ruleId, err := uuid.Parse(id)
+	if err != nil {
+		return apperrors.NewNotFound("rule", id)
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return err
+	}
+
+	n, err := s.Q.WithTx(tx).DeleteRule(ctx, model.DeleteRuleParams{
+		ID:          ruleId,
+		WorkspaceID: workspaceId,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperrors.NewNotFound("rule", id)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Run implements RuleService.
+func (s *ruleService) Run(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, input *RunRulesInput) (*RunRulesResult, error) {
+	rs, err := runRules(ctx, s.Q, workspaceId, input.RuleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RunRulesResult{DryRun: input.DryRun, Changes: []*RuleChange{}}
+	params := model.GetRuleCandidatesParams{
+		WorkspaceID: workspaceId,
+		From:        pgtype.Timestamp{Time: input.From.UTC(), Valid: !input.From.IsZero()},
+		To:          pgtype.Timestamp{Time: input.To.UTC(), Valid: !input.To.IsZero()},
+		Lim:         ruleBatch,
+	}
+
+	for len(rs) > 0 {
+		n, err := s.runBatch(ctx, rs, userId, input, &params, result)
+		if err != nil {
+			return nil, err
+		}
+		if n < ruleBatch {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// runBatch applies the rules to the next batch of transactions in a database
+// transaction, moving params past it. It returns the number of transactions
+// read.
+func (s *ruleService) runBatch(ctx context.Context, rs []*rules.Rule, userId uuid.UUID, input *RunRulesInput, params *model.GetRuleCandidatesParams, result *RunRulesResult) (int, error) {
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return 0, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	rows, err := qTx.GetRuleCandidates(ctx, *params)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		change := ruleChange(rs, row)
+		if change == nil {
+			continue
+		}
+
+		skip := change.Corrected && !input.IncludeCorrected
+		if skip {
+			result.Skipped++
+		} else {
+			result.Changed++
+		}
+		if len(result.Changes) < MaxRuleChanges {
+			result.Changes = append(result.Changes, change)
+		}
+		if skip || input.DryRun {
+			continue
+		}
+
+		if err := applyRuleChange(ctx, qTx, params.WorkspaceID, userId, row, change); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(rows) > 0 {
+		params.After = rows[len(rows)-1].ID
+	}
+	if input.DryRun {
+		return len(rows), nil
+	}
+
+	return len(rows), tx.Commit(ctx)
+}
+
+// Suggestions implements RuleService.
+func (s *ruleService) Suggestions(ctx context.Context, workspaceId uuid.UUID, input *SuggestRulesInput) ([]*rules.Suggestion, error) {
+	min := input.Min
+	if min == 0 {
+		min = rules.MinCorrections
+	}
+
+	rows, err := s.Q.GetCategoryCorrections(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	corrections := make([]rules.Correction, 0, len(rows))
+	for _, row := range rows {
+		corrections = append(corrections, rules.Correction{Title: row.Title, CategoryID: row.ToCategoryID})
+	}
+
+	rs, err := activeRules(ctx, s.Q, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules.Suggest(corrections, rs, min), nil
+}
+
+// activeRules returns the active rules of the workspace in the order they run
+func activeRules(ctx context.Context, q *model.Queries, workspaceId uuid.UUID) ([]*rules.Rule, error) {
+	list, err := q.GetWorkspaceRules(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := make([]*rules.Rule, 0, len(list))
+	for _, r := range list {
+		if !r.Active {
+			continue
+		}
+
+		rule, err := compileRule(r)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, rule)
+	}
+
+	return rs, nil
+}
+
+// runRules returns the rules with the ids, or the active ones without ids
+func runRules(ctx context.Context, q *model.Queries, workspaceId uuid.UUID, ids []string) ([]*rules.Rule, error) {
+	if len(ids) == 0 {
+		return activeRules(ctx, q, workspaceId)
+	}
+
+	list, err := q.GetWorkspaceRules(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the binding validated the ids
+	want := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		want[uuid.MustParse(id)] = true
+	}
+
+	rs := make([]*rules.Rule, 0, len(want))
+	for _, r := range list {
+		if !want[r.ID] {
+			continue
+		}
+		delete(want, r.ID)
+
+		rule, err := compileRule(r)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, rule)
+	}
+
+	for id := range want {
+		return nil, apperrors.NewNotFound("rule", id.String())
+	}
+
+	return rs, nil
+}
+
+// compileRule converts a rule to the one of the rules package, its patterns
+// were validated when it was saved
+func compileRule(r *model.Rule) (*rules.Rule, error) {
+	rule := &rules.Rule{
+		ID:         r.ID,
+		AccountID:  r.AccountID,
+		CategoryID: r.SetCategoryID,
+		Tags:       r.AddTags,
+		Rename:     r.SetTitle.String,
+	}
+
+	var err error
+	if r.TitlePattern.Valid {
+		if rule.Title, err = rules.Pattern(r.TitlePattern.String); err != nil {
+			return nil, err
+		}
+	}
+	if r.NotePattern.Valid {
+		if rule.Note, err = rules.Pattern(r.NotePattern.String); err != nil {
+			return nil, err
+		}
+	}
+	if r.MinValue.Valid {
+		rule.MinValue = numericRat(r.MinValue)
+	}
+	if r.MaxValue.Valid {
+		rule.MaxValue = numericRat(r.MaxValue)
+	}
+
+	return rule, nil
+}
+
+// ruleChange returns what the rules change in the transaction, nil for
+// nothing
+func ruleChange(rs []*rules.Rule, row *model.GetRuleCandidatesRow) *RuleChange {
+	t := rules.Transaction{
+		Title:      row.Title,
+		Note:       row.Note.String,
+		Value:      numericRat(row.Value),
+		AccountID:  row.AccountID,
+		CategoryID: row.CategoryID,
+		Tags:       slices.Clone(row.Tags),
+	}
+
+	matched := rules.Apply(rs, &t)
+	if len(matched) == 0 {
+		return nil
+	}
+
+	change := &RuleChange{
+		TransactionID: row.ID,
+		Title:         row.Title,
+		CategoryID:    row.CategoryID,
+		AddTags:       t.Tags[len(row.Tags):],
+		Rules:         matched,
+		Corrected:     row.Corrected,
+	}
+	if t.Title != row.Title {
+		change.NewTitle = t.Title
+	}
+	if t.CategoryID != row.CategoryID {
+		change.NewCategoryID = &t.CategoryID
+	}
+
+	if change.NewTitle == "" && change.NewCategoryID == nil && len(change.AddTags) == 0 {
+		return nil
+	}
+
+	return change
+}
+
+// applyRuleChange makes the change in the transaction of row
+func applyRuleChange(ctx context.Context, q *model.Queries, workspaceId, userId uuid.UUID, row *model.GetRuleCandidatesRow, change *RuleChange) error {
+	if err := addTags(ctx, q, workspaceId, userId, row.ID, change.AddTags); err != nil {
+		return err
+	}
+	if change.NewTitle == "" && change.NewCategoryID == nil {
+		return nil
+	}
+
+	params := model.UpdateTransactionParams{
+		ID:          row.ID,
+		WorkspaceID: workspaceId,
+		Title:       row.Title,
+		Note:        row.Note,
+		CategoryID:  row.CategoryID,
+	}
+	if change.NewTitle != "" {
+		params.Title = change.NewTitle
+	}
+	if change.NewCategoryID != nil {
+		params.CategoryID = *change.NewCategoryID
+	}
+
+	updated, err := q.UpdateTransaction(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	return outbox.Publish(ctx, q, webhooks.EventTransactionUpdated, updated)
+}
+
+// ruleParams validates the input and converts it to the params of the rule
+func ruleParams(input *RuleInput) (model.CreateRuleParams, error) {
+	params := model.CreateRuleParams{
+		Name:         strings.TrimSpace(input.Name),
+		Position:     input.Position,
+		Active:       input.Active == nil || *input.Active,
+		TitlePattern: pgtype.Text{String: input.TitlePattern, Valid: input.TitlePattern != ""},
+		NotePattern:  pgtype.Text{String: input.NotePattern, Valid: input.NotePattern != ""},
+		AddTags:      tagNames(input.AddTags),
+		SetTitle:     pgtype.Text{String: strings.TrimSpace(input.SetTitle), Valid: strings.TrimSpace(input.SetTitle) != ""},
+	}
+	if id, err := uuid.Parse(input.AccountID); err == nil {
+		params.AccountID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	if id, err := uuid.Parse(input.SetCategoryID); err == nil {
+		params.SetCategoryID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	var fields []apperrors.FieldError
+	if _, err := rules.Pattern(input.TitlePattern); err != nil {
+		fields = append(fields, apperrors.FieldError{Field: "TitlePattern", Message: apperrors.InvalidPattern})
+	}
+	if _, err := rules.Pattern(input.NotePattern); err != nil {
+		fields = append(fields, apperrors.FieldError{Field: "NotePattern", Message: apperrors.InvalidPattern})
+	}
+	for _, v := range []struct {
+		field string
+		value string
+		n     *pgtype.Numeric
+	}{
+		{"MinValue", input.MinValue, &params.MinValue},
+		{"MaxValue", input.MaxValue, &params.MaxValue},
+	} {
+		if v.value == "" {
+			continue
+		}
+		r, ok := new(big.Rat).SetString(v.value)
+		if !ok || !isCents(r) || v.n.Scan(r.FloatString(2)) != nil {
+			fields = append(fields, apperrors.FieldError{Field: v.field, Message: apperrors.InvalidCents})
+			continue
+		}
+		if !inValueRange(r) {
+			fields = append(fields, apperrors.FieldError{Field: v.field, Message: apperrors.ValueOutOfRange})
+		}
+	}
+	if fields != nil {
+		return params, apperrors.NewValidation(fields)
+	}
+
+	if !params.TitlePattern.Valid && !params.NotePattern.Valid && !params.MinValue.Valid &&
+		!params.MaxValue.Valid && !params.AccountID.Valid {
+		return params, apperrors.NewBadRequest(apperrors.RuleWithoutCondition)
+	}
+	if !params.SetCategoryID.Valid && !params.SetTitle.Valid && len(params.AddTags) == 0 {
+		return params, apperrors.NewBadRequest(apperrors.RuleWithoutAction)
+	}
+
+	return params, nil
+}
+
+// checkRuleIds makes sure the account and category of the rule are of the
+// workspace
+func checkRuleIds(ctx context.Context, q *model.Queries, workspaceId uuid.UUID, params *model.CreateRuleParams) error {
+	if params.AccountID.Valid {
+		if err := checkAccounts(ctx, q, workspaceId, []uuid.UUID{params.AccountID.UUID}); err != nil {
+			return err
+		}
+	}
+	if params.SetCategoryID.Valid {
+		return checkCategories(ctx, q, workspaceId, []uuid.UUID{params.SetCategoryID.UUID})
+	}
+
+	return nil
+}
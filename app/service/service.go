@@ -1,10 +1,13 @@
 package service
 
 import (
+	"context"
 	"log/slog"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -14,3 +17,83 @@ type ServiceConfig struct {
 	Db     *pgxpool.Pool
 	Redis  *redis.Client
 }
+
+// workspaceTransaction returns the id of a transaction of the workspace
+func workspaceTransaction(ctx context.Context, q *model.Queries, workspaceId uuid.UUID, id string) (uuid.UUID, error) {
+	txId, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, apperrors.NewNotFound("transaction", id)
+	}
+
+	exists, err := q.WorkspaceTransactionExists(ctx, model.WorkspaceTransactionExistsParams{
+		ID:          txId,
+		WorkspaceID: workspaceId,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !exists {
+		return uuid.Nil, apperrors.NewNotFound("transaction", id)
+	}
+
+	return txId, nil
+}
+
+// checkCategories makes sure the categories are of the workspace
+func checkCategories(ctx context.Context, q *model.Queries, workspaceId uuid.UUID, ids []uuid.UUID) error {
+	ids = uniqueIds(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	count, err := q.CountWorkspaceCategories(ctx, model.CountWorkspaceCategoriesParams{
+		WorkspaceID: workspaceId,
+		Ids:         ids,
+	})
+	if err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return apperrors.NewValidation([]apperrors.FieldError{
+			{Field: "CategoryID", Message: apperrors.UnknownCategory},
+		})
+	}
+
+	return nil
+}
+
+// checkAccounts makes sure the accounts are of the workspace
+func checkAccounts(ctx context.Context, q *model.Queries, workspaceId uuid.UUID, ids []uuid.UUID) error {
+	ids = uniqueIds(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	count, err := q.CountWorkspaceAccounts(ctx, model.CountWorkspaceAccountsParams{
+		WorkspaceID: workspaceId,
+		Ids:         ids,
+	})
+	if err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return apperrors.NewValidation([]apperrors.FieldError{
+			{Field: "AccountID", Message: apperrors.UnknownAccount},
+		})
+	}
+
+	return nil
+}
+
+func uniqueIds(ids []uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
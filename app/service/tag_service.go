@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+)
+
+type TagsInput struct {
+	// Names of the tags, created in the workspace when new. Names differing
+	// only in case are the same tag. No names untag the transaction.
+	Tags []string `json:"tags" binding:"max=20,dive,required,max=50"`
+}
+
+type TagService interface {
+	// List returns the tags of the workspace with how many transactions have
+	// each
+	List(ctx context.Context, workspaceId uuid.UUID) ([]*model.GetWorkspaceTagsRow, error)
+	// Delete removes the tag from the workspace and its transactions
+	Delete(ctx context.Context, workspaceId uuid.UUID, id string) error
+	TransactionTags(ctx context.Context, workspaceId uuid.UUID, transactionId string) ([]*model.Tag, error)
+	// SetTransactionTags replaces the tags of a transaction
+	SetTransactionTags(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, transactionId string, input *TagsInput) ([]*model.Tag, error)
+}
+
+type tagService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+}
+
+func NewTagService(c *ServiceConfig) TagService {
+	return &tagService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+	}
+}
+
+// List implements TagService.
+func (s *tagService) List(ctx context.Context, workspaceId uuid.UUID) ([]*model.GetWorkspaceTagsRow, error) {
+	tags, err := s.Q.GetWorkspaceTags(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []*model.GetWorkspaceTagsRow{}
+	}
+
+	return tags, nil
+}
+
+// Delete implements TagService.
+func (s *tagService) Delete(ctx context.Context, workspaceId uuid.UUID, id string) error {
+	tagId, err := uuid.Parse(id)
+	if err != nil {
+		return apperrors.NewNotFound("tag", id)
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return err
+	}
+
+	n, err := s.Q.WithTx(tx).DeleteTag(ctx, model.DeleteTagParams{
+		ID:          tagId,
+		WorkspaceID: workspaceId,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperrors.NewNotFound("tag", id)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// TransactionTags implements TagService.
+func (s *tagService) TransactionTags(ctx context.Context, workspaceId uuid.UUID, transactionId string) ([]*model.Tag, error) {
+	txId, err := workspaceTransaction(ctx, s.Q, workspaceId, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transactionTags(ctx, s.Q, workspaceId, txId)
+}
+
+// SetTransactionTags implements TagService.
+func (s *tagService) SetTransactionTags(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, transactionId string, input *TagsInput) ([]*model.Tag, error) {
+	txId, err := workspaceTransaction(ctx, s.Q, workspaceId, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	if err := qTx.DeleteTransactionTags(ctx, txId); err != nil {
+		return nil, err
+	}
+
+	if err := addTags(ctx, qTx, workspaceId, userId, txId, input.Tags); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.transactionTags(ctx, qTx, workspaceId, txId)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, tx.Commit(ctx)
+}
+
+func (s *tagService) transactionTags(ctx context.Context, q *model.Queries, workspaceId uuid.UUID, txId uuid.UUID) ([]*model.Tag, error) {
+	tags, err := q.GetTransactionTags(ctx, model.GetTransactionTagsParams{
+		TransactionID: txId,
+		WorkspaceID:   workspaceId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []*model.Tag{}
+	}
+
+	return tags, nil
+}
+
+// addTags tags a transaction with the names, creating the tags new to the
+// workspace
+func addTags(ctx context.Context, q *model.Queries, workspaceId, userId, txId uuid.UUID, names []string) error {
+	names = tagNames(names)
+	if len(names) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(names))
+	for _, name := range names {
+		tag, err := q.UpsertTag(ctx, model.UpsertTagParams{
+			Name:        name,
+			WorkspaceID: workspaceId,
+			UserID:      userId,
+		})
+		if err != nil {
+			return err
+		}
+		ids = append(ids, tag.ID)
+	}
+
+	return q.AddTransactionTags(ctx, model.AddTransactionTagsParams{
+		TransactionID: txId,
+		TagIds:        ids,
+	})
+}
+
+// tagNames trims the names and drops the blank and repeated ones, keeping
+// the first spelling of each
+func tagNames(names []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, name)
+	}
+
+	return out
+}
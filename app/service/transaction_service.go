@@ -0,0 +1,717 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/outbox"
+	"github.com/opchaves/gin-web-app/app/rules"
+	"github.com/opchaves/gin-web-app/app/search"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+)
+
+// TransactionFilter holds the ledger filters of the transaction listings
+type TransactionFilter struct {
+	AccountID  string `form:"account_id" binding:"omitempty,uuid"`
+	CategoryID string `form:"category_id" binding:"omitempty,uuid"`
+	// RFC 3339 times of handled_at, To is exclusive
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+	// Decimal values, both inclusive
+	MinValue string `form:"min_value" binding:"omitempty,numeric"`
+	MaxValue string `form:"max_value" binding:"omitempty,numeric"`
+	// Name of a tag of the transactions, case insensitive
+	Tag string `form:"tag" binding:"omitempty,max=50"`
+}
+
+type SearchTransactionsInput struct {
+	TransactionFilter
+	// Words to search in the title, note, category and account name. The
+	// last word of a query matches as a prefix like the others.
+	Query string `form:"q" binding:"required,max=200"`
+	// Max 100, defaults to 20
+	Limit  int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int32 `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SplitLine is a line of a split transaction. Its value has the sign of the
+// transaction value, e.g. -12.50 for part of an expense, and can't be zero.
+type SplitLine struct {
+	CategoryID string `json:"category_id" binding:"required,uuid"`
+	Value      string `json:"value" binding:"required,numeric"`
+	Note       string `json:"note" binding:"omitempty,max=200"`
+}
+
+type SplitTransactionInput struct {
+	// Two or more lines adding up to the value of the transaction. No lines
+	// turn it back into a single line.
+	Lines []SplitLine `json:"lines" binding:"omitempty,min=2,max=50,dive"`
+}
+
+type TransactionInput struct {
+	Title string `json:"title" binding:"required,max=200"`
+	Note  string `json:"note" binding:"omitempty,max=1000"`
+	// Decimal value, negative for expenses
+	Value     string `json:"value" binding:"required,numeric"`
+	AccountID string `json:"account_id" binding:"required,uuid"`
+	// Set by the rules, or else the payee, when empty
+	CategoryID string `json:"category_id" binding:"omitempty,uuid"`
+	// Found from the title when empty
+	PayeeID   string    `json:"payee_id" binding:"omitempty,uuid"`
+	HandledAt time.Time `json:"handled_at" binding:"required"`
+	Tags      []string  `json:"tags" binding:"max=20,dive,required,max=50"`
+}
+
+type ImportTransactionsInput struct {
+	Transactions []TransactionInput `json:"transactions" binding:"required,min=1,max=1000,dive"`
+}
+
+// UpdateTransactionInput changes the fields given. A new category is
+// recorded as a correction, rules are suggested from them.
+type UpdateTransactionInput struct {
+	Title      *string `json:"title" binding:"omitempty,min=1,max=200"`
+	Note       *string `json:"note" binding:"omitempty,max=1000"`
+	CategoryID *string `json:"category_id" binding:"omitempty,uuid"`
+	// An empty id unlinks the payee
+	PayeeID *string `json:"payee_id" binding:"omitempty,uuid|eq="`
+}
+
+// CreatedTransaction is a new transaction with its tags and the rules that
+// matched it
+type CreatedTransaction struct {
+	*model.CreateTransactionRow
+	Tags  []string    `json:"tags"`
+	Rules []uuid.UUID `json:"rules"`
+}
+
+// ImportResult is the result of an import job
+type ImportResult struct {
+	Imported int `json:"imported"`
+	// Number of transactions the rules matched
+	Matched int `json:"matched"`
+}
+
+type TransactionService interface {
+	// Create adds a transaction after running the rules of the workspace on
+	// it and finding its payee from the title. A category given wins over
+	// the one of the rules, which wins over the one of the payee.
+	Create(ctx context.Context, workspace *model.Workspace, userId uuid.UUID, input *TransactionInput) (*CreatedTransaction, error)
+	Update(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, transactionId string, input *UpdateTransactionInput) (*model.UpdateTransactionRow, error)
+	// Search returns the transactions of the workspace matching the query,
+	// best matches first, with their matches highlighted in the snippets
+	Search(ctx context.Context, workspace *model.Workspace, input *SearchTransactionsInput) ([]*model.SearchTransactionsRow, error)
+	// Splits returns the lines of a transaction, none when it isn't split
+	Splits(ctx context.Context, workspaceId uuid.UUID, transactionId string) ([]*model.TransactionSplit, error)
+	// Split replaces the lines of a transaction. Reports count the lines, in
+	// their categories, instead of the transaction.
+	Split(ctx context.Context, workspaceId uuid.UUID, transactionId string, input *SplitTransactionInput) ([]*model.TransactionSplit, error)
+}
+
+type transactionService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+}
+
+func NewTransactionService(c *ServiceConfig) TransactionService {
+	return &transactionService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+	}
+}
+
+// newTransaction is a transaction to create, after the rules ran
+type newTransaction struct {
+	params model.CreateTransactionsParams
+	tags   []string
+	rules  []uuid.UUID
+}
+
+// Create implements TransactionService.
+func (s *transactionService) Create(ctx context.Context, workspace *model.Workspace, userId uuid.UUID, input *TransactionInput) (*CreatedTransaction, error) {
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	list, err := newTransactions(ctx, qTx, workspace, userId, []TransactionInput{*input}, func(_ int, field string) string {
+		return field
+	})
+	if err != nil {
+		return nil, err
+	}
+	t := list[0]
+
+	row, err := qTx.CreateTransaction(ctx, model.CreateTransactionParams{
+		Title:       t.params.Title,
+		Note:        t.params.Note,
+		Currency:    t.params.Currency,
+		Value:       t.params.Value,
+		UserID:      t.params.UserID,
+		WorkspaceID: t.params.WorkspaceID,
+		CategoryID:  t.params.CategoryID,
+		AccountID:   t.params.AccountID,
+		HandledAt:   t.params.HandledAt,
+		PayeeID:     t.params.PayeeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addTags(ctx, qTx, workspace.ID, userId, row.ID, t.tags); err != nil {
+		return nil, err
+	}
+
+	created := &CreatedTransaction{CreateTransactionRow: row, Tags: t.tags, Rules: t.rules}
+	if err := outbox.Publish(ctx, qTx, webhooks.EventTransactionCreated, created); err != nil {
+		return nil, err
+	}
+
+	return created, tx.Commit(ctx)
+}
+
+// Update implements TransactionService.
+func (s *transactionService) Update(ctx context.Context, workspaceId uuid.UUID, userId uuid.UUID, transactionId string, input *UpdateTransactionInput) (*model.UpdateTransactionRow, error) {
+	txId, err := uuid.Parse(transactionId)
+	if err != nil {
+		return nil, apperrors.NewNotFound("transaction", transactionId)
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	current, err := qTx.LockWorkspaceTransaction(ctx, model.LockWorkspaceTransactionParams{
+		ID:          txId,
+		WorkspaceID: workspaceId,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("transaction", transactionId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	params := model.UpdateTransactionParams{
+		ID:          txId,
+		WorkspaceID: workspaceId,
+		Title:       current.Title,
+		Note:        current.Note,
+		CategoryID:  current.CategoryID,
+	}
+	if input.Title != nil {
+		params.Title = *input.Title
+	}
+	if input.Note != nil {
+		params.Note = pgtype.Text{String: *input.Note, Valid: *input.Note != ""}
+	}
+	if input.CategoryID != nil {
+		params.CategoryID = uuid.MustParse(*input.CategoryID)
+		if err := checkCategories(ctx, qTx, workspaceId, []uuid.UUID{params.CategoryID}); err != nil {
+			return nil, err
+		}
+	}
+	if input.PayeeID != nil {
+		payeeId, err := checkPayee(ctx, qTx, workspaceId, *input.PayeeID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = qTx.SetTransactionPayee(ctx, model.SetTransactionPayeeParams{
+			ID:          txId,
+			WorkspaceID: workspaceId,
+			PayeeID:     payeeId,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := qTx.UpdateTransaction(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated.CategoryID != current.CategoryID {
+		err := qTx.CreateCategoryCorrection(ctx, model.CreateCategoryCorrectionParams{
+			TransactionID:  txId,
+			WorkspaceID:    workspaceId,
+			UserID:         userId,
+			Title:          updated.Title,
+			FromCategoryID: current.CategoryID,
+			ToCategoryID:   updated.CategoryID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := outbox.Publish(ctx, qTx, webhooks.EventTransactionUpdated, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, tx.Commit(ctx)
+}
+
+// importTransactions adds the transactions like Create, with the queries of
+// a transaction so all of them or none are added
+func importTransactions(ctx context.Context, q *model.Queries, workspace *model.Workspace, userId uuid.UUID, input *ImportTransactionsInput) (*ImportResult, error) {
+	list, err := newTransactions(ctx, q, workspace, userId, input.Transactions, func(i int, field string) string {
+		return fmt.Sprintf("Transactions[%d].%s", i, field)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]model.CreateTransactionsParams, 0, len(list))
+	for _, t := range list {
+		params = append(params, t.params)
+	}
+	if _, err := q.CreateTransactions(ctx, params); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Imported: len(list)}
+	for _, t := range list {
+		if len(t.rules) > 0 {
+			result.Matched++
+		}
+
+		if err := addTags(ctx, q, workspace.ID, userId, t.params.ID, t.tags); err != nil {
+			return nil, err
+		}
+
+		err := outbox.Publish(ctx, q, webhooks.EventTransactionCreated, &CreatedTransaction{
+			CreateTransactionRow: &model.CreateTransactionRow{
+				ID:          t.params.ID,
+				Title:       t.params.Title,
+				Note:        t.params.Note,
+				Currency:    t.params.Currency,
+				Value:       t.params.Value,
+				UserID:      t.params.UserID,
+				WorkspaceID: t.params.WorkspaceID,
+				CategoryID:  t.params.CategoryID,
+				AccountID:   t.params.AccountID,
+				HandledAt:   t.params.HandledAt,
+				PayeeID:     t.params.PayeeID,
+			},
+			Tags:  t.tags,
+			Rules: t.rules,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// newTransactions validates the inputs, runs the rules on them and finds
+// their payees. field names the field of an input in the errors.
+func newTransactions(ctx context.Context, q *model.Queries, workspace *model.Workspace, userId uuid.UUID, inputs []TransactionInput, field func(i int, name string) string) ([]*newTransaction, error) {
+	rs, err := activeRules(ctx, q, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	payees, err := workspacePayees(ctx, q, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*newTransaction, 0, len(inputs))
+	var accounts, categories []uuid.UUID
+	var fields []apperrors.FieldError
+
+	for i, input := range inputs {
+		value, ok := new(big.Rat).SetString(input.Value)
+		if !ok || !isCents(value) {
+			fields = append(fields, apperrors.FieldError{Field: field(i, "Value"), Message: apperrors.InvalidCents})
+			continue
+		}
+		if !inValueRange(value) {
+			fields = append(fields, apperrors.FieldError{Field: field(i, "Value"), Message: apperrors.ValueOutOfRange})
+			continue
+		}
+
+		// the binding validated the ids
+		t := rules.Transaction{
+			Title:     strings.TrimSpace(input.Title),
+			Note:      input.Note,
+			Value:     value,
+			AccountID: uuid.MustParse(input.AccountID),
+			Tags:      tagNames(input.Tags),
+		}
+		matched := rules.Apply(rs, &t)
+
+		payee, ok := transactionPayee(payees, input, t.Title)
+		if !ok {
+			fields = append(fields, apperrors.FieldError{Field: field(i, "PayeeID"), Message: apperrors.UnknownPayee})
+			continue
+		}
+
+		if input.CategoryID != "" {
+			t.CategoryID = uuid.MustParse(input.CategoryID)
+		}
+		if t.CategoryID == uuid.Nil && payee != nil && payee.CategoryID.Valid {
+			t.CategoryID = payee.CategoryID.UUID
+		}
+		if t.CategoryID == uuid.Nil {
+			fields = append(fields, apperrors.FieldError{Field: field(i, "CategoryID"), Message: apperrors.CategoryRequired})
+			continue
+		}
+
+		n := &newTransaction{
+			params: model.CreateTransactionsParams{
+				ID:          uuid.New(),
+				Title:       t.Title,
+				Note:        pgtype.Text{String: t.Note, Valid: t.Note != ""},
+				Currency:    pgtype.Text{String: workspace.Currency, Valid: true},
+				UserID:      userId,
+				WorkspaceID: workspace.ID,
+				CategoryID:  t.CategoryID,
+				AccountID:   t.AccountID,
+				HandledAt:   pgtype.Timestamp{Time: input.HandledAt.UTC(), Valid: true},
+			},
+			tags:  t.Tags,
+			rules: matched,
+		}
+		if n.rules == nil {
+			n.rules = []uuid.UUID{}
+		}
+		if payee != nil {
+			n.params.PayeeID = uuid.NullUUID{UUID: payee.ID, Valid: true}
+		}
+		if err := n.params.Value.Scan(value.FloatString(2)); err != nil {
+			return nil, err
+		}
+
+		list = append(list, n)
+		accounts = append(accounts, t.AccountID)
+		categories = append(categories, t.CategoryID)
+	}
+
+	if fields != nil {
+		return nil, apperrors.NewValidation(fields)
+	}
+
+	if err := checkAccounts(ctx, q, workspace.ID, accounts); err != nil {
+		return nil, err
+	}
+	if err := checkCategories(ctx, q, workspace.ID, categories); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// transactionPayee returns the payee of the input, the one given or else the
+// first one its title belongs to, before or after the rules renamed it. It
+// reports false when the payee given isn't of the workspace.
+func transactionPayee(payees []*rules.Payee, input TransactionInput, title string) (*rules.Payee, bool) {
+	if input.PayeeID != "" {
+		id := uuid.MustParse(input.PayeeID)
+		for _, p := range payees {
+			if p.ID == id {
+				return p, true
+			}
+		}
+		return nil, false
+	}
+
+	if p := rules.FindPayee(payees, input.Title); p != nil {
+		return p, true
+	}
+
+	return rules.FindPayee(payees, title), true
+}
+
+// checkPayee returns the payee with the id if it's of the workspace, no
+// payee for an empty id
+func checkPayee(ctx context.Context, q *model.Queries, workspaceId uuid.UUID, id string) (uuid.NullUUID, error) {
+	if id == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	// the binding validated the id
+	payeeId := uuid.MustParse(id)
+	count, err := q.CountWorkspacePayees(ctx, model.CountWorkspacePayeesParams{
+		WorkspaceID: workspaceId,
+		Ids:         []uuid.UUID{payeeId},
+	})
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if count == 0 {
+		return uuid.NullUUID{}, apperrors.NewValidation([]apperrors.FieldError{
+			{Field: "PayeeID", Message: apperrors.UnknownPayee},
+		})
+	}
+
+	return uuid.NullUUID{UUID: payeeId, Valid: true}, nil
+}
+
+// Search implements TransactionService.
+func (s *transactionService) Search(ctx context.Context, workspace *model.Workspace, input *SearchTransactionsInput) ([]*model.SearchTransactionsRow, error) {
+	query := search.Query(input.Query)
+	if query == "" {
+		return nil, apperrors.NewBadRequest(apperrors.EmptySearchQuery)
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	params := model.SearchTransactionsParams{
+		Language:    workspace.Language,
+		Query:       query,
+		WorkspaceID: workspace.ID,
+		From:        pgtype.Timestamp{Time: input.From.UTC(), Valid: !input.From.IsZero()},
+		To:          pgtype.Timestamp{Time: input.To.UTC(), Valid: !input.To.IsZero()},
+		Lim:         limit,
+		Off:         input.Offset,
+	}
+	if err := input.TransactionFilter.apply(&params); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Q.SearchTransactions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		row.TitleSnippet = search.Highlight(row.TitleSnippet)
+		row.NoteSnippet = search.Highlight(row.NoteSnippet)
+	}
+	if rows == nil {
+		rows = []*model.SearchTransactionsRow{}
+	}
+
+	return rows, nil
+}
+
+// Splits implements TransactionService.
+func (s *transactionService) Splits(ctx context.Context, workspaceId uuid.UUID, transactionId string) ([]*model.TransactionSplit, error) {
+	txId, err := workspaceTransaction(ctx, s.Q, workspaceId, transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	splits, err := s.Q.GetTransactionSplits(ctx, model.GetTransactionSplitsParams{
+		TransactionID: txId,
+		WorkspaceID:   workspaceId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if splits == nil {
+		splits = []*model.TransactionSplit{}
+	}
+
+	return splits, nil
+}
+
+// Split implements TransactionService.
+func (s *transactionService) Split(ctx context.Context, workspaceId uuid.UUID, transactionId string, input *SplitTransactionInput) ([]*model.TransactionSplit, error) {
+	txId, err := uuid.Parse(transactionId)
+	if err != nil {
+		return nil, apperrors.NewNotFound("transaction", transactionId)
+	}
+
+	tx, err := s.Db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetActor(ctx, tx); err != nil {
+		return nil, err
+	}
+	qTx := s.Q.WithTx(tx)
+
+	// locks the transaction until the lines are replaced
+	value, err := qTx.GetWorkspaceTransactionValue(ctx, model.GetWorkspaceTransactionValueParams{
+		ID:          txId,
+		WorkspaceID: workspaceId,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("transaction", transactionId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines, sum, err := splitLines(txId, workspaceId, input.Lines, numericRat(value))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) > 0 {
+		if sum.Cmp(numericRat(value)) != 0 {
+			return nil, apperrors.NewValidation([]apperrors.FieldError{
+				{Field: "Lines", Message: apperrors.SplitValue},
+			})
+		}
+
+		ids := make([]uuid.UUID, 0, len(lines))
+		for _, line := range lines {
+			ids = append(ids, line.CategoryID)
+		}
+		if err := checkCategories(ctx, qTx, workspaceId, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	err = qTx.DeleteTransactionSplits(ctx, model.DeleteTransactionSplitsParams{
+		TransactionID: txId,
+		WorkspaceID:   workspaceId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	splits := make([]*model.TransactionSplit, 0, len(lines))
+	for _, line := range lines {
+		split, err := qTx.CreateTransactionSplit(ctx, line)
+		if err != nil {
+			return nil, err
+		}
+		splits = append(splits, split)
+	}
+
+	// the lines are checked against the value on commit too
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+// splitLines converts the lines to their params and returns the sum of
+// their values. The binding validated the ids and that values are numbers.
+func splitLines(txId, workspaceId uuid.UUID, lines []SplitLine, total *big.Rat) ([]model.CreateTransactionSplitParams, *big.Rat, error) {
+	params := make([]model.CreateTransactionSplitParams, 0, len(lines))
+	sum := new(big.Rat)
+	var fields []apperrors.FieldError
+
+	for i, line := range lines {
+		field := fmt.Sprintf("Lines[%d].Value", i)
+
+		value, ok := new(big.Rat).SetString(line.Value)
+		if !ok || !isCents(value) {
+			fields = append(fields, apperrors.FieldError{Field: field, Message: apperrors.InvalidCents})
+			continue
+		}
+		if !inValueRange(value) {
+			fields = append(fields, apperrors.FieldError{Field: field, Message: apperrors.ValueOutOfRange})
+			continue
+		}
+		if value.Sign() == 0 || value.Sign() != total.Sign() {
+			fields = append(fields, apperrors.FieldError{Field: field, Message: apperrors.SplitLineSign})
+			continue
+		}
+		sum.Add(sum, value)
+
+		p := model.CreateTransactionSplitParams{
+			TransactionID: txId,
+			WorkspaceID:   workspaceId,
+			CategoryID:    uuid.MustParse(line.CategoryID),
+			Note:          pgtype.Text{String: line.Note, Valid: line.Note != ""},
+			Position:      int32(i),
+		}
+		if err := p.Value.Scan(value.FloatString(2)); err != nil {
+			return nil, nil, err
+		}
+		params = append(params, p)
+	}
+
+	if fields != nil {
+		return nil, nil, apperrors.NewValidation(fields)
+	}
+
+	return params, sum, nil
+}
+
+// isCents reports whether r has at most 2 decimal places
+func isCents(r *big.Rat) bool {
+	return new(big.Rat).Mul(r, big.NewRat(100, 1)).IsInt()
+}
+
+// maxValue is the largest value the NUMERIC(10, 2) columns hold
+var maxValue = big.NewRat(9999999999, 100)
+
+// inValueRange reports whether r fits the value columns either way
+func inValueRange(r *big.Rat) bool {
+	return new(big.Rat).Abs(r).Cmp(maxValue) <= 0
+}
+
+// numericRat converts n to a rational number
+func numericRat(n pgtype.Numeric) *big.Rat {
+	if n.Int == nil {
+		return new(big.Rat)
+	}
+
+	r := new(big.Rat).SetInt(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n.Exp))), nil)
+	if n.Exp < 0 {
+		return r.Quo(r, new(big.Rat).SetInt(exp))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(exp))
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// apply sets the ids and values of the filter, the binding validated them
+func (f *TransactionFilter) apply(params *model.SearchTransactionsParams) error {
+	if id, err := uuid.Parse(f.AccountID); err == nil {
+		params.AccountID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	if id, err := uuid.Parse(f.CategoryID); err == nil {
+		params.CategoryID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	if f.MinValue != "" {
+		if err := params.MinValue.Scan(f.MinValue); err != nil {
+			return apperrors.NewBadRequest(apperrors.InvalidMinValue)
+		}
+	}
+	if f.MaxValue != "" {
+		if err := params.MaxValue.Scan(f.MaxValue); err != nil {
+			return apperrors.NewBadRequest(apperrors.InvalidMaxValue)
+		}
+	}
+	params.Tag = pgtype.Text{String: strings.TrimSpace(f.Tag), Valid: strings.TrimSpace(f.Tag) != ""}
+
+	return nil
+}
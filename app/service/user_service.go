@@ -8,28 +8,32 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/i18n"
 	"github.com/opchaves/gin-web-app/app/model"
 	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/outbox"
 	"github.com/opchaves/gin-web-app/app/utils"
 )
 
 type RegisterInput struct {
 	// Must be unique
-	Email string `json:"email" binding:"required,email"`
+	Email string `json:"email" form:"email" binding:"required,email"`
 	// Min 2, max 30 characters.
-	FirstName string `json:"first_name" binding:"required,min=2,max=30"`
+	FirstName string `json:"first_name" form:"first_name" binding:"required,min=2,max=30"`
 	// Min 2, max 30 characters.
-	LastName string `json:"last_name" binding:"required,min=2,max=30"`
+	LastName string `json:"last_name" form:"last_name" binding:"required,min=2,max=30"`
 	// Min 10, max 100 characters.
-	Password string `json:"password" binding:"required,min=10,max=100"`
+	Password string `json:"password" form:"password" binding:"required,min=10,max=100"`
 } //@name RegisterRequest
 
 type LoginInput struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" form:"email" binding:"required,email"`
+	Password string `json:"password" form:"password" binding:"required"`
 } //@name LoginInput
 
 // TODO rename struct. maybe `UserResponse`
@@ -41,15 +45,32 @@ type RegisterResponse struct {
 } //@name RegisterResponse
 
 type ForgotPasswordInput struct {
-	Email string `json:"email" binding:"required,email"`
+	Email string `json:"email" form:"email" binding:"required,email"`
 } //@name ForgotPasswordInput
 
+// EventUserRegistered is the outbox topic of the users who registered
+const EventUserRegistered = "user.registered"
+
+// UserRegisteredEvent is the payload of EventUserRegistered
+type UserRegisteredEvent struct {
+	UserID      uuid.UUID `json:"user_id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Email       string    `json:"email"`
+	FirstName   string    `json:"first_name"`
+	// Language the user registered in, for the welcome email
+	Language string `json:"language"`
+}
+
 type UserService interface {
 	GetById(ctx context.Context, id string) (*RegisterResponse, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	Register(ctx context.Context, user *RegisterInput) (*RegisterResponse, error)
 	Login(ctx context.Context, input *LoginInput) (*RegisterResponse, error)
+	// Logout records the logout of the user
+	Logout(ctx context.Context, id string) error
 	ForgotPassword(ctx context.Context, user *model.User) error
+	// Language returns the language of the user's profile, "" without one
+	Language(ctx context.Context, id uuid.UUID) string
 }
 
 type userService struct {
@@ -98,6 +119,9 @@ func (us *userService) GetById(ctx context.Context, id string) (*RegisterRespons
 func (us *userService) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	user, err := us.Q.GetUserByEmail(ctx, email)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("email", email)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +134,7 @@ func (us *userService) Register(ctx context.Context, data *RegisterInput) (*Regi
 	hashedPassword, err := utils.HashPassword(data.Password)
 
 	if err != nil {
-		us.Logger.Error("unable to hash password", slog.Any("error", err))
+		us.Logger.ErrorContext(ctx, "unable to hash password", slog.Any("error", err))
 		return nil, err
 	}
 
@@ -137,8 +161,10 @@ func (us *userService) Register(ctx context.Context, data *RegisterInput) (*Regi
 	user, err := qTx.CreateUser(ctx, newUser)
 
 	if isDuplicateKeyError(err) {
-		us.Logger.Warn("failed to register user", slog.Any("error", err))
-		err = apperrors.NewBadRequest(apperrors.DuplicateEmail)
+		us.Logger.WarnContext(ctx, "failed to register user", slog.Any("error", err))
+		err = apperrors.NewValidation([]apperrors.FieldError{
+			{Field: "Email", Message: apperrors.DuplicateEmail},
+		})
 	}
 
 	if err != nil {
@@ -154,16 +180,31 @@ func (us *userService) Register(ctx context.Context, data *RegisterInput) (*Regi
 		UserID:      user.ID,
 	}
 
-	_, err = qTx.CreateWorkspace(ctx, newWorkspace)
+	workspace, err := qTx.CreateWorkspace(ctx, newWorkspace)
 	if err != nil {
-		us.Logger.Error("failed to create workspace", slog.String("userId", user.ID.String()))
+		us.Logger.ErrorContext(ctx, "failed to create workspace", slog.String("userId", user.ID.String()))
 		return nil, err
 	}
-	us.Logger.Info("User workspace created", slog.String("userId", user.ID.String()))
+	us.Logger.InfoContext(ctx, "User workspace created", slog.String("userId", user.ID.String()))
 
-	tx.Commit(ctx)
+	// the side effects of the registration are published by the outbox relay
+	// once the transaction commits
+	err = outbox.Publish(ctx, qTx, EventUserRegistered, UserRegisteredEvent{
+		UserID:      user.ID,
+		WorkspaceID: workspace.ID,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		Language:    i18n.From(ctx).Lang,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return &RegisterResponse{User: user}, err
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &RegisterResponse{User: user}, nil
 	// TODO future: send email to verify account.
 	// TODO when user verifies account, then create workspace, default accounts and categories
 }
@@ -173,6 +214,7 @@ func (us *userService) Login(ctx context.Context, input *LoginInput) (*RegisterR
 
 	// Will return NotAuthorized to client to omit details of why
 	if err != nil {
+		us.recordLoginFailed(ctx, nil, input.Email)
 		return nil, apperrors.NewAuthorization(apperrors.InvalidCredentials)
 	}
 
@@ -183,10 +225,53 @@ func (us *userService) Login(ctx context.Context, input *LoginInput) (*RegisterR
 	}
 
 	if !match {
+		us.recordLoginFailed(ctx, user, input.Email)
 		return nil, apperrors.NewAuthorization(apperrors.InvalidCredentials)
 	}
 
-	return &RegisterResponse{User: user}, err
+	err = audit.Record(ctx, us.Q, audit.Entry{
+		ActorID:    audit.ID(user.ID),
+		EntityType: audit.EntityUser,
+		EntityID:   audit.ID(user.ID),
+		Action:     audit.ActionLogin,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegisterResponse{User: user}, nil
+}
+
+// recordLoginFailed logs a failed login. Errors are only logged, the login
+// fails anyway.
+func (us *userService) recordLoginFailed(ctx context.Context, user *model.User, email string) {
+	entry := audit.Entry{
+		EntityType: audit.EntityUser,
+		Action:     audit.ActionLoginFailed,
+		After:      map[string]string{"email": email},
+	}
+	if user != nil {
+		entry.EntityID = audit.ID(user.ID)
+	}
+
+	if err := audit.Record(ctx, us.Q, entry); err != nil {
+		us.Logger.ErrorContext(ctx, "failed to record failed login", slog.String("error", err.Error()))
+	}
+}
+
+// Logout implements UserService.
+func (us *userService) Logout(ctx context.Context, id string) error {
+	userId, err := uuid.Parse(id)
+	if err != nil {
+		return err
+	}
+
+	return audit.Record(ctx, us.Q, audit.Entry{
+		ActorID:    audit.ID(userId),
+		EntityType: audit.EntityUser,
+		EntityID:   audit.ID(userId),
+		Action:     audit.ActionLogout,
+	})
 }
 
 // isDuplicateKeyError checks if the provided error is a PostgreSQL duplicate key error
@@ -206,6 +291,26 @@ func (s *userService) ForgotPassword(ctx context.Context, user *model.User) erro
 		return err
 	}
 
-	// TODO send email async? is this already enough? or run send to bg job?
-	return s.MailService.SendResetEmail(user.Email, token)
+	err = audit.Record(ctx, s.Q, audit.Entry{
+		EntityType: audit.EntityUser,
+		EntityID:   audit.ID(user.ID),
+		Action:     audit.ActionPasswordResetRequested,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the email is queued and sent by a job worker
+	return s.MailService.SendResetEmail(ctx, user.Email, token)
+}
+
+// Language implements UserService. Failures are only logged, the language
+// of the request is used instead.
+func (us *userService) Language(ctx context.Context, id uuid.UUID) string {
+	lang, err := us.Q.GetUserLanguage(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		us.Logger.ErrorContext(ctx, "failed to get the language of the user", slog.String("error", err.Error()))
+	}
+
+	return lang
 }
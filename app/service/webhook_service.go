@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/jobs"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/outbox"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+)
+
+// JobDeliverWebhook is the type of the jobs sending a webhook delivery
+const JobDeliverWebhook = "deliver_webhook"
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookInput struct {
+	// Must be an http or https URL
+	Url string `json:"url" form:"url" binding:"required,http_url,max=2048"`
+	// Events to deliver, see webhooks.Events
+	Events []string `json:"events" form:"events" binding:"required,min=1,dive,webhook_event"`
+	// Max 255 characters.
+	Description string `json:"description" form:"description" binding:"max=255"`
+} //@name WebhookRequest
+
+type DeliveriesInput struct {
+	// Max 100, defaults to 20
+	Limit  int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int32 `form:"offset" binding:"omitempty,min=0"`
+}
+
+// WebhookResponse hides the secret, which is only shown once the webhook is
+// created
+type WebhookResponse struct {
+	*model.Webhook
+	Secret    bool `json:"secret,omitempty"`
+	DeletedAt bool `json:"deleted_at,omitempty"`
+} //@name WebhookResponse
+
+// WebhookDeliveryMessage is the job payload of a delivery
+type WebhookDeliveryMessage struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+type WebhookService interface {
+	List(ctx context.Context, workspaceId uuid.UUID) ([]*WebhookResponse, error)
+	// Create returns the webhook with its secret
+	Create(ctx context.Context, workspace *model.Workspace, userId uuid.UUID, input *WebhookInput) (*model.Webhook, error)
+	Delete(ctx context.Context, workspaceId uuid.UUID, id string) error
+	ListDeliveries(ctx context.Context, workspaceId uuid.UUID, id string, input *DeliveriesInput) ([]*model.WebhookDelivery, error)
+	// Replay sends a delivery again as a new one
+	Replay(ctx context.Context, workspaceId uuid.UUID, id string, deliveryId string) (*model.WebhookDelivery, error)
+	// Dispatch creates the deliveries of an outbox event for the webhooks
+	// subscribed to it and queues them
+	Dispatch(ctx context.Context, e *outbox.Event) error
+	// Deliver sends a delivery and records the attempt. It returns an error
+	// when it has to be retried, unless it is the last attempt.
+	Deliver(ctx context.Context, deliveryId uuid.UUID, lastAttempt bool) error
+}
+
+type webhookService struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+	Queue  *jobs.Queue
+	Sender *webhooks.Sender
+}
+
+type WHConfig struct {
+	Q      *model.Queries
+	Logger *slog.Logger
+	Db     *pgxpool.Pool
+	Queue  *jobs.Queue
+	Sender *webhooks.Sender
+}
+
+func NewWebhookService(c *WHConfig) WebhookService {
+	return &webhookService{
+		Q:      c.Q,
+		Logger: c.Logger,
+		Db:     c.Db,
+		Queue:  c.Queue,
+		Sender: c.Sender,
+	}
+}
+
+// List implements WebhookService.
+func (s *webhookService) List(ctx context.Context, workspaceId uuid.UUID) ([]*WebhookResponse, error) {
+	hooks, err := s.Q.GetWorkspaceWebhooks(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*WebhookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		res = append(res, &WebhookResponse{Webhook: hook})
+	}
+
+	return res, nil
+}
+
+// Create implements WebhookService.
+func (s *webhookService) Create(ctx context.Context, workspace *model.Workspace, userId uuid.UUID, input *WebhookInput) (*model.Webhook, error) {
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Q.CreateWebhook(ctx, model.CreateWebhookParams{
+		Url:         input.Url,
+		Secret:      secret,
+		Events:      input.Events,
+		Description: pgtype.Text{String: input.Description, Valid: input.Description != ""},
+		UserID:      userId,
+		WorkspaceID: workspace.ID,
+	})
+}
+
+// Delete implements WebhookService.
+func (s *webhookService) Delete(ctx context.Context, workspaceId uuid.UUID, id string) error {
+	webhookId, err := uuid.Parse(id)
+	if err != nil {
+		return apperrors.NewNotFound("webhook", id)
+	}
+
+	n, err := s.Q.DeleteWebhook(ctx, model.DeleteWebhookParams{ID: webhookId, WorkspaceID: workspaceId})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperrors.NewNotFound("webhook", id)
+	}
+
+	return nil
+}
+
+// ListDeliveries implements WebhookService.
+func (s *webhookService) ListDeliveries(ctx context.Context, workspaceId uuid.UUID, id string, input *DeliveriesInput) ([]*model.WebhookDelivery, error) {
+	hook, err := s.get(ctx, workspaceId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	return s.Q.GetWebhookDeliveries(ctx, model.GetWebhookDeliveriesParams{
+		WebhookID: hook.ID,
+		Limit:     limit,
+		Offset:    input.Offset,
+	})
+}
+
+// Replay implements WebhookService.
+func (s *webhookService) Replay(ctx context.Context, workspaceId uuid.UUID, id string, deliveryId string) (*model.WebhookDelivery, error) {
+	hook, err := s.get(ctx, workspaceId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	originalId, err := uuid.Parse(deliveryId)
+	if err != nil {
+		return nil, apperrors.NewNotFound("delivery", deliveryId)
+	}
+
+	delivery, err := s.Q.ReplayWebhookDelivery(ctx, model.ReplayWebhookDeliveryParams{
+		ID:        originalId,
+		WebhookID: hook.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("delivery", deliveryId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enqueue(ctx, delivery.ID); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Dispatch implements WebhookService.
+func (s *webhookService) Dispatch(ctx context.Context, e *outbox.Event) error {
+	var event struct {
+		WorkspaceID uuid.UUID `json:"workspace_id"`
+	}
+	if err := e.Decode(&event); err != nil {
+		return err
+	}
+	if event.WorkspaceID == uuid.Nil {
+		return fmt.Errorf("%s event without workspace_id", e.Topic)
+	}
+
+	hooks, err := s.Q.GetEventWebhooks(ctx, model.GetEventWebhooksParams{
+		WorkspaceID: event.WorkspaceID,
+		Event:       e.Topic,
+	})
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	// the id is the same when the event is dispatched again, so it gets the
+	// deliveries it already has
+	eventId := fmt.Sprintf("evt_%d", e.ID)
+	payload, err := json.Marshal(&webhooks.Payload{
+		ID:          eventId,
+		Type:        e.Topic,
+		WorkspaceID: event.WorkspaceID,
+		CreatedAt:   e.CreatedAt.UTC(),
+		Data:        e.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		delivery, err := s.Q.CreateWebhookDelivery(ctx, model.CreateWebhookDeliveryParams{
+			WebhookID:   hook.ID,
+			WorkspaceID: hook.WorkspaceID,
+			EventID:     eventId,
+			Event:       e.Topic,
+			Payload:     payload,
+		})
+		if err != nil {
+			return err
+		}
+
+		if delivery.Status != DeliveryPending {
+			continue
+		}
+		if err := s.enqueue(ctx, delivery.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deliver implements WebhookService.
+func (s *webhookService) Deliver(ctx context.Context, deliveryId uuid.UUID, lastAttempt bool) error {
+	delivery, err := s.Q.GetWebhookDelivery(ctx, deliveryId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.Logger.WarnContext(ctx, "webhook delivery not found", slog.String("delivery_id", deliveryId.String()))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// the job ran twice
+	if delivery.Status != DeliveryPending {
+		return nil
+	}
+
+	logger := s.Logger.With(
+		slog.String("delivery_id", delivery.ID.String()),
+		slog.String("webhook_id", delivery.WebhookID.String()),
+		slog.String("event", delivery.Event),
+	)
+
+	hook, err := s.Q.GetWorkspaceWebhook(ctx, model.GetWorkspaceWebhookParams{
+		ID:          delivery.WebhookID,
+		WorkspaceID: delivery.WorkspaceID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		logger.InfoContext(ctx, "webhook deleted, dropping delivery")
+		return s.Q.UpdateWebhookDeliveryAttempt(ctx, model.UpdateWebhookDeliveryAttemptParams{
+			ID:        delivery.ID,
+			Status:    DeliveryFailed,
+			LastError: pgtype.Text{String: "webhook deleted", Valid: true},
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	res, sendErr := s.Sender.Send(ctx, &webhooks.Request{
+		URL:      hook.Url,
+		Secret:   hook.Secret,
+		Event:    delivery.Event,
+		EventID:  delivery.EventID,
+		Delivery: delivery.ID.String(),
+		Body:     delivery.Payload,
+	})
+
+	attempt := model.UpdateWebhookDeliveryAttemptParams{
+		ID:     delivery.ID,
+		Status: DeliverySucceeded,
+	}
+	if res != nil {
+		attempt.ResponseStatus = pgtype.Int4{Int32: int32(res.Status), Valid: true}
+		attempt.ResponseBody = pgtype.Text{String: res.Body, Valid: true}
+		attempt.DurationMs = pgtype.Int4{Int32: int32(res.Duration.Milliseconds()), Valid: true}
+	}
+	if sendErr != nil {
+		attempt.Status = DeliveryPending
+		if lastAttempt {
+			attempt.Status = DeliveryFailed
+		}
+		attempt.LastError = pgtype.Text{String: sendErr.Error(), Valid: true}
+	}
+
+	if err := s.Q.UpdateWebhookDeliveryAttempt(ctx, attempt); err != nil {
+		return err
+	}
+
+	if sendErr != nil {
+		logger.WarnContext(ctx, "webhook delivery failed",
+			slog.Bool("last_attempt", lastAttempt),
+			slog.String("error", sendErr.Error()),
+		)
+		if !lastAttempt {
+			return sendErr
+		}
+	}
+
+	return nil
+}
+
+// get returns a webhook of the workspace
+func (s *webhookService) get(ctx context.Context, workspaceId uuid.UUID, id string) (*model.Webhook, error) {
+	webhookId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apperrors.NewNotFound("webhook", id)
+	}
+
+	hook, err := s.Q.GetWorkspaceWebhook(ctx, model.GetWorkspaceWebhookParams{
+		ID:          webhookId,
+		WorkspaceID: workspaceId,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.NewNotFound("webhook", id)
+	}
+
+	return hook, err
+}
+
+func (s *webhookService) enqueue(ctx context.Context, deliveryId uuid.UUID) error {
+	_, err := s.Queue.Enqueue(ctx, jobs.QueueWebhooks, JobDeliverWebhook, WebhookDeliveryMessage{
+		DeliveryID: deliveryId,
+	})
+	return err
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
 )
 
 type WorkspaceInput struct {
@@ -20,6 +23,8 @@ type WorkspaceInput struct {
 
 type WorkspaceService interface {
 	GetById(ctx context.Context, id string) (*model.Workspace, error)
+	// GetUserWorkspace returns the workspace if it belongs to the user
+	GetUserWorkspace(ctx context.Context, userId string, id string) (*model.Workspace, error)
 	Create(ctx context.Context, data *WorkspaceInput) (*model.Workspace, error)
 	BuildNewWorkspace(data *WorkspaceInput) (*model.CreateWorkspaceParams, error)
 }
@@ -40,7 +45,33 @@ func NewWorkspaceService(c *ServiceConfig) WorkspaceService {
 
 // GetById implements WorkspaceService.
 func (s *workspaceService) GetById(ctx context.Context, id string) (*model.Workspace, error) {
-	panic("unimplemented")
+	workspaceId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apperrors.NewNotFound("workspace", id)
+	}
+
+	workspace, err := s.Q.GetWorkspaceByID(ctx, workspaceId)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && workspace.DeletedAt.Valid {
+		return nil, apperrors.NewNotFound("workspace", id)
+	}
+
+	return workspace, err
+}
+
+// GetUserWorkspace implements WorkspaceService.
+func (s *workspaceService) GetUserWorkspace(ctx context.Context, userId string, id string) (*model.Workspace, error) {
+	workspace, err := s.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// other users' workspaces are not found rather than forbidden so their ids
+	// can't be probed
+	if workspace.UserID.String() != userId {
+		return nil, apperrors.NewNotFound("workspace", id)
+	}
+
+	return workspace, nil
 }
 
 // Create implements WorkspaceService.
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrInvalidSignature is returned by Verify for a tampered URL
+	ErrInvalidSignature = errors.New("storage: invalid signature")
+	// ErrExpired is returned by Verify once a URL expires
+	ErrExpired = errors.New("storage: url expired")
+)
+
+// FilesPath is where the app serves the files of the local storage
+const FilesPath = "/files/"
+
+// Local stores the objects as files under Dir. Its signed URLs point to the
+// app, which checks them with Verify and serves the files.
+type Local struct {
+	Dir     string
+	BaseURL string
+	Secret  []byte
+
+	now func() time.Time
+}
+
+type LocalConfig struct {
+	Dir string
+	// BaseURL is the public URL of the app
+	BaseURL string
+	// Secret signs the URLs
+	Secret string
+}
+
+func NewLocal(c *LocalConfig) *Local {
+	return &Local{
+		Dir:     c.Dir,
+		BaseURL: c.BaseURL,
+		Secret:  []byte(c.Secret),
+		now:     time.Now,
+	}
+}
+
+// Put implements Storage. The file is written next to its final path and
+// renamed so readers never see it half-written.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("storage: wrote %d bytes of %d", n, size)
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// Get implements Storage. The reader is an *os.File.
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+// Delete implements Storage.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// SignedURL implements Storage.
+func (l *Local) SignedURL(ctx context.Context, key string, expires time.Duration, filename string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	exp := strconv.FormatInt(l.now().Add(expires).Unix(), 10)
+
+	q := url.Values{}
+	q.Set("expires", exp)
+	if filename != "" {
+		q.Set("filename", filename)
+	}
+	q.Set("signature", l.sign(key, exp, filename))
+
+	return l.BaseURL + FilesPath + (&url.URL{Path: key}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+// Verify checks the query of a URL returned by SignedURL for key
+func (l *Local) Verify(key string, query url.Values) error {
+	exp := query.Get("expires")
+	want := l.sign(key, exp, query.Get("filename"))
+	if !hmac.Equal([]byte(want), []byte(query.Get("signature"))) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if l.now().Unix() > unix {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// ContentDisposition is the header to serve a file downloaded with query
+func (l *Local) ContentDisposition(query url.Values) string {
+	if filename := query.Get("filename"); filename != "" {
+		return contentDisposition(filename)
+	}
+	return ""
+}
+
+func (l *Local) sign(key, expires, filename string) string {
+	mac := hmac.New(sha256.New, l.Secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s", key, expires, filename)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
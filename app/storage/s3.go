@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateFormat   = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+)
+
+// S3 stores the objects in a bucket of an S3 compatible service, e.g. AWS,
+// MinIO or R2. Requests are signed with AWS Signature Version 4.
+type S3 struct {
+	Endpoint  *url.URL
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle puts the bucket in the path, http://host/bucket/key, instead
+	// of the host, http://bucket.host/key. Most stand-ins need it.
+	PathStyle bool
+	Client    *http.Client
+
+	now func() time.Time
+}
+
+type S3Config struct {
+	// Endpoint is the URL of the service, e.g. https://s3.amazonaws.com
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+func NewS3(c *S3Config) (*S3, error) {
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", c.Endpoint)
+	}
+	if c.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	region := c.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3{
+		Endpoint:  endpoint,
+		Region:    region,
+		Bucket:    c.Bucket,
+		AccessKey: c.AccessKey,
+		SecretKey: c.SecretKey,
+		PathStyle: c.PathStyle,
+		Client:    client,
+		now:       time.Now,
+	}, nil
+}
+
+// Put implements Storage.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+// Get implements Storage.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+// Delete implements Storage.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+// SignedURL implements Storage. It is a presigned GET request.
+func (s *S3) SignedURL(ctx context.Context, key string, expires time.Duration, filename string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	u := s.objectURL(key)
+	now := s.now().UTC()
+
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", signAlgorithm)
+	q.Set("X-Amz-Credential", s.AccessKey+"/"+s.scope(now))
+	q.Set("X-Amz-Date", now.Format(amzDateFormat))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if filename != "" {
+		q.Set("response-content-disposition", contentDisposition(filename))
+	}
+	u.RawQuery = canonicalQuery(q)
+
+	header := http.Header{}
+	signature := s.signature(now, canonicalRequest(http.MethodGet, u, header, u.Host, []string{"host"}, unsignedPayload))
+	u.RawQuery += "&X-Amz-Signature=" + signature
+
+	return u.String(), nil
+}
+
+func (s *S3) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	return req, nil
+}
+
+// do signs and sends req, returning an error unless it succeeds
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	s.sign(req)
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		if res.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, res.Status, body)
+	}
+
+	return res, nil
+}
+
+// sign sets the Authorization header of req, signing its host and x-amz
+// headers
+func (s *S3) sign(req *http.Request) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+
+	signed := []string{"host"}
+	for name := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-amz-") {
+			signed = append(signed, name)
+		}
+	}
+	sort.Strings(signed)
+
+	signature := s.signature(now, canonicalRequest(req.Method, req.URL, req.Header, req.URL.Host, signed, req.Header.Get("X-Amz-Content-Sha256")))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, s.AccessKey, s.scope(now), strings.Join(signed, ";"), signature))
+}
+
+func (s *S3) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		t.Format(amzDateFormat),
+		s.scope(t),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format("20060102"))
+	for _, part := range []string{s.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func (s *S3) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.Region + "/s3/aws4_request"
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.Endpoint
+	u.RawQuery = ""
+	base := strings.TrimSuffix(u.Path, "/")
+
+	if s.PathStyle {
+		u.Path = base + "/" + s.Bucket + "/" + key
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+
+	return &u
+}
+
+func canonicalRequest(method string, u *url.URL, header http.Header, host string, signed []string, payloadHash string) string {
+	var headers strings.Builder
+	for _, name := range signed {
+		value := host
+		if name != "host" {
+			value = strings.Join(header.Values(name), ",")
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	return strings.Join([]string{
+		method,
+		uriEncode(u.Path, false),
+		canonicalQuery(u.Query()),
+		headers.String(),
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// canonicalQuery sorts and encodes the query the way it is signed
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters, and
+// slashes unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,54 @@
+// Package storage keeps uploaded files behind the Storage interface. Files
+// are stored on the local filesystem or in an S3 compatible bucket and are
+// downloaded with signed URLs that expire.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when there is no object with the key
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey is returned for keys escaping the storage, e.g. with ..
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Storage stores objects by key. Keys are slash separated paths, e.g.
+// workspaces/<id>/attachments/<id>.pdf.
+type Storage interface {
+	// Put stores the size bytes of r under key, replacing any object there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns the content of the object, the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object, it is not an error if there is none
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL anyone can download the object from until it
+	// expires. Browsers are told to save it as filename when one is given.
+	SignedURL(ctx context.Context, key string, expires time.Duration, filename string) (string, error)
+}
+
+// ValidKey reports whether key is a relative path without . or .. segments
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// contentDisposition shows the file in the browser, saving it as filename
+func contentDisposition(filename string) string {
+	return mime.FormatMediaType("inline", map[string]string{"filename": filename})
+}
@@ -11,17 +11,23 @@ import (
 	"github.com/opchaves/gin-web-app/app/model/fixture"
 	"github.com/opchaves/gin-web-app/app/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type apiResponse struct {
 	Data service.RegisterResponse `json:"data"`
 }
 
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
 func TestMain_AccountE2E(t *testing.T) {
-	router := SetupTest(t)
+	router := New(t).Router
 
 	authUser := fixture.GetMockUser()
 	cookie := ""
+	csrfToken := ""
 
 	testCases := []struct {
 		name          string
@@ -121,6 +127,43 @@ func TestMain_AccountE2E(t *testing.T) {
 				assert.NotNil(t, respBody.Data.UpdatedAt)
 			},
 		},
+		{
+			name: "Get CSRF Token",
+			setupRequest: func() (*http.Request, error) {
+				return http.NewRequest(http.MethodGet, "/auth/csrf", nil)
+			},
+			setupHeaders: func(t *testing.T, request *http.Request) {
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Add("Cookie", cookie)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				respBody := &struct {
+					Data struct {
+						Token string `json:"token"`
+					} `json:"data"`
+				}{}
+				err := json.Unmarshal(recorder.Body.Bytes(), respBody)
+				assert.NoError(t, err)
+				assert.NotEmpty(t, respBody.Data.Token)
+
+				csrfToken = respBody.Data.Token
+			},
+		},
+		{
+			name: "Logout Without CSRF Token",
+			setupRequest: func() (*http.Request, error) {
+				return http.NewRequest(http.MethodPost, "/auth/logout", nil)
+			},
+			setupHeaders: func(t *testing.T, request *http.Request) {
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Add("Cookie", cookie)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "Logout",
 			setupRequest: func() (*http.Request, error) {
@@ -129,6 +172,7 @@ func TestMain_AccountE2E(t *testing.T) {
 			setupHeaders: func(t *testing.T, request *http.Request) {
 				request.Header.Set("Content-Type", "application/json")
 				request.Header.Add("Cookie", cookie)
+				request.Header.Set("X-CSRF-Token", csrfToken)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
@@ -149,3 +193,41 @@ func TestMain_AccountE2E(t *testing.T) {
 		})
 	}
 }
+
+func TestForgotPassword_E2E(t *testing.T) {
+	a := New(t)
+	user := a.CreateUser()
+
+	res := a.Client().Post("/auth/forgot-password", gin.H{"email": user.Email})
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, a.Mail.Messages(), "email is sent by a job")
+	assert.Equal(t, 1, a.RunJobs())
+
+	messages := a.Mail.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{user.Email}, messages[0].To)
+	assert.Contains(t, messages[0].Text, a.Cfg.PublicURL+"/reset-password/")
+	assert.Contains(t, messages[0].HTML, a.Cfg.PublicURL+"/reset-password/")
+}
+
+func TestGetCurrent_E2E(t *testing.T) {
+	a := New(t)
+
+	t.Run("Logged In", func(t *testing.T) {
+		client, user := a.AuthClient()
+
+		res := client.Get("/auth/me")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, user.ID, Data[service.RegisterResponse](res).ID)
+	})
+
+	t.Run("Logged Out", func(t *testing.T) {
+		client, _ := a.AuthClient()
+
+		res := client.Post("/auth/logout", nil)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		res = client.Get("/auth/me")
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachments_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+
+	category, account, transaction := uuid.New(), uuid.New(), uuid.New()
+	for _, sql := range []string{
+		`INSERT INTO categories (id, name, c_type, user_id, workspace_id) VALUES ($1, 'Food', 'expense', $4, $5)`,
+		`INSERT INTO accounts (id, name, user_id, workspace_id) VALUES ($2, 'Wallet', $4, $5)`,
+		`INSERT INTO transactions (id, title, value, user_id, workspace_id, category_id, account_id) VALUES ($3, 'Dinner', 30, $4, $5, $1, $2)`,
+	} {
+		_, err := a.Db.Exec(ctx, sql, category, account, transaction, user.ID, workspace.ID)
+		require.NoError(t, err)
+	}
+
+	path := "/workspaces/" + workspace.ID.String() + "/transactions/" + transaction.String() + "/attachments"
+
+	img := image.NewNRGBA(image.Rect(0, 0, 800, 400))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(10, 10, color.Black)
+	var receipt bytes.Buffer
+	require.NoError(t, png.Encode(&receipt, img))
+
+	// download follows a signed URL of the local storage
+	download := func(raw string) *Response {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		return a.Client().Get(u.RequestURI())
+	}
+
+	var uploaded service.AttachmentResponse
+
+	t.Run("Upload Image", func(t *testing.T) {
+		res := client.Upload(path, "file", "../receipt.png", "image/png", receipt.Bytes())
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+
+		uploaded = Data[service.AttachmentResponse](res)
+		assert.Equal(t, "receipt.png", uploaded.Filename)
+		assert.Equal(t, "image/png", uploaded.ContentType)
+		assert.Equal(t, int64(receipt.Len()), uploaded.Size)
+		assert.NotContains(t, res.Body.String(), "storage_key")
+		require.NotEmpty(t, uploaded.ThumbnailURL)
+
+		file := download(uploaded.URL)
+		require.Equal(t, http.StatusOK, file.Code)
+		assert.Equal(t, receipt.Bytes(), file.Body.Bytes())
+		assert.Contains(t, file.Header().Get("Content-Disposition"), "receipt.png")
+
+		thumb := download(uploaded.ThumbnailURL)
+		require.Equal(t, http.StatusOK, thumb.Code)
+		assert.Equal(t, "image/jpeg", thumb.Header().Get("Content-Type"))
+		cfg, err := jpeg.DecodeConfig(thumb.Body)
+		require.NoError(t, err)
+		assert.Equal(t, 320, cfg.Width)
+		assert.Equal(t, 160, cfg.Height)
+	})
+
+	t.Run("Upload PDF", func(t *testing.T) {
+		res := client.Upload(path, "file", "invoice.pdf", "application/octet-stream", []byte("%PDF-1.4\n%%EOF"))
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+
+		pdf := Data[service.AttachmentResponse](res)
+		assert.Equal(t, "application/pdf", pdf.ContentType)
+		assert.Empty(t, pdf.ThumbnailURL)
+
+		list := Data[[]service.AttachmentResponse](client.Get(path))
+		require.Len(t, list, 2)
+		assert.Equal(t, uploaded.ID, list[0].ID)
+		assert.Equal(t, pdf.ID, list[1].ID)
+	})
+
+	t.Run("Content Sniffing", func(t *testing.T) {
+		res := client.Upload(path, "file", "photo.png", "image/png", []byte("<html><script>alert(1)</script></html>"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		assert.Contains(t, res.Body.String(), apperrors.InvalidImageType)
+
+		res = client.Upload(path, "file", "notes.txt", "text/plain", []byte("just text"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		assert.Contains(t, res.Body.String(), apperrors.InvalidAttachmentType)
+
+		// a PNG signature followed by garbage
+		res = client.Upload(path, "file", "broken.png", "image/png", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 100)...))
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+	})
+
+	t.Run("Size Limit", func(t *testing.T) {
+		big := append([]byte("%PDF-1.4\n"), make([]byte, a.MaxUploadBytes)...)
+		res := client.Upload(path, "file", "big.pdf", "application/pdf", big)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+
+		res = client.Upload(path, "other", "a.pdf", "application/pdf", []byte("%PDF"))
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Signed URLs", func(t *testing.T) {
+		u, err := url.Parse(uploaded.URL)
+		require.NoError(t, err)
+
+		q := u.Query()
+		q.Set("expires", "9999999999")
+		u.RawQuery = q.Encode()
+		assert.Equal(t, http.StatusForbidden, download(u.String()).Code)
+
+		res := client.Get(path + "/" + uploaded.ID.String() + "/download")
+		require.Equal(t, http.StatusFound, res.Code)
+		assert.Equal(t, http.StatusOK, download(res.Header().Get("Location")).Code)
+	})
+
+	t.Run("Other Workspace", func(t *testing.T) {
+		other, otherUser := a.AuthClient()
+		otherPath := "/workspaces/" + a.Workspace(otherUser).ID.String() + "/transactions/" + transaction.String() + "/attachments"
+
+		assert.Equal(t, http.StatusNotFound, other.Get(otherPath).Code)
+		res := other.Upload(otherPath, "file", "a.pdf", "application/pdf", []byte("%PDF-1.4"))
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Equal(t, http.StatusNotFound, other.Get(path).Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		res := client.Delete(path + "/" + uploaded.ID.String())
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		assert.Equal(t, http.StatusNotFound, download(uploaded.URL).Code)
+		assert.Equal(t, http.StatusNotFound, download(uploaded.ThumbnailURL).Code)
+		assert.Len(t, Data[[]service.AttachmentResponse](client.Get(path)), 1)
+
+		res = client.Delete(path + "/" + uploaded.ID.String())
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/model/fixture"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain_AuditClientAddress(t *testing.T) {
+	actorIP := func(a *App, forwardedFor string) string {
+		a.Router.GET("/test/actor", func(c *gin.Context) {
+			c.String(http.StatusOK, audit.ActorFrom(c.Request.Context()).IPAddress)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test/actor", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		res := a.Client().Do(req)
+		require.Equal(t, http.StatusOK, res.Code)
+
+		return res.Body.String()
+	}
+
+	t.Run("Ignores X-Forwarded-For By Default", func(t *testing.T) {
+		a := NewOffline(t, nil)
+		assert.Equal(t, "192.0.2.1", actorIP(a, "203.0.113.9"))
+	})
+
+	t.Run("Reads X-Forwarded-For From Trusted Proxies", func(t *testing.T) {
+		a := NewOffline(t, func(cfg *config.Config) {
+			cfg.TrustedProxies = []string{"192.0.2.0/24"}
+		})
+		assert.Equal(t, "203.0.113.9", actorIP(a, "203.0.113.9"))
+	})
+}
+
+func TestAuditLog_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+	path := "/workspaces/" + workspace.ID.String() + "/audit-logs"
+
+	list := func(query string) service.AuditLogsResponse {
+		res := client.Get(path + "?" + query)
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		var body service.AuditLogsResponse
+		res.Decode(&body)
+		return body
+	}
+
+	t.Run("Auth Events", func(t *testing.T) {
+		other := a.CreateUser()
+
+		res := a.Client().Post("/auth/login", gin.H{"email": other.Email, "password": "wrong password"})
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+		c := a.Login(other, fixture.DefaultPassword)
+		res = c.Post("/auth/logout", nil)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		entries, err := audit.List(ctx, a.Q, audit.Filter{EntityID: audit.ID(other.ID)}, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+
+		assert.Equal(t, audit.ActionLogout, entries[0].Action)
+		assert.Equal(t, audit.ActionLogin, entries[1].Action)
+		assert.Equal(t, audit.ActionLoginFailed, entries[2].Action)
+		assert.Equal(t, audit.ID(other.ID), entries[0].ActorID)
+		assert.False(t, entries[2].ActorID.Valid)
+		assert.NotEmpty(t, entries[1].IpAddress.String)
+		assert.NotEmpty(t, entries[1].RequestID.String)
+	})
+
+	t.Run("Spoofed Client Address", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login",
+			strings.NewReader(`{"email":"`+user.Email+`","password":"wrong password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		res := a.Client().Do(req)
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+		entries, err := audit.List(ctx, a.Q, audit.Filter{Action: audit.ActionLoginFailed}, 0, 1)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "192.0.2.1", entries[0].IpAddress.String, "the address of the connection")
+	})
+
+	t.Run("Data Changes", func(t *testing.T) {
+		tx, err := a.Db.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		actorCtx := audit.WithUserID(audit.WithActor(ctx, audit.Actor{RequestID: "req-1"}), user.ID)
+		require.NoError(t, audit.SetActor(actorCtx, tx))
+
+		accountId := uuid.New()
+		_, err = tx.Exec(ctx, `INSERT INTO accounts (id, name, user_id, workspace_id) VALUES ($1, 'Wallet', $2, $3)`,
+			accountId, user.ID, workspace.ID)
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, `UPDATE accounts SET name = 'Cash', updated_at = now() WHERE id = $1`, accountId)
+		require.NoError(t, err)
+		// nothing changed
+		_, err = tx.Exec(ctx, `UPDATE accounts SET updated_at = now() WHERE id = $1`, accountId)
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, `UPDATE accounts SET deleted_at = now() WHERE id = $1`, accountId)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(ctx))
+
+		body := list("entity_type=account&entity_id=" + accountId.String())
+		require.Len(t, body.Data, 3)
+		assert.Nil(t, body.Next)
+
+		deleted, updated, created := body.Data[0], body.Data[1], body.Data[2]
+		assert.Equal(t, "deleted", deleted.Action)
+		assert.Equal(t, "updated", updated.Action)
+		assert.Equal(t, "created", created.Action)
+
+		assert.JSONEq(t, `{"name":"Wallet"}`, string(updated.Before))
+		assert.JSONEq(t, `{"name":"Cash"}`, string(updated.After))
+		assert.Nil(t, created.Before)
+		assert.Contains(t, string(created.After), `"Wallet"`)
+
+		for _, e := range body.Data {
+			assert.Equal(t, audit.ID(user.ID), e.ActorID)
+			assert.Equal(t, audit.ID(workspace.ID), e.WorkspaceID)
+			assert.Equal(t, "req-1", e.RequestID.String)
+		}
+
+		t.Run("Pagination", func(t *testing.T) {
+			page := list("entity_type=account&limit=2")
+			require.Len(t, page.Data, 2)
+			require.NotNil(t, page.Next)
+
+			page = list("entity_type=account&limit=2&before=" + strconv.FormatInt(*page.Next, 10))
+			require.Len(t, page.Data, 1)
+			assert.Equal(t, created.ID, page.Data[0].ID)
+		})
+
+		t.Run("Export", func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := audit.Export(ctx, a.Q, audit.Filter{WorkspaceID: audit.ID(workspace.ID)}, &buf, audit.FormatCSV)
+			require.NoError(t, err)
+			assert.Equal(t, 3, n)
+			assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 4)
+
+			buf.Reset()
+			n, err = audit.Export(ctx, a.Q, audit.Filter{WorkspaceID: audit.ID(workspace.ID)}, &buf, audit.FormatJSON)
+			require.NoError(t, err)
+			assert.Equal(t, 3, n)
+
+			var first map[string]any
+			require.NoError(t, json.NewDecoder(&buf).Decode(&first))
+			assert.Equal(t, "deleted", first["action"])
+		})
+	})
+
+	t.Run("Role Changes", func(t *testing.T) {
+		_, err := a.Db.Exec(ctx, `UPDATE users SET role = 'admin' WHERE id = $1`, user.ID)
+		require.NoError(t, err)
+
+		entries, err := audit.List(ctx, a.Q, audit.Filter{EntityID: audit.ID(user.ID), Action: "role_changed"}, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.JSONEq(t, `{"role":"user"}`, string(entries[0].Before))
+		assert.JSONEq(t, `{"role":"admin"}`, string(entries[0].After))
+	})
+
+	t.Run("Append Only", func(t *testing.T) {
+		_, err := a.Db.Exec(ctx, `UPDATE audit_logs SET action = 'nothing'`)
+		assert.ErrorContains(t, err, "append-only")
+
+		_, err = a.Db.Exec(ctx, `DELETE FROM audit_logs`)
+		assert.ErrorContains(t, err, "append-only")
+	})
+
+	t.Run("Other Workspace", func(t *testing.T) {
+		other, _ := a.AuthClient()
+
+		res := other.Get(path)
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
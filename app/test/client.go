@@ -0,0 +1,215 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/fixture"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/stretchr/testify/require"
+)
+
+// Client makes requests to the app like a browser would, keeping the cookies
+// it gets and sending its CSRF token and languages
+type Client struct {
+	t         *testing.T
+	app       *App
+	cookies   map[string]*http.Cookie
+	CSRFToken string
+	// Language is sent as Accept-Language
+	Language string
+}
+
+// Response is the recorded response of a request
+type Response struct {
+	*httptest.ResponseRecorder
+	t *testing.T
+}
+
+// Client returns a client without a session
+func (a *App) Client() *Client {
+	return &Client{t: a.t, app: a, cookies: map[string]*http.Cookie{}}
+}
+
+// CreateUser adds a user with a workspace. Its password is
+// fixture.DefaultPassword.
+func (a *App) CreateUser() *model.User {
+	u := fixture.GetMockUser()
+	u.Password = ""
+
+	user, _, err := fixture.CreateUser(a.Ctx, a.Q, u)
+	require.NoError(a.t, err)
+
+	return user
+}
+
+// Workspace returns the workspace created with user
+func (a *App) Workspace(user *model.User) *model.Workspace {
+	workspaces, err := a.Q.GetUserWorkspaces(a.Ctx, user.ID)
+	require.NoError(a.t, err)
+	require.NotEmpty(a.t, workspaces)
+
+	return workspaces[0]
+}
+
+// Login returns a client logged in as user, with a CSRF token
+func (a *App) Login(user *model.User, password string) *Client {
+	c := a.Client()
+
+	res := c.Post("/auth/login", map[string]string{
+		"email":    user.Email,
+		"password": password,
+	})
+	require.Equal(a.t, http.StatusOK, res.Code, res.Body.String())
+
+	c.CSRFToken = Data[struct {
+		Token string `json:"token"`
+	}](c.Get("/auth/csrf")).Token
+
+	return c
+}
+
+// AuthClient creates a user and returns a client logged in as them
+func (a *App) AuthClient() (*Client, *model.User) {
+	user := a.CreateUser()
+	return a.Login(user, fixture.DefaultPassword), user
+}
+
+// FinishJob runs the background jobs and returns the job res started, as c
+// gets it once they ran
+func (a *App) FinishJob(c *Client, res *Response) *service.JobRunResponse {
+	a.t.Helper()
+
+	require.Equal(a.t, http.StatusAccepted, res.Code, res.Body.String())
+	location := res.Header().Get("Location")
+	require.NotEmpty(a.t, location)
+
+	a.RunJobs()
+
+	res = c.Get(location)
+	require.Equal(a.t, http.StatusOK, res.Code, res.Body.String())
+
+	return Data[*service.JobRunResponse](res)
+}
+
+// Get sends a GET request
+func (c *Client) Get(path string) *Response {
+	return c.Do(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+// Post sends a POST request with body encoded as JSON, if any
+func (c *Client) Post(path string, body any) *Response {
+	return c.Do(c.jsonRequest(http.MethodPost, path, body))
+}
+
+// Put sends a PUT request with body encoded as JSON, if any
+func (c *Client) Put(path string, body any) *Response {
+	return c.Do(c.jsonRequest(http.MethodPut, path, body))
+}
+
+// Patch sends a PATCH request with body encoded as JSON, if any
+func (c *Client) Patch(path string, body any) *Response {
+	return c.Do(c.jsonRequest(http.MethodPatch, path, body))
+}
+
+// Upload sends a multipart POST request with content as the file field
+func (c *Client) Upload(path, field, filename, contentType string, content []byte) *Response {
+	c.t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, filename))
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	require.NoError(c.t, err)
+	_, err = part.Write(content)
+	require.NoError(c.t, err)
+	require.NoError(c.t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	return c.Do(req)
+}
+
+// Delete sends a DELETE request
+func (c *Client) Delete(path string) *Response {
+	return c.Do(httptest.NewRequest(http.MethodDelete, path, nil))
+}
+
+// Do sends req with the cookies, CSRF token and language of the client
+func (c *Client) Do(req *http.Request) *Response {
+	c.t.Helper()
+
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	if c.CSRFToken != "" {
+		req.Header.Set(model.CSRFHeader, c.CSRFToken)
+	}
+	if c.Language != "" {
+		req.Header.Set("Accept-Language", c.Language)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	rr := httptest.NewRecorder()
+	c.app.Router.ServeHTTP(rr, req)
+
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(c.cookies, cookie.Name)
+			continue
+		}
+		c.cookies[cookie.Name] = cookie
+	}
+
+	return &Response{ResponseRecorder: rr, t: c.t}
+}
+
+func (c *Client) jsonRequest(method, path string, body any) *http.Request {
+	c.t.Helper()
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(c.t, err)
+		r = bytes.NewReader(b)
+	}
+
+	req := httptest.NewRequest(method, path, r)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req
+}
+
+// Decode decodes the JSON body into v
+func (r *Response) Decode(v any) {
+	r.t.Helper()
+	require.NoError(r.t, json.Unmarshal(r.Body.Bytes(), v), r.Body.String())
+}
+
+// Data decodes the data field of a JSON response
+func Data[T any](r *Response) T {
+	r.t.Helper()
+
+	var body struct {
+		Data T `json:"data"`
+	}
+	r.Decode(&body)
+
+	return body.Data
+}
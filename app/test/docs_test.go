@@ -0,0 +1,231 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app"
+	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/docs"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// ginParam matches the :name and *name params of gin paths
+var ginParam = regexp.MustCompile(`[:*](\w+)`)
+
+// specParam matches the {name} params of OpenAPI paths
+var specParam = regexp.MustCompile(`\{(\w+)\}`)
+
+func TestMain_DocsE2E(t *testing.T) {
+	a := NewOffline(t, func(cfg *config.Config) {
+		// serve /metrics on the app so it is checked too
+		cfg.MetricsPort = ""
+		cfg.MetricsPassword = "secret"
+	})
+
+	var spec map[string]any
+	require.NoError(t, yaml.Unmarshal(docs.YAML(), &spec))
+
+	t.Run("Routes match the spec", func(t *testing.T) {
+		var routes []string
+		for _, r := range a.Router.Routes() {
+			if r.Method == http.MethodHead || strings.HasPrefix(r.Path, "/assets/") {
+				continue
+			}
+			routes = append(routes, r.Method+" "+ginParam.ReplaceAllString(r.Path, "{$1}"))
+		}
+
+		var documented []string
+		for path, item := range spec["paths"].(map[string]any) {
+			for method := range item.(map[string]any) {
+				if contains(operationMethods, method) {
+					documented = append(documented, strings.ToUpper(method)+" "+path)
+				}
+			}
+		}
+
+		sort.Strings(routes)
+		sort.Strings(documented)
+		assert.Equal(t, routes, documented, "routes and docs/openapi.yaml drifted apart")
+	})
+
+	t.Run("Operations are complete", func(t *testing.T) {
+		for path, item := range spec["paths"].(map[string]any) {
+			want := specParam.FindAllStringSubmatch(path, -1)
+
+			for method, op := range item.(map[string]any) {
+				if !contains(operationMethods, method) {
+					continue
+				}
+				op := op.(map[string]any)
+				name := strings.ToUpper(method) + " " + path
+
+				assert.NotEmpty(t, op["operationId"], name)
+				assert.NotEmpty(t, op["responses"], name)
+
+				var params []string
+				for _, p := range asSlice(op["parameters"]) {
+					p := resolve(t, spec, p)
+					if p["in"] == "path" {
+						params = append(params, p["name"].(string))
+					}
+				}
+				var names []string
+				for _, m := range want {
+					names = append(names, m[1])
+				}
+				assert.ElementsMatch(t, names, params, "path params of %s", name)
+			}
+		}
+	})
+
+	t.Run("References resolve", func(t *testing.T) {
+		walk(spec, func(ref string) {
+			_, ok := lookup(spec, ref)
+			assert.True(t, ok, "unresolved $ref %s", ref)
+		})
+	})
+
+	t.Run("Error schemas match the Go types", func(t *testing.T) {
+		schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+
+		for name, v := range map[string]any{
+			"Error":         model.HttpError{},
+			"ErrorResponse": model.ErrorResponse{},
+			"FieldError":    model.FieldError{},
+		} {
+			var props []string
+			for prop := range schemas[name].(map[string]any)["properties"].(map[string]any) {
+				props = append(props, prop)
+			}
+			assert.ElementsMatch(t, jsonFields(v), props, name)
+		}
+
+		var codes []any
+		for _, typ := range apperrors.Types {
+			codes = append(codes, string(typ))
+		}
+		code := schemas["Error"].(map[string]any)["properties"].(map[string]any)["code"].(map[string]any)
+		assert.ElementsMatch(t, codes, code["enum"], "error codes")
+	})
+
+	t.Run("Webhook events match the Go ones", func(t *testing.T) {
+		schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+
+		var events []any
+		for _, e := range webhooks.Events {
+			events = append(events, e)
+		}
+		assert.ElementsMatch(t, events, schemas["WebhookEvent"].(map[string]any)["enum"])
+	})
+
+	t.Run("Version matches the app", func(t *testing.T) {
+		assert.Equal(t, app.Version, spec["info"].(map[string]any)["version"])
+	})
+
+	t.Run("Serves the docs", func(t *testing.T) {
+		client := a.Client()
+
+		res := client.Get("/docs/openapi.json")
+		require.Equal(t, http.StatusOK, res.Code)
+		var served map[string]any
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &served))
+		assert.Equal(t, spec["openapi"], served["openapi"])
+
+		res = client.Get("/docs/openapi.yaml")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, docs.YAML(), res.Body.Bytes())
+
+		res = client.Get("/docs")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), "/docs/openapi.json")
+		assert.Contains(t, res.Body.String(), `id="NOTFOUND"`)
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+// resolve follows the $ref of v, if any
+func resolve(t *testing.T, spec map[string]any, v any) map[string]any {
+	m := v.(map[string]any)
+	if ref, ok := m["$ref"].(string); ok {
+		target, found := lookup(spec, ref)
+		require.True(t, found, "unresolved $ref %s", ref)
+		return target.(map[string]any)
+	}
+	return m
+}
+
+// lookup returns the value of a local JSON pointer like #/components/schemas/Error
+func lookup(spec map[string]any, ref string) (any, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+
+	var v any = spec
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// walk calls fn with every $ref in v
+func walk(v any, fn func(ref string)) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if ref, ok := child.(string); ok && k == "$ref" {
+				fn(ref)
+				continue
+			}
+			walk(child, fn)
+		}
+	case []any:
+		for _, child := range v {
+			walk(child, fn)
+		}
+	}
+}
+
+// jsonFields returns the JSON names of the fields of the struct v
+func jsonFields(v any) []string {
+	var names []string
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorOf decodes the error envelope of res
+func errorOf(t *testing.T, res *Response) model.HttpError {
+	var body model.ErrorResponse
+	res.Decode(&body)
+	require.NotEmpty(t, body.Error.Code, res.Body.String())
+
+	return body.Error
+}
+
+func TestMain_ErrorsE2E(t *testing.T) {
+	a := NewOffline(t, func(cfg *config.Config) {
+		cfg.RateLimit = 5
+	})
+
+	t.Run("No Route", func(t *testing.T) {
+		res := a.Client().Get("/no-such-route")
+		assert.Equal(t, http.StatusNotFound, res.Code)
+
+		e := errorOf(t, res)
+		assert.Equal(t, apperrors.NotFound, e.Code)
+		assert.Contains(t, e.Message, "/docs")
+		assert.Equal(t, res.Header().Get(model.RequestIDHeader), e.RequestID)
+		assert.Equal(t, "/docs#NOTFOUND", e.Docs)
+	})
+
+	t.Run("No Route For Browsers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/no-such-route", nil)
+		req.Header.Set("Accept", "text/html")
+		res := a.Client().Do(req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Contains(t, res.Body.String(), "No route found")
+	})
+
+	t.Run("Not Logged In", func(t *testing.T) {
+		res := a.Client().Get("/auth/me")
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+		assert.Equal(t, apperrors.Authorization, errorOf(t, res).Code)
+	})
+
+	t.Run("Invalid Fields", func(t *testing.T) {
+		res := a.Client().Post("/auth/login", map[string]string{"email": "not an email"})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		e := errorOf(t, res)
+		assert.Equal(t, apperrors.Validation, e.Code)
+		assert.ElementsMatch(t, []model.FieldError{
+			{Field: "Email", Message: "email is not valid"},
+			{Field: "Password", Message: "cannot be empty"},
+		}, e.Fields)
+	})
+
+	t.Run("Rate Limited", func(t *testing.T) {
+		var res *Response
+		for i := 0; i <= 5; i++ {
+			res = a.Client().Get("/auth/csrf")
+		}
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.NotEmpty(t, res.Header().Get("X-RateLimit-Reset"))
+		assert.Equal(t, apperrors.TooManyRequests, errorOf(t, res).Code)
+	})
+}
+
+func TestMain_AccountErrorsE2E(t *testing.T) {
+	a := New(t)
+
+	t.Run("Duplicate Email", func(t *testing.T) {
+		user := a.CreateUser()
+		client := a.Client()
+
+		res := client.Post("/auth/register", map[string]string{
+			"first_name": "Jane",
+			"last_name":  "Doe",
+			"email":      user.Email,
+			"password":   fixture.DefaultPassword,
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		e := errorOf(t, res)
+		assert.Equal(t, apperrors.Validation, e.Code)
+		assert.Equal(t, []model.FieldError{{Field: "Email", Message: apperrors.DuplicateEmail}}, e.Fields)
+
+		// no session is started for the failed registration
+		assert.Equal(t, http.StatusUnauthorized, client.Get("/auth/me").Code)
+	})
+
+	t.Run("Forgot Password Of Unknown Email", func(t *testing.T) {
+		res := a.Client().Post("/auth/forgot-password", map[string]string{"email": "nobody@example.com"})
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, "true", res.Body.String())
+	})
+}
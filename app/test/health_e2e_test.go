@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain_ReadyzDraining(t *testing.T) {
+	a := NewOffline(t, nil)
+	client := a.Client()
+
+	a.Health.SetReady(false)
+	t.Cleanup(func() { a.Health.SetReady(true) })
+
+	res := client.Get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+
+	var report service.HealthReport
+	res.Decode(&report)
+	assert.Equal(t, service.HealthDraining, report.Status)
+	assert.Empty(t, report.Checks)
+
+	res = client.Get("/healthz")
+	assert.Equal(t, http.StatusOK, res.Code, "the process is still alive")
+}
+
+func TestReadyz_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client := a.Client()
+
+	readyz := func() (int, service.HealthReport) {
+		res := client.Get("/readyz")
+		var report service.HealthReport
+		res.Decode(&report)
+		return res.Code, report
+	}
+
+	code, report := readyz()
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, service.HealthOK, report.Status)
+	for _, name := range []string{"database", "redis", "migrations"} {
+		assert.Equal(t, service.HealthOK, report.Checks[name].Status, name)
+	}
+
+	t.Run("Migrations", func(t *testing.T) {
+		latest, err := db.LatestVersion()
+		require.NoError(t, err)
+
+		// truncating the tables between tests keeps schema_migrations
+		t.Cleanup(func() {
+			_, err := a.Db.Exec(ctx, `UPDATE schema_migrations SET version = $1, dirty = false`, latest)
+			require.NoError(t, err)
+		})
+
+		for name, sql := range map[string]string{
+			"Behind": `UPDATE schema_migrations SET version = version - 1`,
+			"Dirty":  `UPDATE schema_migrations SET dirty = true`,
+		} {
+			_, err := a.Db.Exec(ctx, `UPDATE schema_migrations SET version = $1, dirty = false`, latest)
+			require.NoError(t, err)
+			_, err = a.Db.Exec(ctx, sql)
+			require.NoError(t, err)
+
+			code, report := readyz()
+			assert.Equal(t, http.StatusServiceUnavailable, code, name)
+			assert.Equal(t, service.HealthUnavailable, report.Status, name)
+			assert.Equal(t, service.HealthFailing, report.Checks["migrations"].Status, name)
+			assert.Equal(t, service.HealthCheckFailed, report.Checks["migrations"].Error, name)
+			assert.Equal(t, service.HealthOK, report.Checks["database"].Status, name)
+		}
+	})
+
+	t.Run("Draining", func(t *testing.T) {
+		a.Health.SetReady(false)
+		t.Cleanup(func() { a.Health.SetReady(true) })
+
+		code, report := readyz()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, service.HealthDraining, report.Status)
+	})
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/model/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain_I18nE2E(t *testing.T) {
+	a := NewOffline(t, nil)
+
+	t.Run("Default Language", func(t *testing.T) {
+		res := a.Client().Get("/no-such-route")
+		assert.Equal(t, "en", res.Header().Get("Content-Language"))
+		assert.Contains(t, errorOf(t, res).Message, "No route found")
+	})
+
+	t.Run("Accept-Language", func(t *testing.T) {
+		c := a.Client()
+		c.Language = "pt-BR,pt;q=0.9,en;q=0.8"
+
+		res := c.Get("/no-such-route")
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Equal(t, "pt", res.Header().Get("Content-Language"))
+		assert.Equal(t, "Rota não encontrada. Veja /docs para a lista de rotas", errorOf(t, res).Message)
+	})
+
+	t.Run("Unknown Language", func(t *testing.T) {
+		c := a.Client()
+		c.Language = "ja"
+
+		res := c.Get("/no-such-route")
+		assert.Equal(t, "en", res.Header().Get("Content-Language"))
+		assert.Contains(t, errorOf(t, res).Message, "No route found")
+	})
+
+	t.Run("Invalid Fields", func(t *testing.T) {
+		c := a.Client()
+		c.Language = "pt"
+
+		res := c.Post("/auth/login", map[string]string{"email": "not an email"})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		e := errorOf(t, res)
+		assert.Equal(t, apperrors.Validation, e.Code)
+		assert.Equal(t, "Alguns campos são inválidos", e.Message)
+		assert.ElementsMatch(t, []model.FieldError{
+			{Field: "Email", Message: "email inválido"},
+			{Field: "Password", Message: "não pode ficar vazio"},
+		}, e.Fields)
+	})
+
+	t.Run("Error Page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/no-such-route", nil)
+		req.Header.Set("Accept", "text/html")
+		req.Header.Set("Accept-Language", "pt")
+		res := a.Client().Do(req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Contains(t, res.Body.String(), `<html lang="pt"`)
+		assert.Contains(t, res.Body.String(), "Não encontrado")
+		assert.Contains(t, res.Body.String(), "Voltar")
+	})
+
+	t.Run("Home Page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "text/html")
+		req.Header.Set("Accept-Language", "pt")
+		res := a.Client().Do(req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), "Início")
+		assert.Contains(t, res.Body.String(), "Enviar")
+	})
+}
+
+func TestMain_ProfileLanguageE2E(t *testing.T) {
+	a := New(t)
+
+	user := a.CreateUser()
+	_, err := a.Db.Exec(a.Ctx,
+		`INSERT INTO profiles (name, currency, language, user_id) VALUES ($1, 'brl', 'pt-BR', $2)`,
+		user.FirstName, user.ID)
+	require.NoError(t, err)
+
+	c := a.Client()
+	c.Language = "en"
+	res := c.Post("/auth/login", map[string]string{
+		"email":    user.Email,
+		"password": fixture.DefaultPassword,
+	})
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	// the profile wins over Accept-Language
+	res = c.Get("/no-such-route")
+	assert.Equal(t, "pt", res.Header().Get("Content-Language"))
+	assert.Contains(t, errorOf(t, res).Message, "Rota não encontrada")
+
+	c.CSRFToken = Data[struct {
+		Token string `json:"token"`
+	}](c.Get("/auth/csrf")).Token
+	res = c.Post("/auth/logout", nil)
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	res = c.Get("/no-such-route")
+	assert.Equal(t, "en", res.Header().Get("Content-Language"))
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/jobs"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+	base := "/workspaces/" + workspace.ID.String()
+
+	groceries, card := uuid.New(), uuid.New()
+	_, err := a.Db.Exec(ctx, `INSERT INTO categories (id, name, c_type, user_id, workspace_id) VALUES ($1, 'Groceries', 'expense', $2, $3)`,
+		groceries, user.ID, workspace.ID)
+	require.NoError(t, err)
+	_, err = a.Db.Exec(ctx, `INSERT INTO accounts (id, name, user_id, workspace_id) VALUES ($1, 'Card', $2, $3)`,
+		card, user.ID, workspace.ID)
+	require.NoError(t, err)
+
+	transaction := func(title, value string) map[string]any {
+		return map[string]any{
+			"title":       title,
+			"value":       value,
+			"account_id":  card.String(),
+			"category_id": groceries.String(),
+			"handled_at":  "2023-05-02T08:00:00Z",
+		}
+	}
+
+	count := func() int {
+		var n int
+		err := a.Db.QueryRow(ctx, `SELECT count(*) FROM transactions WHERE workspace_id = $1`, workspace.ID).Scan(&n)
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("Import", func(t *testing.T) {
+		res := client.Post(base+"/transactions/import", map[string]any{
+			"transactions": []map[string]any{transaction("Market", "-30"), transaction("Bakery", "-3")},
+		})
+		require.Equal(t, http.StatusAccepted, res.Code, res.Body.String())
+		started := Data[*service.JobRunResponse](res)
+		assert.Equal(t, service.JobRunImport, started.Kind)
+		assert.Equal(t, service.JobRunPending, started.Status)
+		assert.Equal(t, base+"/jobs/"+started.ID.String(), res.Header().Get("Location"))
+		assert.Equal(t, 0, count(), "nothing is imported in the request")
+
+		run := a.FinishJob(client, res)
+		require.Equal(t, service.JobRunSucceeded, run.Status, run.Error)
+		assert.True(t, run.FinishedAt.Valid)
+		var result service.ImportResult
+		require.NoError(t, json.Unmarshal(run.Result, &result))
+		assert.Equal(t, service.ImportResult{Imported: 2}, result)
+		assert.Equal(t, 2, count())
+
+		// a job running again finds the run finished
+		_, err := a.Jobs.Enqueue(ctx, jobs.QueueImports, service.JobImportTransactions, service.JobRunMessage{RunID: run.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, a.RunJobs())
+		assert.Equal(t, 2, count())
+	})
+
+	t.Run("Invalid Import Fails In The Language Of The Request", func(t *testing.T) {
+		bad := transaction("Yacht", "-100000000")
+		run := a.FinishJob(client, client.Post(base+"/transactions/import", map[string]any{
+			"transactions": []map[string]any{transaction("Market", "-30"), bad},
+		}))
+		assert.Equal(t, service.JobRunFailed, run.Status)
+		require.NotNil(t, run.Error)
+		assert.Equal(t, apperrors.Validation, run.Error.Code)
+		assert.Equal(t, []model.FieldError{{Field: "Transactions[1].Value", Message: apperrors.ValueOutOfRange}}, run.Error.Fields)
+		assert.Empty(t, run.Result)
+		assert.Equal(t, 2, count(), "none of them is imported")
+
+		client.Language = "pt"
+		defer func() { client.Language = "" }()
+		res := client.Get(base + "/jobs/" + run.ID.String())
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.NotEqual(t, apperrors.ValueOutOfRange, Data[*service.JobRunResponse](res).Error.Fields[0].Message)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		res := client.Post(base+"/reports", map[string]any{"report": "nope"})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		run := a.FinishJob(client, client.Post(base+"/reports", map[string]any{
+			"report":     service.ReportCategories,
+			"account_id": card.String(),
+		}))
+		require.Equal(t, service.JobRunSucceeded, run.Status, run.Error)
+		assert.Equal(t, service.JobRunReport, run.Kind)
+
+		var rows []*model.GetCategoryReportRow
+		require.NoError(t, json.Unmarshal(run.Result, &rows))
+		require.Len(t, rows, 1)
+		assert.Equal(t, groceries, rows[0].CategoryID)
+	})
+
+	t.Run("Other Workspace", func(t *testing.T) {
+		res := client.Post(base+"/reports", map[string]any{"report": service.ReportTags})
+		require.Equal(t, http.StatusAccepted, res.Code, res.Body.String())
+
+		other, _ := a.AuthClient()
+		res = other.Get(res.Header().Get("Location"))
+		assert.Equal(t, http.StatusNotFound, res.Code)
+
+		res = client.Get(base + "/jobs/" + uuid.NewString())
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterOutbox_E2E(t *testing.T) {
+	a := New(t)
+
+	register := func(email string) *Response {
+		return a.Client().Post("/auth/register", gin.H{
+			"first_name": "Jane",
+			"last_name":  "Doe",
+			"email":      email,
+			"password":   "password1234",
+		})
+	}
+
+	res := register("jane@example.com")
+	require.Equal(t, http.StatusCreated, res.Code)
+
+	// the email is queued by the relay, not by the request
+	assert.Equal(t, 0, a.RunJobs())
+	assert.Equal(t, 1, a.RelayEvents())
+	assert.Equal(t, 0, a.RelayEvents(), "published events are not relayed again")
+	assert.Equal(t, 1, a.RunJobs())
+
+	messages := a.Mail.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"jane@example.com"}, messages[0].To)
+	assert.Contains(t, messages[0].Text, "Jane")
+
+	// the registration rolls back, so does its event
+	res = register("jane@example.com")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, 0, a.RelayEvents())
+}
+
+func TestRelay_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+
+	publish := func(topic string, payload any) {
+		tx, err := a.Db.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		require.NoError(t, outbox.Publish(ctx, a.Q.WithTx(tx), topic, payload))
+		require.NoError(t, tx.Commit(ctx))
+	}
+
+	t.Run("Retries Failed Events", func(t *testing.T) {
+		var got []int
+		a.Outbox.Subscribe("test.failing", func(ctx context.Context, e *outbox.Event) error {
+			var n int
+			require.NoError(t, e.Decode(&n))
+			got = append(got, n)
+
+			if len(got) == 1 {
+				return errors.New("boom")
+			}
+			return nil
+		})
+
+		publish("test.failing", 42)
+
+		assert.Equal(t, 1, a.RelayEvents())
+		// the retry is scheduled later
+		assert.Equal(t, 0, a.RelayEvents())
+
+		_, err := a.Db.Exec(ctx, "UPDATE outbox_events SET available_at = now() WHERE topic = 'test.failing'")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, a.RelayEvents())
+		assert.Equal(t, []int{42, 42}, got)
+
+		var attempts int
+		var lastError string
+		err = a.Db.QueryRow(ctx, "SELECT attempts, last_error FROM outbox_events WHERE topic = 'test.failing'").
+			Scan(&attempts, &lastError)
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, "boom", lastError)
+	})
+
+	t.Run("Rolled Back Events", func(t *testing.T) {
+		tx, err := a.Db.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, outbox.Publish(ctx, a.Q.WithTx(tx), "test.rollback", 1))
+		require.NoError(t, tx.Rollback(ctx))
+
+		assert.Equal(t, 0, a.RelayEvents())
+	})
+}
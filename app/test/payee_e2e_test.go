@@ -0,0 +1,151 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayees_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+	base := "/workspaces/" + workspace.ID.String()
+
+	exec := func(sql string, args ...any) {
+		_, err := a.Db.Exec(ctx, sql, args...)
+		require.NoError(t, err)
+	}
+
+	shopping, groceries, card := uuid.New(), uuid.New(), uuid.New()
+	exec(`INSERT INTO categories (id, name, c_type, user_id, workspace_id) VALUES ($1, 'Shopping', 'expense', $3, $4), ($2, 'Groceries', 'expense', $3, $4)`,
+		shopping, groceries, user.ID, workspace.ID)
+	exec(`INSERT INTO accounts (id, name, user_id, workspace_id) VALUES ($1, 'Card', $2, $3)`,
+		card, user.ID, workspace.ID)
+
+	transaction := func(title, value string) map[string]any {
+		return map[string]any{
+			"title":       title,
+			"value":       value,
+			"account_id":  card.String(),
+			"category_id": groceries.String(),
+			"handled_at":  "2023-05-02T08:00:00Z",
+		}
+	}
+
+	// created before the payee, linked when it's saved
+	res := client.Post(base+"/transactions", transaction("AMZN MKTP US*2K4", "-30"))
+	require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+	old := Data[*service.CreatedTransaction](res)
+	assert.False(t, old.PayeeID.Valid)
+
+	var amazon *service.SavedPayee
+
+	t.Run("Create Payees", func(t *testing.T) {
+		res := client.Post(base+"/payees", map[string]any{"name": "Amazon", "aliases": []string{"(amzn"}})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "Aliases", Message: apperrors.InvalidPattern}}, errorOf(t, res).Fields)
+
+		res = client.Post(base+"/payees", map[string]any{"name": "Amazon", "category_id": uuid.NewString()})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "CategoryID", Message: apperrors.UnknownCategory}}, errorOf(t, res).Fields)
+
+		res = client.Post(base+"/payees", map[string]any{
+			"name":        "Amazon",
+			"aliases":     []string{`^amzn\b`},
+			"category_id": shopping.String(),
+		})
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		amazon = Data[*service.SavedPayee](res)
+		assert.Equal(t, int64(1), amazon.Linked)
+
+		res = client.Post(base+"/payees", map[string]any{"name": "AMAZON"})
+		assert.Equal(t, http.StatusConflict, res.Code)
+
+		res = client.Post(base+"/payees", map[string]any{"name": "Market"})
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		assert.Equal(t, int64(0), Data[*service.SavedPayee](res).Linked)
+
+		list := Data[[]*model.Payee](client.Get(base + "/payees"))
+		require.Len(t, list, 2)
+		assert.Equal(t, "Amazon", list[0].Name)
+	})
+
+	t.Run("Payees Link On Create", func(t *testing.T) {
+		tx := transaction("Amazon.com", "-12.50")
+		delete(tx, "category_id")
+		res := client.Post(base+"/transactions", tx)
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		created := Data[*service.CreatedTransaction](res)
+		assert.Equal(t, amazon.ID, created.PayeeID.UUID)
+		assert.Equal(t, shopping, created.CategoryID, "the category of the payee")
+
+		// a category given wins
+		res = client.Post(base+"/transactions", transaction("amzn digital", "-2"))
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		created = Data[*service.CreatedTransaction](res)
+		assert.Equal(t, amazon.ID, created.PayeeID.UUID)
+		assert.Equal(t, groceries, created.CategoryID)
+
+		tx = transaction("Corner shop", "-4")
+		tx["payee_id"] = uuid.NewString()
+		res = client.Post(base+"/transactions", tx)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "PayeeID", Message: apperrors.UnknownPayee}}, errorOf(t, res).Fields)
+
+		run := a.FinishJob(client, client.Post(base+"/transactions/import", map[string]any{
+			"transactions": []map[string]any{transaction("AMZN Prime", "-9.99"), transaction("Bakery", "-3")},
+		}))
+		require.Equal(t, service.JobRunSucceeded, run.Status, run.Error)
+	})
+
+	t.Run("Update Transaction Payee", func(t *testing.T) {
+		res := client.Patch(base+"/transactions/"+old.ID.String(), map[string]any{"payee_id": ""})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.False(t, Data[*model.UpdateTransactionRow](res).PayeeID.Valid)
+
+		res = client.Patch(base+"/transactions/"+old.ID.String(), map[string]any{"payee_id": amazon.ID.String()})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.Equal(t, amazon.ID, Data[*model.UpdateTransactionRow](res).PayeeID.UUID)
+	})
+
+	t.Run("Payee Report", func(t *testing.T) {
+		res := client.Get(base + "/reports/payees")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		report := Data[[]struct {
+			PayeeID      uuid.UUID `json:"payee_id"`
+			PayeeName    string    `json:"payee_name"`
+			Total        float64   `json:"total"`
+			Transactions int64     `json:"transactions"`
+		}](res)
+		require.Len(t, report, 1)
+		assert.Equal(t, "Amazon", report[0].PayeeName)
+		assert.Equal(t, -54.49, report[0].Total)
+		assert.Equal(t, int64(4), report[0].Transactions)
+
+		res = client.Get(base + "/reports/payees?category_id=" + shopping.String())
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.Len(t, Data[[]map[string]any](res), 1)
+	})
+
+	t.Run("Delete Payee", func(t *testing.T) {
+		res := client.Delete(base + "/payees/" + amazon.ID.String())
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		var linked int
+		err := a.Db.QueryRow(ctx, `SELECT count(*) FROM transactions WHERE workspace_id = $1 AND payee_id IS NOT NULL`, workspace.ID).Scan(&linked)
+		require.NoError(t, err)
+		assert.Equal(t, 0, linked)
+
+		res = client.Delete(base + "/payees/" + amazon.ID.String())
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,297 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/opchaves/gin-web-app/app/rules"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+	base := "/workspaces/" + workspace.ID.String()
+
+	exec := func(sql string, args ...any) {
+		_, err := a.Db.Exec(ctx, sql, args...)
+		require.NoError(t, err)
+	}
+
+	groceries, transport, other, wallet, card := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	exec(`INSERT INTO categories (id, name, c_type, user_id, workspace_id) VALUES ($1, 'Groceries', 'expense', $3, $4), ($2, 'Transport', 'expense', $3, $4)`,
+		groceries, transport, user.ID, workspace.ID)
+	exec(`INSERT INTO accounts (id, name, user_id, workspace_id) VALUES ($1, 'Wallet', $3, $4), ($2, 'Card', $3, $4)`,
+		wallet, card, user.ID, workspace.ID)
+
+	transaction := func(title, value string, category uuid.UUID) map[string]any {
+		t := map[string]any{
+			"title":      title,
+			"value":      value,
+			"account_id": card.String(),
+			"handled_at": "2023-05-02T08:00:00Z",
+		}
+		if category != uuid.Nil {
+			t["category_id"] = category.String()
+		}
+		return t
+	}
+
+	var uber *model.Rule
+
+	t.Run("Invalid Rules", func(t *testing.T) {
+		res := client.Post(base+"/rules", map[string]any{
+			"name": "Broken", "title_pattern": "(uber", "set_category_id": transport.String(),
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "TitlePattern", Message: apperrors.InvalidPattern}}, errorOf(t, res).Fields)
+
+		res = client.Post(base+"/rules", map[string]any{"name": "Everything", "set_category_id": transport.String()})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, errorOf(t, res).Message, apperrors.RuleWithoutCondition)
+
+		res = client.Post(base+"/rules", map[string]any{"name": "Nothing", "title_pattern": "uber"})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, errorOf(t, res).Message, apperrors.RuleWithoutAction)
+
+		res = client.Post(base+"/rules", map[string]any{"name": "Huge", "min_value": "100000000", "set_category_id": transport.String()})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "MinValue", Message: apperrors.ValueOutOfRange}}, errorOf(t, res).Fields)
+
+		res = client.Post(base+"/rules", map[string]any{"name": "Other", "title_pattern": "uber", "set_category_id": other.String()})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "CategoryID", Message: apperrors.UnknownCategory}}, errorOf(t, res).Fields)
+	})
+
+	t.Run("Create Rules", func(t *testing.T) {
+		res := client.Post(base+"/rules", map[string]any{
+			"name":            "Uber",
+			"position":        1,
+			"title_pattern":   `^uber\s`,
+			"max_value":       "0",
+			"set_category_id": transport.String(),
+			"add_tags":        []string{"Rides"},
+			"set_title":       "Uber",
+		})
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		uber = Data[*model.Rule](res)
+		assert.True(t, uber.Active)
+		assert.Equal(t, []string{"Rides"}, uber.AddTags)
+
+		res = client.Post(base+"/rules", map[string]any{
+			"name":         "Card",
+			"position":     2,
+			"account_id":   card.String(),
+			"add_tags":     []string{"Card"},
+			"note_pattern": "",
+		})
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+
+		list := Data[[]*model.Rule](client.Get(base + "/rules"))
+		require.Len(t, list, 2)
+		assert.Equal(t, "Uber", list[0].Name)
+	})
+
+	t.Run("Rules Apply On Create", func(t *testing.T) {
+		res := client.Post(base+"/transactions", transaction("UBER TRIP 4411", "-12.30", uuid.Nil))
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		created := Data[*service.CreatedTransaction](res)
+		assert.Equal(t, "Uber", created.Title)
+		assert.Equal(t, transport, created.CategoryID)
+		assert.Equal(t, []string{"Rides", "Card"}, created.Tags)
+		assert.Len(t, created.Rules, 2)
+
+		var events int
+		err := a.Db.QueryRow(ctx, `SELECT count(*) FROM outbox_events WHERE topic = 'transaction.created' AND payload->>'id' = $1`, created.ID.String()).Scan(&events)
+		require.NoError(t, err)
+		assert.Equal(t, 1, events)
+
+		// a category given wins
+		res = client.Post(base+"/transactions", transaction("Uber Eats", "-20", groceries))
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		assert.Equal(t, groceries, Data[*service.CreatedTransaction](res).CategoryID)
+
+		res = client.Post(base+"/transactions", transaction("Yacht", "-100000000", groceries))
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "Value", Message: apperrors.ValueOutOfRange}}, errorOf(t, res).Fields)
+
+		// refunds aren't rides
+		res = client.Post(base+"/transactions", transaction("Uber refund", "5", uuid.Nil))
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "CategoryID", Message: apperrors.CategoryRequired}}, errorOf(t, res).Fields)
+	})
+
+	t.Run("Rules Apply On Import", func(t *testing.T) {
+		run := a.FinishJob(client, client.Post(base+"/transactions/import", map[string]any{
+			"transactions": []map[string]any{
+				transaction("Uber ride home", "-8", uuid.Nil),
+				transaction("Market", "-30", groceries),
+				transaction("Unknown", "-1", uuid.Nil),
+			},
+		}))
+		assert.Equal(t, service.JobRunFailed, run.Status)
+		require.NotNil(t, run.Error)
+		assert.Equal(t, apperrors.Validation, run.Error.Code)
+		assert.Equal(t, []model.FieldError{{Field: "Transactions[2].CategoryID", Message: apperrors.CategoryRequired}}, run.Error.Fields)
+
+		run = a.FinishJob(client, client.Post(base+"/transactions/import", map[string]any{
+			"transactions": []map[string]any{
+				transaction("Uber ride home", "-8", uuid.Nil),
+				transaction("Market", "-30", groceries),
+			},
+		}))
+		require.Equal(t, service.JobRunSucceeded, run.Status, run.Error)
+		var result service.ImportResult
+		require.NoError(t, json.Unmarshal(run.Result, &result))
+		assert.Equal(t, service.ImportResult{Imported: 2, Matched: 2}, result)
+
+		// the two created before and the ride home, all renamed
+		rows := Data[[]*model.SearchTransactionsRow](client.Get(base + "/transactions/search?q=uber&tag=rides"))
+		assert.Len(t, rows, 3)
+	})
+
+	var corrected []uuid.UUID
+
+	t.Run("Corrections And Suggestions", func(t *testing.T) {
+		for _, title := range []string{"Metro Card 01", "METRO CARD 02", "metro card top up"} {
+			var id uuid.UUID
+			err := a.Db.QueryRow(ctx, `INSERT INTO transactions (title, value, user_id, workspace_id, category_id, account_id, handled_at)
+				VALUES ($1, -5, $2, $3, $4, $5, '2023-04-01') RETURNING id`,
+				title, user.ID, workspace.ID, groceries, wallet).Scan(&id)
+			require.NoError(t, err)
+			corrected = append(corrected, id)
+		}
+
+		assert.Empty(t, Data[[]*rules.Suggestion](client.Get(base+"/rules/suggestions")))
+
+		for _, id := range corrected {
+			res := client.Patch(base+"/transactions/"+id.String(), map[string]any{"category_id": transport.String()})
+			require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+			assert.Equal(t, transport, Data[*model.UpdateTransactionRow](res).CategoryID)
+		}
+
+		res := client.Get(base + "/rules/suggestions")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		suggestions := Data[[]*rules.Suggestion](res)
+		require.Len(t, suggestions, 1)
+		assert.Equal(t, "metro card", suggestions[0].Name)
+		assert.Equal(t, transport, suggestions[0].SetCategoryID)
+		assert.Equal(t, 3, suggestions[0].Corrections)
+
+		res = client.Patch(base+"/transactions/"+uuid.NewString(), map[string]any{"title": "Nope"})
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("Run Over History", func(t *testing.T) {
+		exec(`INSERT INTO transactions (title, value, user_id, workspace_id, category_id, account_id, handled_at)
+			VALUES ('UBER BV 9', -7, $1, $2, $3, $4, '2023-01-01')`, user.ID, workspace.ID, groceries, wallet)
+
+		run := func(body map[string]any) *service.RunRulesResult {
+			res := client.Post(base+"/rules/run", body)
+			require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+			return Data[*service.RunRulesResult](res)
+		}
+
+		preview := run(map[string]any{"dry_run": true, "rule_ids": []string{uber.ID.String()}})
+		require.Equal(t, 1, preview.Changed)
+		change := preview.Changes[0]
+		assert.Equal(t, "UBER BV 9", change.Title)
+		assert.Equal(t, "Uber", change.NewTitle)
+		assert.Equal(t, transport, *change.NewCategoryID)
+		assert.Equal(t, []string{"Rides"}, change.AddTags)
+
+		// nothing changed yet
+		assert.Equal(t, 1, run(map[string]any{"dry_run": true, "rule_ids": []string{uber.ID.String()}}).Changed)
+
+		result := run(map[string]any{"rule_ids": []string{uber.ID.String()}})
+		assert.False(t, result.DryRun)
+		assert.Equal(t, 1, result.Changed)
+		assert.Equal(t, 0, run(map[string]any{"dry_run": true, "rule_ids": []string{uber.ID.String()}}).Changed)
+
+		res := client.Post(base+"/rules/run", map[string]any{"rule_ids": []string{uuid.NewString()}})
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("Run Skips Corrected Transactions", func(t *testing.T) {
+		res := client.Post(base+"/rules", map[string]any{
+			"name": "Metro", "active": false, "title_pattern": "^metro card", "set_category_id": groceries.String(),
+		})
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		metro := Data[*model.Rule](res)
+
+		run := func(body map[string]any) *service.RunRulesResult {
+			body["rule_ids"] = []string{metro.ID.String()}
+			res := client.Post(base+"/rules/run", body)
+			require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+			return Data[*service.RunRulesResult](res)
+		}
+		categories := func() []uuid.UUID {
+			ids := make([]uuid.UUID, 0, len(corrected))
+			for _, id := range corrected {
+				var category uuid.UUID
+				require.NoError(t, a.Db.QueryRow(ctx, `SELECT category_id FROM transactions WHERE id = $1`, id).Scan(&category))
+				ids = append(ids, category)
+			}
+			return ids
+		}
+
+		preview := run(map[string]any{"dry_run": true})
+		assert.Equal(t, 0, preview.Changed)
+		assert.Equal(t, 3, preview.Skipped)
+		require.Len(t, preview.Changes, 3)
+		assert.True(t, preview.Changes[0].Corrected)
+		assert.Equal(t, groceries, *preview.Changes[0].NewCategoryID)
+
+		result := run(map[string]any{})
+		assert.Equal(t, 0, result.Changed)
+		assert.Equal(t, 3, result.Skipped)
+		assert.Equal(t, []uuid.UUID{transport, transport, transport}, categories())
+
+		preview = run(map[string]any{"dry_run": true, "include_corrected": true})
+		assert.Equal(t, 3, preview.Changed)
+		assert.Equal(t, 0, preview.Skipped)
+
+		result = run(map[string]any{"include_corrected": true})
+		assert.Equal(t, 3, result.Changed)
+		assert.Equal(t, []uuid.UUID{groceries, groceries, groceries}, categories())
+	})
+
+	t.Run("Update And Delete", func(t *testing.T) {
+		res := client.Put(base+"/rules/"+uber.ID.String(), map[string]any{
+			"name": "Uber", "active": false, "title_pattern": "^uber", "set_category_id": transport.String(),
+		})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.False(t, Data[*model.Rule](res).Active)
+
+		res = client.Post(base+"/transactions", transaction("Uber trip", "-3", groceries))
+		require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+		assert.Equal(t, "Uber trip", Data[*service.CreatedTransaction](res).Title)
+
+		res = client.Delete(base + "/rules/" + uber.ID.String())
+		assert.Equal(t, http.StatusOK, res.Code)
+		res = client.Delete(base + "/rules/" + uber.ID.String())
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("Audited", func(t *testing.T) {
+		var count int
+		err := a.Db.QueryRow(ctx, `SELECT count(*) FROM audit_logs WHERE entity_type = 'rule' AND entity_id = $1`, uber.ID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("Other Workspace", func(t *testing.T) {
+		other, _ := a.AuthClient()
+		res := other.Get(base + "/rules")
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTransactions_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+	path := "/workspaces/" + workspace.ID.String() + "/transactions/search"
+
+	exec := func(sql string, args ...any) {
+		_, err := a.Db.Exec(ctx, sql, args...)
+		require.NoError(t, err)
+	}
+
+	food, wallet, bank := uuid.New(), uuid.New(), uuid.New()
+	exec(`INSERT INTO categories (id, name, c_type, user_id, workspace_id) VALUES ($1, 'Restaurants', 'expense', $2, $3)`,
+		food, user.ID, workspace.ID)
+	exec(`INSERT INTO accounts (id, name, user_id, workspace_id) VALUES ($1, 'Wallet', $3, $4), ($2, 'Checking', $3, $4)`,
+		wallet, bank, user.ID, workspace.ID)
+
+	insert := func(title, note string, account uuid.UUID, value string, handledAt string) uuid.UUID {
+		id := uuid.New()
+		exec(`INSERT INTO transactions (id, title, note, value, user_id, workspace_id, category_id, account_id, handled_at)
+			VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9)`,
+			id, title, note, value, user.ID, workspace.ID, food, account, handledAt)
+		return id
+	}
+
+	coffee := insert("Coffee shop", "Espresso with <b>friends</b>", wallet, "4.50", "2023-05-02 08:00:00")
+	lunch := insert("Lunch", "Sandwich at the coffee place", bank, "12.00", "2023-05-10 12:00:00")
+	insert("Groceries", "", bank, "80.00", "2023-05-11 18:00:00")
+
+	search := func(params url.Values) []*model.SearchTransactionsRow {
+		res := client.Get(path + "?" + params.Encode())
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		return Data[[]*model.SearchTransactionsRow](res)
+	}
+
+	t.Run("Prefix And Ranking", func(t *testing.T) {
+		rows := search(url.Values{"q": {"coff"}})
+		require.Len(t, rows, 2)
+
+		// a title match ranks above a note match
+		assert.Equal(t, coffee, rows[0].ID)
+		assert.Equal(t, lunch, rows[1].ID)
+		assert.Equal(t, "<mark>Coffee</mark> shop", rows[0].TitleSnippet)
+		assert.Contains(t, rows[1].NoteSnippet, "<mark>coffee</mark>")
+		assert.Equal(t, "Restaurants", rows[0].CategoryName)
+		assert.Equal(t, "Wallet", rows[0].AccountName)
+	})
+
+	t.Run("Escaped Snippets", func(t *testing.T) {
+		rows := search(url.Values{"q": {"espresso"}})
+		require.Len(t, rows, 1)
+		assert.Contains(t, rows[0].NoteSnippet, "&lt;b&gt;")
+	})
+
+	t.Run("Stemming", func(t *testing.T) {
+		rows := search(url.Values{"q": {"friendly"}})
+		require.Len(t, rows, 1)
+		assert.Equal(t, coffee, rows[0].ID)
+	})
+
+	t.Run("Category And Account Names", func(t *testing.T) {
+		assert.Len(t, search(url.Values{"q": {"restaurant"}}), 3)
+		assert.Len(t, search(url.Values{"q": {"checking"}}), 2)
+
+		exec(`UPDATE accounts SET name = 'Savings' WHERE id = $1`, bank)
+		assert.Empty(t, search(url.Values{"q": {"checking"}}))
+		assert.Len(t, search(url.Values{"q": {"savings"}}), 2)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		rows := search(url.Values{"q": {"coffee"}, "account_id": {bank.String()}})
+		require.Len(t, rows, 1)
+		assert.Equal(t, lunch, rows[0].ID)
+
+		rows = search(url.Values{"q": {"coffee"}, "min_value": {"5"}})
+		require.Len(t, rows, 1)
+		assert.Equal(t, lunch, rows[0].ID)
+
+		rows = search(url.Values{"q": {"coffee"}, "to": {"2023-05-05T00:00:00Z"}})
+		require.Len(t, rows, 1)
+		assert.Equal(t, coffee, rows[0].ID)
+
+		assert.Empty(t, search(url.Values{"q": {"coffee"}, "category_id": {uuid.NewString()}}))
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		// english drops stop words
+		assert.Len(t, search(url.Values{"q": {"espresso the"}}), 1)
+
+		// the simple configuration neither stems nor drops stop words
+		exec(`UPDATE workspaces SET language = 'xx' WHERE id = $1`, workspace.ID)
+		assert.Empty(t, search(url.Values{"q": {"espresso the"}}))
+		assert.Empty(t, search(url.Values{"q": {"friendly"}}))
+		assert.Len(t, search(url.Values{"q": {"friends"}}), 1)
+	})
+
+	t.Run("Invalid Query", func(t *testing.T) {
+		res := client.Get(path + "?q=" + url.QueryEscape("&!"))
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		res = client.Get(path)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Other Workspace", func(t *testing.T) {
+		other, _ := a.AuthClient()
+		res := other.Get(path + "?q=coffee")
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,239 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formatTotal formats a total of a report with its 2 decimal places
+func formatTotal(total float64) string {
+	return strconv.FormatFloat(total, 'f', 2, 64)
+}
+
+func TestSplitsAndTags_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+	base := "/workspaces/" + workspace.ID.String()
+
+	exec := func(sql string, args ...any) {
+		_, err := a.Db.Exec(ctx, sql, args...)
+		require.NoError(t, err)
+	}
+
+	groceries, household, other, wallet := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	exec(`INSERT INTO categories (id, name, c_type, user_id, workspace_id) VALUES ($1, 'Groceries', 'expense', $3, $4), ($2, 'Household', 'expense', $3, $4)`,
+		groceries, household, user.ID, workspace.ID)
+	exec(`INSERT INTO accounts (id, name, user_id, workspace_id) VALUES ($1, 'Wallet', $2, $3)`,
+		wallet, user.ID, workspace.ID)
+
+	insert := func(title, value string) uuid.UUID {
+		id := uuid.New()
+		exec(`INSERT INTO transactions (id, title, value, user_id, workspace_id, category_id, account_id, handled_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, '2023-05-02 08:00:00')`,
+			id, title, value, user.ID, workspace.ID, groceries, wallet)
+		return id
+	}
+
+	receipt := insert("Supermarket receipt", "-100.00")
+	insert("Bakery", "-10.00")
+
+	categoryReport := func(params url.Values) map[uuid.UUID]string {
+		res := client.Get(base + "/reports/categories?" + params.Encode())
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		totals := map[uuid.UUID]string{}
+		for _, row := range Data[[]struct {
+			CategoryID uuid.UUID `json:"category_id"`
+			Total      float64   `json:"total"`
+		}](res) {
+			totals[row.CategoryID] = formatTotal(row.Total)
+		}
+		return totals
+	}
+
+	t.Run("Lines Must Add Up", func(t *testing.T) {
+		res := client.Put(base+"/transactions/"+receipt.String()+"/splits", map[string]any{
+			"lines": []map[string]string{
+				{"category_id": groceries.String(), "value": "-70"},
+				{"category_id": household.String(), "value": "-20"},
+			},
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		e := errorOf(t, res)
+		assert.Equal(t, apperrors.Validation, e.Code)
+		assert.Equal(t, []model.FieldError{{Field: "Lines", Message: apperrors.SplitValue}}, e.Fields)
+	})
+
+	t.Run("Invalid Lines", func(t *testing.T) {
+		path := base + "/transactions/" + receipt.String() + "/splits"
+
+		res := client.Put(path, map[string]any{
+			"lines": []map[string]string{{"category_id": groceries.String(), "value": "-100"}},
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		res = client.Put(path, map[string]any{
+			"lines": []map[string]string{
+				{"category_id": groceries.String(), "value": "-99.995"},
+				{"category_id": household.String(), "value": "-0.005"},
+			},
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, errorOf(t, res).Fields, model.FieldError{Field: "Lines[0].Value", Message: apperrors.InvalidCents})
+
+		// they add up but don't fit the column
+		res = client.Put(path, map[string]any{
+			"lines": []map[string]string{
+				{"category_id": groceries.String(), "value": "99999900"},
+				{"category_id": household.String(), "value": "-100000000"},
+			},
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{
+			{Field: "Lines[0].Value", Message: apperrors.SplitLineSign},
+			{Field: "Lines[1].Value", Message: apperrors.ValueOutOfRange},
+		}, errorOf(t, res).Fields)
+
+		res = client.Put(path, map[string]any{
+			"lines": []map[string]string{
+				{"category_id": groceries.String(), "value": "-110"},
+				{"category_id": household.String(), "value": "10"},
+				{"category_id": household.String(), "value": "0"},
+			},
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{
+			{Field: "Lines[1].Value", Message: apperrors.SplitLineSign},
+			{Field: "Lines[2].Value", Message: apperrors.SplitLineSign},
+		}, errorOf(t, res).Fields)
+
+		res = client.Put(path, map[string]any{
+			"lines": []map[string]string{
+				{"category_id": groceries.String(), "value": "-80"},
+				{"category_id": other.String(), "value": "-20"},
+			},
+		})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{Field: "CategoryID", Message: apperrors.UnknownCategory}}, errorOf(t, res).Fields)
+
+		res = client.Put(base+"/transactions/"+uuid.NewString()+"/splits", map[string]any{})
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		res := client.Put(base+"/transactions/"+receipt.String()+"/splits", map[string]any{
+			"lines": []map[string]string{
+				{"category_id": groceries.String(), "value": "-80.5", "note": "Food"},
+				{"category_id": household.String(), "value": "-19.50"},
+			},
+		})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		require.Len(t, Data[[]*model.TransactionSplit](res), 2)
+
+		splits := Data[[]*model.TransactionSplit](client.Get(base + "/transactions/" + receipt.String() + "/splits"))
+		require.Len(t, splits, 2)
+		assert.Equal(t, groceries, splits[0].CategoryID)
+		assert.Equal(t, "Food", splits[0].Note.String)
+		assert.Equal(t, household, splits[1].CategoryID)
+
+		// the lines count instead of the transaction
+		assert.Equal(t, map[uuid.UUID]string{groceries: "-90.50", household: "-19.50"}, categoryReport(nil))
+
+		// search finds the transaction by the category of a line
+		rows := Data[[]*model.SearchTransactionsRow](client.Get(base + "/transactions/search?q=supermarket&category_id=" + household.String()))
+		assert.Len(t, rows, 1)
+	})
+
+	t.Run("Value Can't Drift From The Lines", func(t *testing.T) {
+		_, err := a.Db.Exec(ctx, `UPDATE transactions SET value = -90 WHERE id = $1`, receipt)
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "ck_transaction_splits_value", pgErr.ConstraintName)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		path := base + "/transactions/" + receipt.String() + "/tags"
+
+		res := client.Put(path, map[string]any{"tags": []string{"Vacation", " vacation ", "Family  trip"}})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		tags := Data[[]*model.Tag](res)
+		require.Len(t, tags, 2)
+		assert.Equal(t, "Family trip", tags[0].Name)
+		assert.Equal(t, "Vacation", tags[1].Name)
+
+		// the same tag in another case
+		res = client.Put(path, map[string]any{"tags": []string{"VACATION"}})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.Equal(t, tags[1].ID, Data[[]*model.Tag](res)[0].ID)
+
+		list := Data[[]*model.GetWorkspaceTagsRow](client.Get(base + "/tags"))
+		require.Len(t, list, 2)
+		assert.Equal(t, int64(0), list[0].Transactions)
+		assert.Equal(t, int64(1), list[1].Transactions)
+
+		rows := Data[[]*model.SearchTransactionsRow](client.Get(base + "/transactions/search?q=receipt&tag=vacation"))
+		assert.Len(t, rows, 1)
+		assert.Empty(t, Data[[]*model.SearchTransactionsRow](client.Get(base+"/transactions/search?q=receipt&tag=family+trip")))
+
+		assert.Equal(t, map[uuid.UUID]string{groceries: "-80.50", household: "-19.50"},
+			categoryReport(url.Values{"tag": {"Vacation"}}))
+
+		res = client.Get(base + "/reports/tags?category_id=" + household.String())
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		report := Data[[]struct {
+			TagName      string  `json:"tag_name"`
+			Total        float64 `json:"total"`
+			Transactions int64   `json:"transactions"`
+		}](res)
+		require.Len(t, report, 1)
+		assert.Equal(t, "Vacation", report[0].TagName)
+		assert.Equal(t, "-19.50", formatTotal(report[0].Total))
+		assert.Equal(t, int64(1), report[0].Transactions)
+	})
+
+	t.Run("Delete Tag", func(t *testing.T) {
+		list := Data[[]*model.GetWorkspaceTagsRow](client.Get(base + "/tags"))
+		require.NotEmpty(t, list)
+
+		res := client.Delete(base + "/tags/" + list[1].ID.String())
+		assert.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.Empty(t, Data[[]*model.Tag](client.Get(base+"/transactions/"+receipt.String()+"/tags")))
+
+		res = client.Delete(base + "/tags/" + list[1].ID.String())
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("Unsplit", func(t *testing.T) {
+		res := client.Put(base+"/transactions/"+receipt.String()+"/splits", map[string]any{"lines": []any{}})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.Empty(t, Data[[]*model.TransactionSplit](res))
+
+		assert.Equal(t, map[uuid.UUID]string{groceries: "-110.00"}, categoryReport(nil))
+	})
+
+	t.Run("Audited", func(t *testing.T) {
+		var count int
+		err := a.Db.QueryRow(ctx, `SELECT count(*) FROM audit_logs WHERE entity_type = 'transaction_split' AND actor_id = $1`, user.ID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("Other Workspace", func(t *testing.T) {
+		other, _ := a.AuthClient()
+		res := other.Get(base + "/reports/categories")
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/opchaves/gin-web-app/app/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing_E2E(t *testing.T) {
+	// installed before the app so its router and pool trace with it
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(&tracing.Config{ServiceName: "test", SampleRatio: 1},
+		sdktrace.WithSyncer(exporter),
+	)
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	a := New(t)
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+
+	exporter.Reset()
+	res := client.Get("/workspaces/" + workspace.ID.String() + "/tags")
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	spans := exporter.GetSpans()
+	var server *tracetest.SpanStub
+	for i := range spans {
+		if spans[i].SpanKind == trace.SpanKindServer {
+			server = &spans[i]
+		}
+	}
+	require.NotNil(t, server)
+	assert.Equal(t, "/workspaces/:workspaceId/tags", server.Name)
+
+	var queries []string
+	for _, span := range spans {
+		if span.Parent.SpanID() != server.SpanContext.SpanID() || !strings.HasPrefix(span.Name, "query ") {
+			continue
+		}
+
+		queries = append(queries, span.Name)
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+		assert.Contains(t, span.Attributes, attribute.String("db.system", "postgresql"))
+	}
+	assert.NotEmpty(t, queries, "no pgx spans under the request")
+}
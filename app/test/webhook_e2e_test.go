@@ -0,0 +1,180 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/outbox"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/app/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the webhook deliveries it gets
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func TestWebhooks_E2E(t *testing.T) {
+	a := New(t)
+	ctx := context.Background()
+	client, user := a.AuthClient()
+	workspace := a.Workspace(user)
+	path := "/workspaces/" + workspace.ID.String() + "/webhooks"
+
+	rcv := newReceiver(t)
+
+	res := client.Post(path, gin.H{
+		"url":    rcv.URL,
+		"events": []string{webhooks.EventTransactionCreated},
+	})
+	require.Equal(t, http.StatusCreated, res.Code, res.Body.String())
+	hook := Data[model.Webhook](res)
+	assert.NotEmpty(t, hook.Secret)
+
+	publish := func(topic string) {
+		tx, err := a.Db.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		err = outbox.Publish(ctx, a.Q.WithTx(tx), topic, gin.H{
+			"workspace_id": workspace.ID,
+			"title":        "Coffee",
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(ctx))
+	}
+
+	deliveries := func() []model.WebhookDelivery {
+		res := client.Get(path + "/" + hook.ID.String() + "/deliveries")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		return Data[[]model.WebhookDelivery](res)
+	}
+
+	t.Run("List", func(t *testing.T) {
+		res := client.Get(path)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		hooks := Data[[]map[string]any](res)
+		require.Len(t, hooks, 1)
+		assert.Equal(t, rcv.URL, hooks[0]["url"])
+		assert.NotContains(t, hooks[0], "secret")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		res := client.Post(path, gin.H{"url": "ftp://example.com", "events": []string{"nope"}})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		res = client.Post(path, gin.H{"url": rcv.URL, "events": []string{webhooks.EventTransactionUpdated, "nope"}})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, []model.FieldError{{
+			Field:   "Events[1]",
+			Message: "must be one of " + strings.Join(webhooks.Events, " "),
+		}}, errorOf(t, res).Fields)
+	})
+
+	t.Run("Other Workspace", func(t *testing.T) {
+		other, _ := a.AuthClient()
+
+		res := other.Get(path)
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("Signed Delivery", func(t *testing.T) {
+		publish(webhooks.EventTransactionCreated)
+		// not subscribed
+		publish(webhooks.EventTransactionUpdated)
+
+		assert.Equal(t, 2, a.RelayEvents())
+		assert.Equal(t, 1, a.RunJobs())
+
+		require.Len(t, rcv.requests, 1)
+		req, body := rcv.requests[0], rcv.bodies[0]
+		assert.Equal(t, webhooks.EventTransactionCreated, req.Header.Get(webhooks.HeaderEvent))
+		assert.NoError(t, webhooks.Verify(hook.Secret, req.Header.Get(webhooks.HeaderSignature), body, time.Minute))
+
+		var payload webhooks.Payload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, webhooks.EventTransactionCreated, payload.Type)
+		assert.Equal(t, workspace.ID, payload.WorkspaceID)
+		assert.Equal(t, req.Header.Get(webhooks.HeaderID), payload.ID)
+		assert.JSONEq(t, `{"workspace_id":"`+workspace.ID.String()+`","title":"Coffee"}`, string(payload.Data))
+
+		log := deliveries()
+		require.Len(t, log, 1)
+		assert.Equal(t, service.DeliverySucceeded, log[0].Status)
+		assert.Equal(t, int32(http.StatusOK), log[0].ResponseStatus.Int32)
+		assert.Equal(t, int32(1), log[0].Attempts)
+	})
+
+	t.Run("Failed Delivery And Replay", func(t *testing.T) {
+		rcv.status = http.StatusInternalServerError
+		publish(webhooks.EventTransactionCreated)
+
+		assert.Equal(t, 1, a.RelayEvents())
+		assert.Equal(t, 1, a.RunJobs())
+
+		failed := deliveries()[0]
+		assert.Equal(t, service.DeliveryPending, failed.Status, "retried later")
+		assert.Equal(t, int32(http.StatusInternalServerError), failed.ResponseStatus.Int32)
+		assert.NotEmpty(t, failed.LastError.String)
+
+		rcv.status = http.StatusOK
+		res := client.Post(path+"/"+hook.ID.String()+"/deliveries/"+failed.ID.String()+"/replay", nil)
+		require.Equal(t, http.StatusAccepted, res.Code, res.Body.String())
+		replay := Data[model.WebhookDelivery](res)
+		assert.Equal(t, uuid.NullUUID{UUID: failed.ID, Valid: true}, replay.ReplayOf)
+		assert.Equal(t, failed.EventID, replay.EventID)
+
+		assert.Equal(t, 1, a.RunJobs(), "the retry of the failed one is not due yet")
+
+		log := deliveries()
+		require.Len(t, log, 3)
+		assert.Equal(t, service.DeliverySucceeded, log[0].Status)
+		assert.Equal(t, replay.ID, log[0].ID)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		res := client.Delete(path + "/" + hook.ID.String())
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		res = client.Delete(path + "/" + hook.ID.String())
+		assert.Equal(t, http.StatusNotFound, res.Code)
+
+		publish(webhooks.EventTransactionCreated)
+		assert.Equal(t, 1, a.RelayEvents())
+		assert.Equal(t, 0, a.RunJobs())
+	})
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// ScopeName identifies the spans created by the application itself
+const ScopeName = "github.com/opchaves/gin-web-app"
+
+// Config sets up tracing
+type Config struct {
+	// Exporter is one of none, stdout or otlp. The otlp exporter is set with
+	// the standard OTEL_EXPORTER_OTLP_* environment variables.
+	Exporter    string
+	ServiceName string
+	Version     string
+	// SampleRatio is the fraction of new traces that get sampled
+	SampleRatio float64
+	// Writer is where the stdout exporter writes to
+	Writer io.Writer
+}
+
+// Setup installs the global tracer provider and propagators. The returned
+// function flushes and stops the exporter, it must be called on shutdown.
+func Setup(ctx context.Context, c *Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch strings.ToLower(c.Exporter) {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(c.Writer))
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %q", c.Exporter)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(c, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider for the service. Tests pass an
+// in-memory exporter with sdktrace.WithSyncer.
+func NewProvider(c *Config, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(c.ServiceName),
+		semconv.ServiceVersion(c.Version),
+	)
+
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	}, opts...)
+
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// Middleware starts a span for every request, named after its route
+func Middleware(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName)
+}
+
+// Start creates a span for work done by the application, like sending an
+// email
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(ScopeName).Start(ctx, name, opts...)
+}
@@ -0,0 +1,138 @@
+package tracing_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/handler/middleware"
+	"github.com/opchaves/gin-web-app/app/logging"
+	"github.com/opchaves/gin-web-app/app/mail"
+	"github.com/opchaves/gin-web-app/app/service"
+	"github.com/opchaves/gin-web-app/app/tracing"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(&tracing.Config{ServiceName: "test", SampleRatio: 1},
+		sdktrace.WithSyncer(exporter),
+	)
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, "info", logging.FormatJSON)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(tracing.Middleware("test"), middleware.RequestID(), middleware.Logger(logger))
+	router.GET("/items/:id", func(c *gin.Context) {
+		_, span := tracing.Start(c, "load item")
+		span.End()
+
+		c.JSON(http.StatusOK, gin.H{"data": c.Param("id")})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "load item", child.Name)
+	assert.Equal(t, "/items/:id", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+	assert.Contains(t, server.Attributes, attribute.String("request.id", "req-1"))
+
+	record := map[string]any{}
+	assert.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, server.SpanContext.TraceID().String(), record["trace_id"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "/items/:id", record["route"])
+}
+
+func TestClientSpans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(&tracing.Config{ServiceName: "test", SampleRatio: 1},
+		sdktrace.WithSyncer(exporter),
+	)
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	require.NoError(t, redisotel.InstrumentTracing(rdb))
+
+	mailbox := &mail.Memory{}
+	mailService := service.NewMailService(&service.MailConfig{Transport: mailbox, From: "app@example.com"})
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(tracing.Middleware("test"))
+	router.POST("/notify", func(c *gin.Context) {
+		if err := rdb.Set(c, "notified", "1", 0).Err(); err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		err := mailService.Deliver(c, mail.Message{To: []string{"jane@example.com"}, Subject: "Hi", Text: "hi"})
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/notify", nil))
+	require.Equal(t, http.StatusNoContent, res.Code)
+	require.Len(t, mailbox.Messages(), 1)
+
+	spans := exporter.GetSpans()
+	var server *tracetest.SpanStub
+	for i := range spans {
+		if spans[i].SpanKind == trace.SpanKindServer {
+			server = &spans[i]
+		}
+	}
+	require.NotNil(t, server)
+
+	children := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		if span.Parent.SpanID() == server.SpanContext.SpanID() {
+			children[span.Name] = span
+		}
+	}
+
+	redisSpan, ok := children["set"]
+	require.True(t, ok, "no redis span under the request")
+	assert.Equal(t, trace.SpanKindClient, redisSpan.SpanKind)
+	assert.Contains(t, redisSpan.Attributes, attribute.String("db.system", "redis"))
+
+	mailSpan, ok := children["mail.send"]
+	require.True(t, ok, "no mail span under the request")
+	assert.Equal(t, trace.SpanKindClient, mailSpan.SpanKind)
+	assert.Contains(t, mailSpan.Attributes, attribute.String("mail.subject", "Hi"))
+}
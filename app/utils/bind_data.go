@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -20,9 +19,7 @@ type Request interface {
 func bindData(c *gin.Context, req Request) bool {
 	// Bind incoming json to struct and check for validation errors
 	if err := c.ShouldBind(req); err != nil {
-		c.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		c.Error(err)
 		return false
 	}
 
@@ -39,9 +36,7 @@ func bindData(c *gin.Context, req Request) bool {
 			fErrors = append(fErrors, er)
 		}
 
-		c.JSON(http.StatusBadRequest, gin.H{
-			"errors": fErrors,
-		})
+		c.Error(apperrors.NewValidation(fErrors))
 		return false
 	}
 	return true
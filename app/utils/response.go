@@ -1,19 +1,166 @@
 package utils
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/opchaves/gin-web-app/app/i18n"
 	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/apperrors"
 )
 
-func ToFieldErrorResponse(c *gin.Context, field, message string) {
-	c.JSON(http.StatusBadRequest, gin.H{
-		"errors": []model.FieldError{
-			{
-				Field:   field,
-				Message: message,
-			},
+// Templates used to render form errors for HTML clients
+const (
+	FormErrorsTemplate = "form_errors.html"
+	ErrorPageTemplate  = "error.html"
+	// FormErrorsTarget is the element htmx swaps the form errors into
+	FormErrorsTarget = "#form-errors"
+	// ErrorDocsPath followed by an error code links to its docs
+	ErrorDocsPath = "/docs#"
+)
+
+// Negotiated holds what a handler can render for each kind of client
+type Negotiated struct {
+	// JSON is sent as is to API clients
+	JSON any
+	// Page is the full page template rendered for regular browser requests
+	Page string
+	// Fragment is the partial template rendered for HTMX requests
+	Fragment string
+	// Data is passed to Page and Fragment
+	Data gin.H
+	// Redirect, when set, sends HTML clients to this location instead of
+	// rendering Page. HTMX requests still get Fragment when there is one.
+	Redirect string
+}
+
+// IsHTMX reports whether the request was made by htmx
+func IsHTMX(c *gin.Context) bool {
+	return c.GetHeader("HX-Request") == "true"
+}
+
+// WantsHTML reports whether the client prefers HTML over JSON. Requests
+// without an Accept header are treated as API calls.
+func WantsHTML(c *gin.Context) bool {
+	if IsHTMX(c) {
+		return true
+	}
+
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+}
+
+// Render writes the response in the format the client asked for: JSON for
+// API clients, the fragment for HTMX requests and the full page otherwise.
+// It falls back to JSON when no template fits the request. Templates get the
+// session's CSRF token as csrfToken, and the localizer of the request as t and
+// its language as lang.
+func Render(c *gin.Context, status int, r Negotiated) {
+	if !WantsHTML(c) {
+		c.JSON(status, r.JSON)
+		return
+	}
+
+	if r.Data == nil {
+		r.Data = gin.H{}
+	}
+	r.Data["csrfToken"] = c.GetString(model.CSRFKey)
+	l := i18n.From(c)
+	r.Data["t"] = l
+	r.Data["lang"] = l.Lang
+
+	if IsHTMX(c) && r.Fragment != "" {
+		c.HTML(status, r.Fragment, r.Data)
+		return
+	}
+
+	if r.Redirect != "" {
+		redirect(c, r.Redirect)
+		return
+	}
+
+	template := r.Page
+	if template == "" {
+		template = r.Fragment
+	}
+
+	if template == "" {
+		c.JSON(status, r.JSON)
+		return
+	}
+
+	c.HTML(status, template, r.Data)
+}
+
+// redirect sends the client to location. htmx follows HX-Redirect on its
+// own, so HTMX requests get the header instead of a 3xx status.
+func redirect(c *gin.Context, location string) {
+	if IsHTMX(c) {
+		c.Header("HX-Redirect", location)
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, location)
+}
+
+// ToAppError returns err as an *apperrors.Error. Errors which aren't one
+// become internal errors and ok is false, so callers know to log them.
+func ToAppError(err error) (e *apperrors.Error, ok bool) {
+	if e, ok := apperrors.As(err); ok {
+		return e, true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return apperrors.NewPayloadTooLarge(maxBytesErr.Limit, -1), true
+	}
+
+	return apperrors.NewInternal(), false
+}
+
+// NewErrorResponse wraps e in the envelope of every error response, with its
+// messages in the language of the request
+func NewErrorResponse(c *gin.Context, e *apperrors.Error) model.ErrorResponse {
+	l := i18n.From(c)
+	return model.ErrorResponse{
+		Error: model.HttpError{
+			Code:      e.Type,
+			Message:   l.Error(e),
+			Fields:    l.Fields(e),
+			RequestID: c.GetString(model.RequestIDKey),
+			Docs:      ErrorDocsPath + string(e.Type),
+		},
+	}
+}
+
+// RenderError responds with e. HTML clients get the error page, and HTMX
+// requests the form errors partial swapped into FormErrorsTarget.
+func RenderError(c *gin.Context, e *apperrors.Error) {
+	if IsHTMX(c) {
+		c.Header("HX-Retarget", FormErrorsTarget)
+		c.Header("HX-Reswap", "innerHTML")
+	}
+
+	l := i18n.From(c)
+	errors := l.Fields(e)
+	if len(errors) == 0 {
+		errors = []model.FieldError{{Message: l.Error(e)}}
+	}
+
+	title, ok := l.Lookup("status." + strconv.Itoa(e.Status()))
+	if !ok {
+		title = http.StatusText(e.Status())
+	}
+
+	Render(c, e.Status(), Negotiated{
+		JSON:     NewErrorResponse(c, e),
+		Page:     ErrorPageTemplate,
+		Fragment: FormErrorsTemplate,
+		Data: gin.H{
+			"title":  title,
+			"errors": errors,
 		},
 	})
 }
@@ -0,0 +1,131 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// maxResponseBody is how much of the response is kept in the delivery log
+const maxResponseBody = 1024
+
+// Request is a delivery to send
+type Request struct {
+	URL      string
+	Secret   string
+	Event    string
+	EventID  string
+	Delivery string
+	Body     []byte
+}
+
+// Response is what the receiver answered
+type Response struct {
+	Status   int
+	Body     string
+	Duration time.Duration
+}
+
+// Sender posts the deliveries
+type Sender struct {
+	Client    *http.Client
+	UserAgent string
+}
+
+// ErrBlockedAddress is returned for receivers on an address of the host or
+// its private networks
+var ErrBlockedAddress = errors.New("webhook receiver address is not allowed")
+
+// NewSender returns a sender whose requests time out after timeout. Unless
+// allowPrivate is set, it refuses to connect to loopback, private,
+// link-local and unspecified addresses, so that users can't reach the
+// services next to the app through their webhooks. The check runs on the
+// resolved address and redirects aren't followed, nor proxies used.
+func NewSender(timeout time.Duration, userAgent string, allowPrivate bool) *Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = checkAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		Client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		UserAgent: userAgent,
+	}
+}
+
+// checkAddress is the dialer control refusing the blocked addresses
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if blocked(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ap.Addr())
+	}
+
+	return nil
+}
+
+func blocked(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// Send signs and posts the request. It returns an error when the receiver
+// can't be reached or doesn't answer with a 2xx status, along with the
+// response if there is one.
+func (s *Sender) Send(ctx context.Context, r *Request) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.UserAgent)
+	req.Header.Set(HeaderEvent, r.Event)
+	req.Header.Set(HeaderID, r.EventID)
+	req.Header.Set(HeaderDelivery, r.Delivery)
+	req.Header.Set(HeaderSignature, Sign(r.Secret, time.Now(), r.Body))
+
+	start := time.Now()
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	// drain the rest so the connection can be reused
+	io.Copy(io.Discard, res.Body)
+
+	response := &Response{
+		Status:   res.StatusCode,
+		Body:     string(bytes.ReplaceAll(bytes.ToValidUTF8(body, nil), []byte{0}, nil)),
+		Duration: time.Since(start),
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return response, fmt.Errorf("webhook receiver answered %d", res.StatusCode)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,122 @@
+// Package webhooks signs and sends the webhook deliveries of the workspace
+// events.
+//
+// A delivery is a POST of the event as JSON. Its X-Webhook-Signature header
+// is "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">" keyed with
+// the webhook secret, so receivers can check where it comes from and reject
+// old deliveries with Verify. X-Webhook-Id is the same on every attempt and
+// replay of an event, so receivers can skip the ones they already handled.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events users can subscribe to. They are also the topics of the outbox
+// events delivered to the webhooks, whose payload must have a workspace_id.
+// Only add an event along with the code publishing it.
+const (
+	EventTransactionCreated = "transaction.created"
+	EventTransactionUpdated = "transaction.updated"
+)
+
+// Events lists all the events
+var Events = []string{
+	EventTransactionCreated,
+	EventTransactionUpdated,
+}
+
+// IsEvent reports whether name is one of Events
+func IsEvent(name string) bool {
+	return slices.Contains(Events, name)
+}
+
+// Headers of the deliveries
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// ErrInvalidSignature is returned by Verify for deliveries which were not
+// signed with the secret or are too old
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Payload is the body of a delivery
+type Payload struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	WorkspaceID uuid.UUID       `json:"workspace_id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// NewSecret returns a random secret to sign the deliveries of a webhook
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header of body sent at t
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, body)
+}
+
+// Verify checks the signature header of body and that it was signed less
+// than tolerance ago
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidSignature)
+	}
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return fmt.Errorf("%w: too old", ErrInvalidSignature)
+	}
+
+	expected := mac(secret, ts, body)
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func mac(secret, ts string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
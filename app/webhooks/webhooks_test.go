@@ -0,0 +1,129 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+
+	header := Sign("secret", now, body)
+	assert.NoError(t, Verify("secret", header, body, time.Minute))
+
+	assert.ErrorIs(t, Verify("other", header, body, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"id":"evt_2"}`), time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", "v1=abc", body, time.Minute), ErrInvalidSignature)
+
+	old := Sign("secret", now.Add(-time.Hour), body)
+	assert.ErrorIs(t, Verify("secret", old, body, time.Minute), ErrInvalidSignature)
+	assert.NoError(t, Verify("secret", old, body, 0))
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	require.NoError(t, err)
+	b, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.Len(t, a, len("whsec_")+64)
+	assert.NotEqual(t, a, b)
+}
+
+func TestSender(t *testing.T) {
+	status := http.StatusOK
+	var got *http.Request
+	var gotBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		w.Write([]byte("thanks"))
+	}))
+	defer server.Close()
+
+	sender := NewSender(time.Second, "test", true)
+	req := &Request{
+		URL:      server.URL,
+		Secret:   "secret",
+		Event:    EventTransactionCreated,
+		EventID:  "evt_1",
+		Delivery: "d1",
+		Body:     []byte(`{"id":"evt_1"}`),
+	}
+
+	res, err := sender.Send(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, "thanks", res.Body)
+
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, EventTransactionCreated, got.Header.Get(HeaderEvent))
+	assert.Equal(t, "evt_1", got.Header.Get(HeaderID))
+	assert.Equal(t, "d1", got.Header.Get(HeaderDelivery))
+	assert.Equal(t, req.Body, gotBody)
+	assert.NoError(t, Verify("secret", got.Header.Get(HeaderSignature), gotBody, time.Minute))
+
+	t.Run("Error Status", func(t *testing.T) {
+		status = http.StatusInternalServerError
+
+		res, err := sender.Send(context.Background(), req)
+		assert.Error(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, http.StatusInternalServerError, res.Status)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		res, err := sender.Send(context.Background(), &Request{URL: "http://127.0.0.1:1", Body: []byte("{}")})
+		assert.Error(t, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Redirects Aren't Followed", func(t *testing.T) {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("the redirect was followed")
+		}))
+		defer target.Close()
+
+		redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+		defer redirect.Close()
+
+		res, err := sender.Send(context.Background(), &Request{URL: redirect.URL, Body: []byte("{}")})
+		assert.Error(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, http.StatusFound, res.Status)
+	})
+}
+
+func TestSenderBlocksPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request reached a loopback receiver")
+	}))
+	defer server.Close()
+
+	sender := NewSender(time.Second, "test", false)
+	for _, url := range []string{
+		server.URL,
+		"http://localhost:" + server.URL[len("http://127.0.0.1:"):],
+		"http://[::1]:9/",
+		"http://0.0.0.0:9/",
+		"http://10.0.0.1:9/",
+		"http://192.168.1.1:9/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::ffff:127.0.0.1]:9/",
+	} {
+		res, err := sender.Send(context.Background(), &Request{URL: url, Body: []byte("{}")})
+		assert.ErrorIs(t, err, ErrBlockedAddress, url)
+		assert.Nil(t, res, url)
+	}
+}
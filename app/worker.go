@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+
+	"github.com/opchaves/gin-web-app/app/jobs"
+	"github.com/opchaves/gin-web-app/app/mail"
+	"github.com/opchaves/gin-web-app/app/service"
+)
+
+// SetJobs registers the handlers of the background jobs
+func SetJobs(c *Config) {
+	c.Worker.Handle(service.JobSendEmail, func(ctx context.Context, job *jobs.Job) error {
+		var msg mail.Message
+		if err := job.Decode(&msg); err != nil {
+			return err
+		}
+
+		return c.MailService.Deliver(ctx, msg)
+	})
+
+	c.Worker.Handle(service.JobDeliverWebhook, func(ctx context.Context, job *jobs.Job) error {
+		var msg service.WebhookDeliveryMessage
+		if err := job.Decode(&msg); err != nil {
+			return err
+		}
+
+		lastAttempt := job.Attempts+1 >= job.MaxAttempts
+		return c.WebhookService.Deliver(ctx, msg.DeliveryID, lastAttempt)
+	})
+
+	runJob := func(ctx context.Context, job *jobs.Job) error {
+		var msg service.JobRunMessage
+		if err := job.Decode(&msg); err != nil {
+			return err
+		}
+
+		lastAttempt := job.Attempts+1 >= job.MaxAttempts
+		return c.JobRunService.Run(ctx, &msg, lastAttempt)
+	}
+	c.Worker.Handle(service.JobImportTransactions, runJob)
+	c.Worker.Handle(service.JobGenerateReport, runJob)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/audit"
+	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/model"
+)
+
+// Audit runs the audit log commands
+func Audit(ctx context.Context, cfg *config.Config, command string, args []string) error {
+	if command != "export" {
+		return fmt.Errorf("%w: audit %s", errUsage, command)
+	}
+
+	filter, format, output, err := parseExportFlags(args)
+	if err != nil {
+		return err
+	}
+
+	db, err := pgxpool.New(ctx, cfg.DatabaseUrl)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	n, err := audit.Export(ctx, model.New(db), filter, w, format)
+	if err != nil {
+		return fmt.Errorf("failed to export the audit log: %w", err)
+	}
+
+	// stdout may be the export
+	fmt.Fprintf(os.Stderr, "exported %d audit log entries\n", n)
+	return nil
+}
+
+func parseExportFlags(args []string) (filter audit.Filter, format string, output string, err error) {
+	var workspace, actor, entityId, from, to string
+
+	fs := flag.NewFlagSet("audit export", flag.ContinueOnError)
+	fs.StringVar(&workspace, "workspace", "", "only entries of this workspace id")
+	fs.StringVar(&actor, "actor", "", "only entries made by this user id")
+	fs.StringVar(&filter.EntityType, "entity-type", "", "only entries of this entity type, e.g. transaction")
+	fs.StringVar(&entityId, "entity-id", "", "only entries of this entity id")
+	fs.StringVar(&filter.Action, "action", "", "only entries with this action, e.g. deleted")
+	fs.StringVar(&from, "from", "", "only entries made since this date or RFC 3339 time")
+	fs.StringVar(&to, "to", "", "only entries made before this date or RFC 3339 time")
+	fs.StringVar(&format, "format", audit.FormatJSON, "json for JSON lines or csv")
+	fs.StringVar(&output, "o", "", "file to write, stdout by default")
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	for _, id := range []struct {
+		value string
+		dst   *uuid.NullUUID
+	}{{workspace, &filter.WorkspaceID}, {actor, &filter.ActorID}, {entityId, &filter.EntityID}} {
+		if id.value == "" {
+			continue
+		}
+		parsed, perr := uuid.Parse(id.value)
+		if perr != nil {
+			err = fmt.Errorf("%w: invalid id %q", errUsage, id.value)
+			return
+		}
+		*id.dst = audit.ID(parsed)
+	}
+
+	if filter.From, err = parseTime(from); err != nil {
+		return
+	}
+	filter.To, err = parseTime(to)
+	return
+}
+
+// parseTime parses a date or an RFC 3339 time, in UTC unless it has a zone
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("%w: invalid time %q", errUsage, s)
+	}
+	return t, nil
+}
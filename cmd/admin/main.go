@@ -5,52 +5,68 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
-	"log/slog"
-
+	"github.com/joho/godotenv"
 	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/logging"
+	"github.com/opchaves/gin-web-app/app/model/fixture"
 )
 
-// NOTE not used. current migrate command being used with Makefile
+const usage = `Usage: admin <command> [arguments]
+
+Commands:
+  migrate up [N]       apply all or the next N migrations
+  migrate down [N|all] roll back N migrations (default 1) or all of them
+  migrate to V         migrate up or down to version V
+  migrate status       print the current and latest versions
+  migrate force V      set the version without running migrations, clearing the dirty flag
+  seed <file|set>      run a .sql file or insert a fixture set (%s)
+  seed demo [flags]    insert a demo workspace, see admin seed demo -h
+  audit export [flags] export the audit log as JSON lines or CSV, see admin audit export -h
+`
+
+var errUsage = errors.New("invalid command")
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Println(err)
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, usage, strings.Join(fixture.SetNames(), ", "))
+		}
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
-	handler := slog.NewJSONHandler(os.Stdout, nil)
-	logger := slog.New(handler)
+func run(args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
 
-	if len(os.Args) <= 3 {
-		logger.Error(fmt.Sprintf("Usage:", os.Args[1], "command", "argument"))
-		return errors.New("invalid command")
+	// the .env file is optional here as the environment may be set already
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error loading .env file: %w", err)
 	}
 
 	ctx := context.Background()
 	cfg, err := config.LoadConfig(ctx)
 	if err != nil {
-		logger.Error("failed to load config", slog.Any("error", err))
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return err
 	}
 
-	switch os.Args[1] {
+	switch args[0] {
 	case "migrate":
-		err = Migrate(&cfg, logger, os.Args[3])
+		return Migrate(&cfg, logger, args[1], args[2:])
 	case "seed":
-		err = Seed(&cfg, logger, os.Args[3])
+		return Seed(ctx, &cfg, logger, args[1], args[2:])
+	case "audit":
+		return Audit(ctx, &cfg, args[1], args[2:])
 	default:
-		err = errors.New("must specify a command")
+		return fmt.Errorf("%w: %s", errUsage, args[0])
 	}
-
-	return err
-}
-
-func Migrate(cfg *config.Config, logger *slog.Logger, command string) error {
-	return nil
-}
-
-func Seed(cfg *config.Config, logger *slog.Logger, sqlFile string) error {
-	return nil
 }
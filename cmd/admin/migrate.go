@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/db"
+)
+
+// Migrate runs the migrate subcommand against the embedded migrations
+func Migrate(cfg *config.Config, logger *slog.Logger, command string, args []string) error {
+	m, err := db.NewMigrate(cfg.DatabaseUrl, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	defer m.Close()
+
+	switch command {
+	case "up":
+		if len(args) == 0 {
+			err = m.Up()
+			break
+		}
+		n, perr := parseArg(args, "N")
+		if perr != nil {
+			return perr
+		}
+		err = m.Steps(n)
+	case "down":
+		if len(args) > 0 && args[0] == "all" {
+			err = m.Down()
+			break
+		}
+		n := 1
+		if len(args) > 0 {
+			if n, err = parseArg(args, "N"); err != nil {
+				return err
+			}
+		}
+		err = m.Steps(-n)
+	case "to":
+		v, perr := parseArg(args, "V")
+		if perr != nil {
+			return perr
+		}
+		err = m.Migrate(uint(v))
+	case "force":
+		v, perr := parseArg(args, "V")
+		if perr != nil {
+			return perr
+		}
+		err = m.Force(v)
+	case "status":
+		return printStatus(m)
+	default:
+		return fmt.Errorf("%w: migrate %s", errUsage, command)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		logger.Info("no migrations to run")
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return printStatus(m)
+}
+
+func printStatus(m *migrate.Migrate) error {
+	latest, err := db.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Printf("version: none, latest: %d\n", latest)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version: %d, dirty: %t, latest: %d\n", version, dirty, latest)
+	return nil
+}
+
+// parseArg parses the single positive integer argument of a subcommand
+func parseArg(args []string, name string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: expected %s", errUsage, name)
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive number, got %q", errUsage, name, args[0])
+	}
+
+	return n, nil
+}
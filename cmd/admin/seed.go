@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opchaves/gin-web-app/app/config"
+	"github.com/opchaves/gin-web-app/app/model"
+	"github.com/opchaves/gin-web-app/app/model/fixture"
+)
+
+// Seed runs the given .sql file or inserts the fixture set with that name.
+// Everything runs in a single transaction.
+func Seed(ctx context.Context, cfg *config.Config, logger *slog.Logger, name string, args []string) error {
+	var sql string
+	set, ok := fixture.Sets[name]
+	if name == "demo" {
+		opts, err := parseDemoFlags(args)
+		if err != nil {
+			return err
+		}
+		set = fixture.DemoSet(opts)
+	} else if len(args) > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, args)
+	}
+
+	if !ok {
+		if filepath.Ext(name) != ".sql" {
+			return fmt.Errorf("%w: unknown fixture set %q", errUsage, name)
+		}
+
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		sql = string(b)
+	}
+
+	db, err := pgxpool.New(ctx, cfg.DatabaseUrl)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if set != nil {
+		err = set(ctx, model.New(tx))
+	} else {
+		_, err = tx.Exec(ctx, sql)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to seed %s: %w", name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	logger.Info("seeded database", slog.String("seed", name))
+	return nil
+}
+
+func parseDemoFlags(args []string) (fixture.DemoOptions, error) {
+	var opts fixture.DemoOptions
+
+	fs := flag.NewFlagSet("seed demo", flag.ContinueOnError)
+	fs.Int64Var(&opts.Seed, "seed", 1, "seed of the random data, also used in the user email")
+	fs.IntVar(&opts.Scale, "scale", 1, "multiplies the amount of transactions")
+	fs.IntVar(&opts.Months, "months", 6, "months of transactions to generate")
+
+	return opts, fs.Parse(args)
+}
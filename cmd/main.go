@@ -17,7 +17,7 @@ func main() {
 	config, err := app.Setup()
 
 	if err != nil {
-		slog.Error("error: ", slog.AnyValue(err))
+		slog.Error("error: ", slog.Any("error", err))
 		os.Exit(1)
 	}
 
@@ -36,6 +36,44 @@ func main() {
 
 	config.Logger.Debug(fmt.Sprintf("Listening on port %v", srv.Addr))
 
+	// Metrics get their own server so they are not exposed with the app
+	var metricsSrv *http.Server
+	if config.Cfg.MetricsPort != "" {
+		metricsSrv = &http.Server{
+			Addr:    ":" + config.Cfg.MetricsPort,
+			Handler: config.Metrics.Handler(),
+		}
+
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				config.Logger.Error("failed to initialize metrics server", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+
+		config.Logger.Debug(fmt.Sprintf("Serving metrics on port %v", metricsSrv.Addr))
+	}
+
+	// Jobs and outbox events run until the server shuts down, then the
+	// running ones finish
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+	if config.Cfg.JobInline {
+		go func() {
+			relayDone := make(chan struct{})
+			go func() {
+				config.Outbox.Run(workerCtx)
+				close(relayDone)
+			}()
+
+			config.Worker.Run(workerCtx)
+			<-relayDone
+			close(workerDone)
+		}()
+	} else {
+		close(workerDone)
+	}
+
 	// Wait for kill signal of channel
 	quit := make(chan os.Signal, 1)
 
@@ -44,6 +82,12 @@ func main() {
 	// This blocks until a signal is passed into the quit channel
 	<-quit
 
+	// Fail readiness first so load balancers stop sending new requests
+	// before the server stops accepting them
+	config.Health.SetReady(false)
+	config.Logger.Debug("Draining traffic...")
+	time.Sleep(time.Duration(config.Cfg.ShutdownDrain) * time.Second)
+
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -56,5 +100,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Requests are done so no more jobs get enqueued by them
+	stopWorker()
+	<-workerDone
+
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			config.Logger.Debug("Metrics server forced to shutdown", slog.Any("error", err))
+		}
+	}
+
+	if err := config.ShutdownTracing(ctx); err != nil {
+		config.Logger.Debug("failed to flush traces", slog.Any("error", err))
+	}
+
 	return
 }
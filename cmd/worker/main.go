@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/opchaves/gin-web-app/app"
+)
+
+// Runs the background jobs and the outbox relay outside the server, set
+// JOB_INLINE=false on the server when using it
+func main() {
+	config, err := app.Setup()
+
+	if err != nil {
+		slog.Error("error: ", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	var metricsSrv *http.Server
+	if config.Cfg.MetricsPort != "" {
+		metricsSrv = &http.Server{
+			Addr:    ":" + config.Cfg.MetricsPort,
+			Handler: config.Metrics.Handler(),
+		}
+
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				config.Logger.Error("failed to initialize metrics server", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+
+		config.Logger.Debug(fmt.Sprintf("Serving metrics on port %v", metricsSrv.Addr))
+	}
+
+	// The worker drains the running jobs once a signal is received
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	relayDone := make(chan struct{})
+	go func() {
+		config.Outbox.Run(ctx)
+		close(relayDone)
+	}()
+
+	config.Worker.Run(ctx)
+	<-relayDone
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+			config.Logger.Debug("Metrics server forced to shutdown", slog.Any("error", err))
+		}
+	}
+
+	if err := config.ShutdownTracing(shutdownCtx); err != nil {
+		config.Logger.Debug("failed to flush traces", slog.Any("error", err))
+	}
+}
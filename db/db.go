@@ -0,0 +1,132 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrations holds the SQL migration files so the binaries don't depend on
+// the db folder being around at runtime
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// MigrationsDir is the folder of Migrations holding the files
+const MigrationsDir = "migrations"
+
+// LatestVersion returns the version of the newest migration, which is the
+// version a database is expected to be at for this build
+func LatestVersion() (uint, error) {
+	entries, err := fs.ReadDir(Migrations, MigrationsDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, e := range entries {
+		prefix, _, found := strings.Cut(e.Name(), "_")
+		if !found {
+			continue
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q", e.Name())
+		}
+
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
+
+// NewMigrate returns a migrate instance running the embedded migrations
+// against the database at databaseURL. Each applied migration is logged.
+func NewMigrate(databaseURL string, logger *slog.Logger) (*migrate.Migrate, error) {
+	src, err := iofs.New(Migrations, MigrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database url: %w", err)
+	}
+	// the pgx driver of migrate registers itself under its own scheme
+	u.Scheme = "pgx5"
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, u.String())
+	if err != nil {
+		return nil, err
+	}
+	m.Log = &migrateLogger{logger: logger}
+
+	return m, nil
+}
+
+// AutoMigrate applies the pending migrations. migrate holds a Postgres
+// advisory lock while doing so, so replicas starting together wait for the
+// first one instead of racing it. It fails when the database is dirty or
+// was migrated by a newer build.
+func AutoMigrate(databaseURL string, lockTimeout time.Duration, logger *slog.Logger) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	m, err := NewMigrate(databaseURL, logger)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	m.LockTimeout = lockTimeout
+
+	err = m.Up()
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		version, _, verr := m.Version()
+		if verr != nil {
+			return verr
+		}
+		logger.Info("database is up to date", slog.Uint64("version", uint64(version)))
+		return nil
+	}
+
+	// Up checks the version while holding the lock, give a clearer reason
+	// for the cases it can't handle
+	version, dirty, verr := m.Version()
+	if verr != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database is dirty at version %d, fix it and run admin migrate force", version)
+	}
+	if version > latest {
+		return fmt.Errorf("database version %d is newer than the latest migration %d", version, latest)
+	}
+
+	return err
+}
+
+// migrateLogger sends the migrate logs to slog
+type migrateLogger struct {
+	logger *slog.Logger
+}
+
+func (l *migrateLogger) Printf(format string, v ...any) {
+	l.logger.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l *migrateLogger) Verbose() bool {
+	return false
+}
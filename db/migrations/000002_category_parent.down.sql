@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_transactions_workspace_id_handled_at";
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS "fk_categories_parent_id";
+ALTER TABLE categories DROP COLUMN IF EXISTS "parent_id";
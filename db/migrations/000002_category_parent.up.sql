@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS "parent_id" UUID NULL;
+ALTER TABLE categories ADD CONSTRAINT "fk_categories_parent_id" FOREIGN KEY ("parent_id") REFERENCES "categories"("id") ON DELETE NO ACTION ON UPDATE NO ACTION;
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_workspace_id_handled_at" ON transactions ("workspace_id", "handled_at");
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS outbox_events(
+  "id" BIGSERIAL NOT NULL,
+  "topic" VARCHAR NOT NULL,
+  "payload" JSONB NOT NULL,
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "last_error" VARCHAR,
+  "available_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "published_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_outbox_events_id" PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_pending" ON outbox_events ("available_at") WHERE "published_at" IS NULL;
+
+COMMIT;
@@ -0,0 +1,49 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS webhooks(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "url" VARCHAR NOT NULL,
+  "secret" VARCHAR NOT NULL,
+  "events" VARCHAR[] NOT NULL,
+  "description" VARCHAR,
+  "active" BOOLEAN NOT NULL DEFAULT true,
+  "user_id" UUID NOT NULL,
+  "workspace_id" UUID NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "updated_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "deleted_at" TIMESTAMP WITHOUT TIME ZONE,
+  CONSTRAINT "pk_webhooks_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_webhooks_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_webhooks_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_webhooks_workspace_id" ON webhooks ("workspace_id") WHERE "deleted_at" IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "webhook_id" UUID NOT NULL,
+  "workspace_id" UUID NOT NULL,
+  "event_id" VARCHAR NOT NULL,
+  "event" VARCHAR NOT NULL,
+  "payload" JSONB NOT NULL,
+  "status" VARCHAR NOT NULL DEFAULT 'pending',
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "response_status" INTEGER,
+  "response_body" VARCHAR,
+  "last_error" VARCHAR,
+  "duration_ms" INTEGER,
+  "replay_of" UUID,
+  "delivered_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "updated_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_webhook_deliveries_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_webhook_deliveries_webhook_id" FOREIGN KEY ("webhook_id") REFERENCES "webhooks"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_webhook_deliveries_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_webhook_deliveries_replay_of" FOREIGN KEY ("replay_of") REFERENCES "webhook_deliveries"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+-- an event is delivered once per webhook, replays are extra deliveries
+CREATE UNIQUE INDEX IF NOT EXISTS "uq_webhook_deliveries_webhook_id_event_id" ON webhook_deliveries ("webhook_id", "event_id") WHERE "replay_of" IS NULL;
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_webhook_id_created_at" ON webhook_deliveries ("webhook_id", "created_at");
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS "audit_users_role" ON users;
+DROP TRIGGER IF EXISTS "audit_transactions" ON transactions;
+DROP TRIGGER IF EXISTS "audit_categories" ON categories;
+DROP TRIGGER IF EXISTS "audit_accounts" ON accounts;
+DROP FUNCTION IF EXISTS audit_role_change();
+DROP FUNCTION IF EXISTS audit_row_change();
+DROP TABLE IF EXISTS "audit_logs";
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+
+COMMIT;
@@ -0,0 +1,118 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_logs(
+  "id" BIGSERIAL NOT NULL,
+  "actor_id" UUID,
+  "workspace_id" UUID,
+  "entity_type" VARCHAR NOT NULL,
+  "entity_id" UUID,
+  "action" VARCHAR NOT NULL,
+  "before" JSONB,
+  "after" JSONB,
+  "ip_address" VARCHAR,
+  "request_id" VARCHAR,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_audit_logs_id" PRIMARY KEY ("id")
+);
+
+-- no foreign keys so entries outlive what they describe
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_workspace_id_id" ON audit_logs ("workspace_id", "id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity" ON audit_logs ("entity_type", "entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON audit_logs ("actor_id");
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_logs_append_only" BEFORE UPDATE OR DELETE ON audit_logs
+FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+-- audit_row_change logs the changes of a row of the entity type given as
+-- argument. Updates only log the columns which changed and soft deletes are
+-- logged as deletes. The actor, ip address and request id are read from the
+-- audit.* settings of the transaction, see audit.SetActor.
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS trigger AS $$
+DECLARE
+  old_row JSONB;
+  new_row JSONB;
+  before_diff JSONB;
+  after_diff JSONB;
+  row_action VARCHAR;
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    old_row := to_jsonb(OLD) - 'updated_at';
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    new_row := to_jsonb(NEW) - 'updated_at';
+  END IF;
+
+  IF TG_OP = 'UPDATE' THEN
+    SELECT jsonb_object_agg(o.key, o.value), jsonb_object_agg(o.key, new_row -> o.key)
+    INTO before_diff, after_diff
+    FROM jsonb_each(old_row) o
+    WHERE new_row -> o.key IS DISTINCT FROM o.value;
+
+    IF before_diff IS NULL THEN
+      RETURN NULL;
+    END IF;
+
+    row_action := 'updated';
+    IF old_row -> 'deleted_at' = 'null' AND new_row -> 'deleted_at' <> 'null' THEN
+      row_action := 'deleted';
+    END IF;
+  ELSE
+    before_diff := old_row;
+    after_diff := new_row;
+    row_action := CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'deleted' END;
+  END IF;
+
+  INSERT INTO audit_logs ("actor_id", "workspace_id", "entity_type", "entity_id", "action", "before", "after", "ip_address", "request_id")
+  VALUES (
+    nullif(current_setting('audit.actor_id', true), '')::uuid,
+    (COALESCE(new_row, old_row) ->> 'workspace_id')::uuid,
+    TG_ARGV[0],
+    (COALESCE(new_row, old_row) ->> 'id')::uuid,
+    row_action,
+    before_diff,
+    after_diff,
+    nullif(current_setting('audit.ip_address', true), ''),
+    nullif(current_setting('audit.request_id', true), '')
+  );
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_accounts" AFTER INSERT OR UPDATE OR DELETE ON accounts
+FOR EACH ROW EXECUTE FUNCTION audit_row_change('account');
+
+CREATE TRIGGER "audit_categories" AFTER INSERT OR UPDATE OR DELETE ON categories
+FOR EACH ROW EXECUTE FUNCTION audit_row_change('category');
+
+CREATE TRIGGER "audit_transactions" AFTER INSERT OR UPDATE OR DELETE ON transactions
+FOR EACH ROW EXECUTE FUNCTION audit_row_change('transaction');
+
+CREATE OR REPLACE FUNCTION audit_role_change() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO audit_logs ("actor_id", "entity_type", "entity_id", "action", "before", "after", "ip_address", "request_id")
+  VALUES (
+    nullif(current_setting('audit.actor_id', true), '')::uuid,
+    'user',
+    NEW.id,
+    'role_changed',
+    jsonb_build_object('role', OLD.role),
+    jsonb_build_object('role', NEW.role),
+    nullif(current_setting('audit.ip_address', true), ''),
+    nullif(current_setting('audit.request_id', true), '')
+  );
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_users_role" AFTER UPDATE OF "role" ON users
+FOR EACH ROW WHEN (OLD.role IS DISTINCT FROM NEW.role) EXECUTE FUNCTION audit_role_change();
+
+COMMIT;
@@ -0,0 +1,66 @@
+BEGIN;
+
+DROP INDEX IF EXISTS "idx_transactions_search_vector";
+DROP TRIGGER IF EXISTS "workspaces_search_vector" ON workspaces;
+DROP TRIGGER IF EXISTS "accounts_search_vector" ON accounts;
+DROP TRIGGER IF EXISTS "categories_search_vector" ON categories;
+DROP TRIGGER IF EXISTS "transactions_search_vector" ON transactions;
+DROP FUNCTION IF EXISTS refresh_transactions_search_vector();
+DROP FUNCTION IF EXISTS transactions_search_vector();
+ALTER TABLE transactions DROP COLUMN IF EXISTS "search_vector";
+DROP FUNCTION IF EXISTS transaction_search_vector(VARCHAR, VARCHAR, UUID, UUID, UUID);
+DROP FUNCTION IF EXISTS search_config(VARCHAR);
+
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS trigger AS $$
+DECLARE
+  old_row JSONB;
+  new_row JSONB;
+  before_diff JSONB;
+  after_diff JSONB;
+  row_action VARCHAR;
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    old_row := to_jsonb(OLD) - 'updated_at';
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    new_row := to_jsonb(NEW) - 'updated_at';
+  END IF;
+
+  IF TG_OP = 'UPDATE' THEN
+    SELECT jsonb_object_agg(o.key, o.value), jsonb_object_agg(o.key, new_row -> o.key)
+    INTO before_diff, after_diff
+    FROM jsonb_each(old_row) o
+    WHERE new_row -> o.key IS DISTINCT FROM o.value;
+
+    IF before_diff IS NULL THEN
+      RETURN NULL;
+    END IF;
+
+    row_action := 'updated';
+    IF old_row -> 'deleted_at' = 'null' AND new_row -> 'deleted_at' <> 'null' THEN
+      row_action := 'deleted';
+    END IF;
+  ELSE
+    before_diff := old_row;
+    after_diff := new_row;
+    row_action := CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'deleted' END;
+  END IF;
+
+  INSERT INTO audit_logs ("actor_id", "workspace_id", "entity_type", "entity_id", "action", "before", "after", "ip_address", "request_id")
+  VALUES (
+    nullif(current_setting('audit.actor_id', true), '')::uuid,
+    (COALESCE(new_row, old_row) ->> 'workspace_id')::uuid,
+    TG_ARGV[0],
+    (COALESCE(new_row, old_row) ->> 'id')::uuid,
+    row_action,
+    before_diff,
+    after_diff,
+    nullif(current_setting('audit.ip_address', true), ''),
+    nullif(current_setting('audit.request_id', true), '')
+  );
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,122 @@
+BEGIN;
+
+-- search_config maps a workspace language, e.g. en-us, to its text search
+-- configuration
+CREATE OR REPLACE FUNCTION search_config(language VARCHAR) RETURNS regconfig AS $$
+  SELECT (CASE lower(split_part(replace(language, '_', '-'), '-', 1))
+    WHEN 'en' THEN 'english'
+    WHEN 'pt' THEN 'portuguese'
+    WHEN 'es' THEN 'spanish'
+    WHEN 'fr' THEN 'french'
+    WHEN 'de' THEN 'german'
+    WHEN 'it' THEN 'italian'
+    WHEN 'nl' THEN 'dutch'
+    ELSE 'simple'
+  END)::regconfig;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- transaction_search_vector weights the title, note, category name and
+-- account name of a transaction, in that order
+CREATE OR REPLACE FUNCTION transaction_search_vector(title VARCHAR, note VARCHAR, workspace_id UUID, category_id UUID, account_id UUID) RETURNS tsvector AS $$
+  SELECT setweight(to_tsvector(w.config, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector(w.config, coalesce(note, '')), 'B') ||
+    setweight(to_tsvector(w.config, coalesce(c.name, '')), 'C') ||
+    setweight(to_tsvector(w.config, coalesce(a.name, '')), 'D')
+  FROM (SELECT search_config(language) AS config FROM workspaces WHERE id = workspace_id) w
+  LEFT JOIN categories c ON c.id = category_id
+  LEFT JOIN accounts a ON a.id = account_id;
+$$ LANGUAGE sql STABLE;
+
+-- the column is kept by triggers rather than generated, as generated columns
+-- can't read the category and account names or the workspace language
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS "search_vector" tsvector;
+
+CREATE OR REPLACE FUNCTION transactions_search_vector() RETURNS trigger AS $$
+BEGIN
+  NEW.search_vector := transaction_search_vector(NEW.title, NEW.note, NEW.workspace_id, NEW.category_id, NEW.account_id);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "transactions_search_vector" BEFORE INSERT OR UPDATE OF "title", "note", "workspace_id", "category_id", "account_id" ON transactions
+FOR EACH ROW EXECUTE FUNCTION transactions_search_vector();
+
+-- refresh_transactions_search_vector updates the transactions whose column
+-- named by the argument is the id of the changed row
+CREATE OR REPLACE FUNCTION refresh_transactions_search_vector() RETURNS trigger AS $$
+BEGIN
+  EXECUTE format(
+    'UPDATE transactions SET search_vector = transaction_search_vector(title, note, workspace_id, category_id, account_id) WHERE %I = $1',
+    TG_ARGV[0]
+  ) USING NEW.id;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "categories_search_vector" AFTER UPDATE OF "name" ON categories
+FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE FUNCTION refresh_transactions_search_vector('category_id');
+
+CREATE TRIGGER "accounts_search_vector" AFTER UPDATE OF "name" ON accounts
+FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE FUNCTION refresh_transactions_search_vector('account_id');
+
+CREATE TRIGGER "workspaces_search_vector" AFTER UPDATE OF "language" ON workspaces
+FOR EACH ROW WHEN (OLD.language IS DISTINCT FROM NEW.language) EXECUTE FUNCTION refresh_transactions_search_vector('workspace_id');
+
+UPDATE transactions SET search_vector = transaction_search_vector(title, note, workspace_id, category_id, account_id);
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_search_vector" ON transactions USING GIN ("search_vector");
+
+-- the search vector is derived data, keep it out of the audit log
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS trigger AS $$
+DECLARE
+  old_row JSONB;
+  new_row JSONB;
+  before_diff JSONB;
+  after_diff JSONB;
+  row_action VARCHAR;
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    old_row := to_jsonb(OLD) - 'updated_at' - 'search_vector';
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    new_row := to_jsonb(NEW) - 'updated_at' - 'search_vector';
+  END IF;
+
+  IF TG_OP = 'UPDATE' THEN
+    SELECT jsonb_object_agg(o.key, o.value), jsonb_object_agg(o.key, new_row -> o.key)
+    INTO before_diff, after_diff
+    FROM jsonb_each(old_row) o
+    WHERE new_row -> o.key IS DISTINCT FROM o.value;
+
+    IF before_diff IS NULL THEN
+      RETURN NULL;
+    END IF;
+
+    row_action := 'updated';
+    IF old_row -> 'deleted_at' = 'null' AND new_row -> 'deleted_at' <> 'null' THEN
+      row_action := 'deleted';
+    END IF;
+  ELSE
+    before_diff := old_row;
+    after_diff := new_row;
+    row_action := CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'deleted' END;
+  END IF;
+
+  INSERT INTO audit_logs ("actor_id", "workspace_id", "entity_type", "entity_id", "action", "before", "after", "ip_address", "request_id")
+  VALUES (
+    nullif(current_setting('audit.actor_id', true), '')::uuid,
+    (COALESCE(new_row, old_row) ->> 'workspace_id')::uuid,
+    TG_ARGV[0],
+    (COALESCE(new_row, old_row) ->> 'id')::uuid,
+    row_action,
+    before_diff,
+    after_diff,
+    nullif(current_setting('audit.ip_address', true), ''),
+    nullif(current_setting('audit.request_id', true), '')
+  );
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS attachments(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "transaction_id" UUID NOT NULL,
+  "workspace_id" UUID NOT NULL,
+  "user_id" UUID NOT NULL,
+  "filename" VARCHAR NOT NULL,
+  "content_type" VARCHAR NOT NULL,
+  "size" BIGINT NOT NULL,
+  "storage_key" VARCHAR NOT NULL,
+  "thumbnail_key" VARCHAR,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_attachments_id" PRIMARY KEY ("id"),
+  CONSTRAINT "uq_attachments_storage_key" UNIQUE ("storage_key"),
+  CONSTRAINT "fk_attachments_transaction_id" FOREIGN KEY ("transaction_id") REFERENCES "transactions"("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+  CONSTRAINT "fk_attachments_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_attachments_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_attachments_transaction_id" ON attachments ("transaction_id");
+
+COMMIT;
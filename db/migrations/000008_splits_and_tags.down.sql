@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS transaction_tags;
+DROP TABLE IF EXISTS tags;
+DROP VIEW IF EXISTS transaction_lines;
+DROP TRIGGER IF EXISTS "transactions_splits_value" ON transactions;
+DROP TABLE IF EXISTS transaction_splits;
+DROP FUNCTION IF EXISTS check_transaction_splits();
+
+COMMIT;
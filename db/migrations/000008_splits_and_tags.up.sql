@@ -0,0 +1,106 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS transaction_splits(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "transaction_id" UUID NOT NULL,
+  "workspace_id" UUID NOT NULL,
+  "category_id" UUID NOT NULL,
+  "value" NUMERIC(10, 2) NOT NULL,
+  "note" VARCHAR,
+  "position" INTEGER NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_transaction_splits_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_transaction_splits_transaction_id" FOREIGN KEY ("transaction_id") REFERENCES "transactions"("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+  CONSTRAINT "fk_transaction_splits_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_transaction_splits_category_id" FOREIGN KEY ("category_id") REFERENCES "categories"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_transaction_splits_transaction_id" ON transaction_splits ("transaction_id");
+CREATE INDEX IF NOT EXISTS "idx_transaction_splits_category_id" ON transaction_splits ("category_id");
+
+-- check_transaction_splits makes sure the lines of a split transaction add
+-- up to its value. It runs at commit, so the lines can be replaced one by one.
+CREATE OR REPLACE FUNCTION check_transaction_splits() RETURNS trigger AS $$
+DECLARE
+  tx_id UUID;
+  tx_value NUMERIC;
+  lines_value NUMERIC;
+BEGIN
+  IF TG_TABLE_NAME = 'transactions' THEN
+    tx_id := NEW.id;
+  ELSIF TG_OP = 'DELETE' THEN
+    tx_id := OLD.transaction_id;
+  ELSE
+    tx_id := NEW.transaction_id;
+  END IF;
+
+  SELECT sum(value) INTO lines_value FROM transaction_splits WHERE transaction_id = tx_id;
+  IF lines_value IS NULL THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT value INTO tx_value FROM transactions WHERE id = tx_id;
+  IF tx_value IS DISTINCT FROM lines_value THEN
+    RAISE EXCEPTION 'lines of transaction % add up to % instead of %', tx_id, lines_value, tx_value
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'ck_transaction_splits_value';
+  END IF;
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "transaction_splits_value" AFTER INSERT OR UPDATE OR DELETE ON transaction_splits
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_transaction_splits();
+
+CREATE CONSTRAINT TRIGGER "transactions_splits_value" AFTER UPDATE OF "value" ON transactions
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_transaction_splits();
+
+-- transaction_lines has the lines of the split transactions in place of
+-- them, so summing its values by category counts every line once. Reports
+-- and budgets read it instead of transactions.
+CREATE OR REPLACE VIEW transaction_lines AS
+SELECT t.id AS transaction_id, NULL::uuid AS split_id, t.workspace_id, t.account_id, t.category_id, t.value, t.handled_at
+FROM transactions t
+WHERE t.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+UNION ALL
+SELECT t.id AS transaction_id, s.id AS split_id, t.workspace_id, t.account_id, s.category_id, s.value, t.handled_at
+FROM transaction_splits s
+JOIN transactions t ON t.id = s.transaction_id
+WHERE t.deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS tags(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "name" VARCHAR NOT NULL,
+  "workspace_id" UUID NOT NULL,
+  "user_id" UUID NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "updated_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_tags_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_tags_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_tags_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+-- tag names are case insensitive within a workspace
+CREATE UNIQUE INDEX IF NOT EXISTS "uq_tags_workspace_id_name" ON tags ("workspace_id", lower("name"));
+
+CREATE TABLE IF NOT EXISTS transaction_tags(
+  "transaction_id" UUID NOT NULL,
+  "tag_id" UUID NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_transaction_tags" PRIMARY KEY ("transaction_id", "tag_id"),
+  CONSTRAINT "fk_transaction_tags_transaction_id" FOREIGN KEY ("transaction_id") REFERENCES "transactions"("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+  CONSTRAINT "fk_transaction_tags_tag_id" FOREIGN KEY ("tag_id") REFERENCES "tags"("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_transaction_tags_tag_id" ON transaction_tags ("tag_id");
+
+CREATE TRIGGER "audit_transaction_splits" AFTER INSERT OR UPDATE OR DELETE ON transaction_splits
+FOR EACH ROW EXECUTE FUNCTION audit_row_change('transaction_split');
+
+CREATE TRIGGER "audit_tags" AFTER INSERT OR UPDATE OR DELETE ON tags
+FOR EACH ROW EXECUTE FUNCTION audit_row_change('tag');
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS category_corrections;
+DROP TABLE IF EXISTS rules;
+
+COMMIT;
@@ -0,0 +1,60 @@
+BEGIN;
+
+-- rules categorize the transactions of a workspace. A rule matches the
+-- transactions meeting all of its conditions and they run by position.
+CREATE TABLE IF NOT EXISTS rules(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "name" VARCHAR NOT NULL,
+  "position" INTEGER NOT NULL DEFAULT 0,
+  "active" BOOLEAN NOT NULL DEFAULT true,
+  "title_pattern" VARCHAR,
+  "note_pattern" VARCHAR,
+  "min_value" NUMERIC(10, 2),
+  "max_value" NUMERIC(10, 2),
+  "account_id" UUID,
+  "set_category_id" UUID,
+  "add_tags" VARCHAR[] NOT NULL DEFAULT '{}',
+  "set_title" VARCHAR,
+  "workspace_id" UUID NOT NULL,
+  "user_id" UUID NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "updated_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_rules_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_rules_account_id" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_rules_set_category_id" FOREIGN KEY ("set_category_id") REFERENCES "categories"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_rules_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_rules_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "ck_rules_condition" CHECK (
+    "title_pattern" IS NOT NULL OR "note_pattern" IS NOT NULL OR "min_value" IS NOT NULL
+    OR "max_value" IS NOT NULL OR "account_id" IS NOT NULL
+  ),
+  CONSTRAINT "ck_rules_action" CHECK (
+    "set_category_id" IS NOT NULL OR "set_title" IS NOT NULL OR cardinality("add_tags") > 0
+  )
+);
+
+CREATE INDEX IF NOT EXISTS "idx_rules_workspace_id" ON rules ("workspace_id", "position");
+
+-- category_corrections are the categories users changed by hand, which rules
+-- are suggested from
+CREATE TABLE IF NOT EXISTS category_corrections(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "transaction_id" UUID NOT NULL,
+  "workspace_id" UUID NOT NULL,
+  "user_id" UUID NOT NULL,
+  "title" VARCHAR NOT NULL,
+  "from_category_id" UUID NOT NULL,
+  "to_category_id" UUID NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_category_corrections_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_category_corrections_transaction_id" FOREIGN KEY ("transaction_id") REFERENCES "transactions"("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+  CONSTRAINT "fk_category_corrections_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_category_corrections_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_category_corrections_workspace_id" ON category_corrections ("workspace_id", "transaction_id");
+
+CREATE TRIGGER "audit_rules" AFTER INSERT OR UPDATE OR DELETE ON rules
+FOR EACH ROW EXECUTE FUNCTION audit_row_change('rule');
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- views can't lose columns, so it's created again without payee_id
+DROP VIEW IF EXISTS transaction_lines;
+ALTER TABLE transactions DROP COLUMN IF EXISTS "payee_id";
+DROP TABLE IF EXISTS payees;
+
+CREATE VIEW transaction_lines AS
+SELECT t.id AS transaction_id, NULL::uuid AS split_id, t.workspace_id, t.account_id, t.category_id, t.value, t.handled_at
+FROM transactions t
+WHERE t.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+UNION ALL
+SELECT t.id AS transaction_id, s.id AS split_id, t.workspace_id, t.account_id, s.category_id, s.value, t.handled_at
+FROM transaction_splits s
+JOIN transactions t ON t.id = s.transaction_id
+WHERE t.deleted_at IS NULL;
+
+COMMIT;
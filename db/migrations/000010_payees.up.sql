@@ -0,0 +1,43 @@
+BEGIN;
+
+-- payees are who the transactions are with. Titles matching the name or one
+-- of the alias patterns of a payee belong to it, e.g. "AMZN MKTP US*2K4"
+-- and "Amazon.com" to Amazon.
+CREATE TABLE IF NOT EXISTS payees(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "name" VARCHAR NOT NULL,
+  "aliases" VARCHAR[] NOT NULL DEFAULT '{}',
+  "category_id" UUID,
+  "workspace_id" UUID NOT NULL,
+  "user_id" UUID NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "updated_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_payees_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_payees_category_id" FOREIGN KEY ("category_id") REFERENCES "categories"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_payees_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_payees_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+-- payee names are case insensitive within a workspace
+CREATE UNIQUE INDEX IF NOT EXISTS "uq_payees_workspace_id_name" ON payees ("workspace_id", lower("name"));
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS "payee_id" UUID;
+ALTER TABLE transactions ADD CONSTRAINT "fk_transactions_payee_id" FOREIGN KEY ("payee_id") REFERENCES "payees"("id") ON DELETE SET NULL ON UPDATE NO ACTION;
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_payee_id" ON transactions ("payee_id");
+
+CREATE OR REPLACE VIEW transaction_lines AS
+SELECT t.id AS transaction_id, NULL::uuid AS split_id, t.workspace_id, t.account_id, t.category_id, t.value, t.handled_at, t.payee_id
+FROM transactions t
+WHERE t.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+UNION ALL
+SELECT t.id AS transaction_id, s.id AS split_id, t.workspace_id, t.account_id, s.category_id, s.value, t.handled_at, t.payee_id
+FROM transaction_splits s
+JOIN transactions t ON t.id = s.transaction_id
+WHERE t.deleted_at IS NULL;
+
+CREATE TRIGGER "audit_payees" AFTER INSERT OR UPDATE OR DELETE ON payees
+FOR EACH ROW EXECUTE FUNCTION audit_row_change('payee');
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- job_runs are the background jobs users start, like imports and reports,
+-- with their status and result. The input is kept here rather than in the
+-- job queue, which only holds the id of the run. A run is pending until its
+-- job saves the result, or the error, with the changes it made.
+CREATE TABLE IF NOT EXISTS job_runs(
+  "id" UUID NOT NULL DEFAULT gen_random_uuid(),
+  "kind" VARCHAR NOT NULL,
+  "status" VARCHAR NOT NULL DEFAULT 'pending',
+  "input" JSONB NOT NULL,
+  "result" JSONB,
+  "error" JSONB,
+  "user_id" UUID NOT NULL,
+  "workspace_id" UUID NOT NULL,
+  "finished_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  "updated_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT "pk_job_runs_id" PRIMARY KEY ("id"),
+  CONSTRAINT "fk_job_runs_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT "fk_job_runs_workspace_id" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id") ON DELETE NO ACTION ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_job_runs_workspace_id_created_at" ON job_runs ("workspace_id", "created_at");
+
+COMMIT;